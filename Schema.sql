@@ -59,13 +59,172 @@ CREATE TABLE users (
     full_name VARCHAR(100) NOT NULL,
     email VARCHAR(100) UNIQUE NOT NULL,
     password_hash TEXT NOT NULL,
-    phone_number VARCHAR(15),
+    phone_number VARCHAR(16), -- E.164, e.g. +94771234567
     profile_image TEXT,
+    suspended_at TIMESTAMP,
+    password_changed_at TIMESTAMP,
+    email_verified_at TIMESTAMP,
+    phone_verified_at TIMESTAMP,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP
 );
 
+CREATE TABLE user_sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    device_name VARCHAR(100),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    last_used_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
+
+CREATE TABLE refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    session_id INTEGER NOT NULL DEFAULT 0, -- 0 for tokens issued before sessions were tracked
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by_id INTEGER REFERENCES refresh_tokens(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX idx_refresh_tokens_session_id ON refresh_tokens(session_id);
+
+CREATE TABLE user_action_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    purpose VARCHAR(30) NOT NULL, -- e.g., password_reset, email_verification, account_unlock
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_action_tokens_user_id ON user_action_tokens(user_id);
+
+CREATE TABLE login_attempts (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(100) NOT NULL, -- as submitted, lower cased, may not belong to a user
+    ip_address VARCHAR(45) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_attempts_email ON login_attempts(email);
+CREATE INDEX idx_login_attempts_ip_address ON login_attempts(ip_address);
+CREATE INDEX idx_login_attempts_created_at ON login_attempts(created_at);
+
+CREATE TABLE phone_verifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    phone_number VARCHAR(16) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_phone_verifications_user_id ON phone_verifications(user_id);
+
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL, -- start of the key, the key itself is only stored hashed
+    key_hash VARCHAR(64) UNIQUE NOT NULL,
+    scopes TEXT NOT NULL, -- comma separated, e.g., properties:write,images:write
+    last_used_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+
+-- ==============================
+-- 🔹 ROLES & PERMISSIONS
+-- ==============================
+
+CREATE TABLE roles (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) UNIQUE NOT NULL, -- e.g., admin, agent, member
+    description TEXT,
+    listing_limit INTEGER NOT NULL DEFAULT 0, -- 0 means unlimited
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE permissions (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(100) UNIQUE NOT NULL, -- e.g., properties:moderate
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id INTEGER NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id)
+);
+
+-- ==============================
+-- 🔹 ORGANIZATIONS
+-- ==============================
+
+CREATE TABLE organizations (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    description TEXT,
+    email VARCHAR(100),
+    phone_number VARCHAR(16),
+    website TEXT,
+    logo_url TEXT,
+    city VARCHAR(50),
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE organization_members (
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'agent')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user_id ON organization_members(user_id);
+
+CREATE TABLE organization_invitations (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(100) NOT NULL, -- lower cased, may not belong to a user yet
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'manager', 'agent')),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    invited_by INTEGER NOT NULL REFERENCES users(id),
+    expires_at TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_organization_invitations_organization_id ON organization_invitations(organization_id);
+
 -- ==============================
 -- 🔹 PROPERTIES (Final Version)
 -- ==============================
@@ -73,6 +232,7 @@ CREATE TABLE users (
 CREATE TABLE properties (
     id SERIAL PRIMARY KEY,
     user_id INTEGER REFERENCES users(id),
+    organization_id INTEGER REFERENCES organizations(id), -- set for agency listings
     title VARCHAR(150),
     description TEXT,
     purpose_id INTEGER REFERENCES purpose_types(id),
@@ -94,11 +254,114 @@ CREATE TABLE properties (
     rental_period VARCHAR(20), -- Monthly, Weekly, etc.
     is_refundable BOOLEAN DEFAULT FALSE,
     pricing_type VARCHAR(10) CHECK (pricing_type IN ('sell', 'rent', 'stay')) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'published', -- draft, pending_review, rejected, published, reserved, sold, rented, expired, archived
+    status_changed_at TIMESTAMP,
+    published_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    expiry_reminded_at TIMESTAMP,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    deleted_at TIMESTAMP
+    deleted_at TIMESTAMP,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(city, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'C') ||
+        setweight(to_tsvector('english', coalesce(address, '')), 'D')
+    ) STORED -- keyword search
 );
 
+CREATE INDEX idx_properties_on_search_vector ON properties USING GIN (search_vector);
+CREATE INDEX idx_properties_on_latitude_longitude ON properties(latitude, longitude);
+CREATE INDEX idx_properties_on_created_at_id ON properties(created_at, id); -- default list order and its cursors
+CREATE INDEX idx_properties_on_status ON properties(status);
+CREATE INDEX idx_properties_on_expires_at ON properties(expires_at);
+
+-- Only when the postgis extension is installed; location searches fall back to Haversine otherwise
+-- ALTER TABLE properties ADD COLUMN location GEOGRAPHY(Point, 4326)
+--     GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography) STORED;
+-- CREATE INDEX idx_properties_on_location ON properties USING GIST (location);
+
+-- Every status transition of a listing, the first row records the status it was created in
+CREATE TABLE property_status_changes (
+    id SERIAL PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL, -- empty for the first status
+    to_status VARCHAR(20) NOT NULL,
+    changed_by INTEGER NOT NULL DEFAULT 0, -- 0 when changed by the service itself
+    note VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_property_status_changes_property_id ON property_status_changes(property_id);
+
+-- Every approval or rejection of a listing under review
+CREATE TABLE property_moderation_decisions (
+    id SERIAL PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    moderator_id INTEGER NOT NULL REFERENCES users(id),
+    decision VARCHAR(20) NOT NULL, -- approved or rejected
+    reason_code VARCHAR(40), -- set on rejections
+    note VARCHAR(500),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_property_moderation_decisions_property_id ON property_moderation_decisions(property_id);
+
+-- Every content screening that flagged the text of a listing, reported to moderators
+CREATE TABLE property_screenings (
+    id SERIAL PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL, -- risk score from 0 to 100
+    matches TEXT NOT NULL, -- JSON array of the matched rules
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_property_screenings_property_id ON property_screenings(property_id);
+
+-- Every stored version of the content of a listing, numbered from 1 per listing
+CREATE TABLE property_revisions (
+    id SERIAL PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    snapshot TEXT NOT NULL, -- JSON of the fields, amenity_ids, utility_ids and images
+    changed_by INTEGER NOT NULL, -- 0 for the content kept from before revisions were stored
+    note VARCHAR(200),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (property_id, version)
+);
+
+-- Pairs of listings flagged as likely the same property, property_id being the newer one
+CREATE TABLE property_duplicates (
+    id SERIAL PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    duplicate_of_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL, -- similarity score from 0 to 100
+    reasons VARCHAR(100) NOT NULL, -- comma separated: address, location, title, price, images
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- open, merged or dismissed
+    resolved_by INTEGER REFERENCES users(id),
+    note VARCHAR(500),
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (property_id, duplicate_of_id)
+);
+
+CREATE INDEX idx_property_duplicates_duplicate_of_id ON property_duplicates(duplicate_of_id);
+CREATE INDEX idx_property_duplicates_status ON property_duplicates(status);
+
+-- Every extension of a listing period, counted against the renewal limit of the user
+CREATE TABLE property_renewals (
+    id SERIAL PRIMARY KEY,
+    property_id INTEGER NOT NULL REFERENCES properties(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    previous_expires_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_property_renewals_property_id ON property_renewals(property_id);
+CREATE INDEX idx_property_renewals_on_user_id_created_at ON property_renewals(user_id, created_at);
+
 -- ==============================
 -- 🔹 MANY-TO-MANY RELATIONS
 -- ==============================
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"time"
+
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Action token repository methods
+	ActionTokenRepositoryCreateMethod  = "ActionTokenRepositoryCreate"
+	ActionTokenRepositoryConsumeMethod = "ActionTokenRepositoryConsume"
+)
+
+type ActionTokenRepository interface {
+	Create(token *internaldto.UserActionToken) error
+	Consume(purpose, tokenHash string) (*internaldto.UserActionToken, error)
+}
+
+type actionTokenRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateActionTokenRepository creates a new instance of ActionTokenRepository
+func CreateActionTokenRepository(requestID string) ActionTokenRepository {
+	return &actionTokenRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+// Create stores a new token and retires the unused tokens of the same purpose,
+// so only the most recently emailed link works.
+func (r *actionTokenRepository) Create(token *internaldto.UserActionToken) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ActionTokenRepositoryCreateMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ActionTokenRepositoryCreateMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&internaldto.UserActionToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserActionToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		if err = tx.Create(token).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("UserActionToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(ActionTokenRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// Consume marks an unused, unexpired token as used and returns it. The update is
+// conditional so a token can be consumed only once, otherwise gorm.ErrRecordNotFound
+// is returned.
+func (r *actionTokenRepository) Consume(purpose, tokenHash string) (*internaldto.UserActionToken, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ActionTokenRepositoryConsumeMethod), log.TraceMethodInputs(commonLogFields, purpose)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ActionTokenRepositoryConsumeMethod), commonLogFields...)
+
+	var token internaldto.UserActionToken
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&internaldto.UserActionToken{}).
+			Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenHash, purpose, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserActionToken"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("token_hash = ?", tokenHash).First(&token).Error
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("UserActionToken"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &token, nil
+}
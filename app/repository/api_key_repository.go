@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"time"
+
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// API key repository methods
+	APIKeyRepositoryCreateMethod    = "APIKeyRepositoryCreate"
+	APIKeyRepositoryListMethod      = "APIKeyRepositoryList"
+	APIKeyRepositoryGetByHashMethod = "APIKeyRepositoryGetByHash"
+	APIKeyRepositoryTouchMethod     = "APIKeyRepositoryTouch"
+	APIKeyRepositoryRevokeMethod    = "APIKeyRepositoryRevoke"
+
+	// apiKeyTouchInterval limits how often last_used_at is written for a key
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyRepository interface {
+	Create(apiKey *internaldto.APIKey) error
+	ListByUserID(userID uint) ([]internaldto.APIKey, error)
+	GetByHash(keyHash string) (*internaldto.APIKey, error)
+	Touch(id uint) error
+	Revoke(userID, id uint) error
+}
+
+type apiKeyRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateAPIKeyRepository creates a new instance of APIKeyRepository
+func CreateAPIKeyRepository(requestID string) APIKeyRepository {
+	return &apiKeyRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+func (r *apiKeyRepository) Create(apiKey *internaldto.APIKey) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyRepositoryCreateMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyRepositoryCreateMethod), commonLogFields...)
+
+	if err := r.db.Create(apiKey).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("APIKey"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// ListByUserID lists the keys of a user that have not been revoked, newest first
+func (r *apiKeyRepository) ListByUserID(userID uint) ([]internaldto.APIKey, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyRepositoryListMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyRepositoryListMethod), commonLogFields...)
+
+	var apiKeys []internaldto.APIKey
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC").
+		Find(&apiKeys).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("APIKey"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+func (r *apiKeyRepository) GetByHash(keyHash string) (*internaldto.APIKey, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyRepositoryGetByHashMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyRepositoryGetByHashMethod), commonLogFields...)
+
+	var apiKey internaldto.APIKey
+	err := r.db.Where("key_hash = ?", keyHash).First(&apiKey).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("APIKey"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+// Touch records that a key was used, writing last_used_at at most once per apiKeyTouchInterval
+func (r *apiKeyRepository) Touch(id uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyRepositoryTouchMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyRepositoryTouchMethod), commonLogFields...)
+
+	now := time.Now()
+	err := r.db.Model(&internaldto.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, now.Add(-apiKeyTouchInterval)).
+		Update("last_used_at", now).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("APIKey"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// Revoke revokes a key of the user. It returns gorm.ErrRecordNotFound when the user
+// has no such active key.
+func (r *apiKeyRepository) Revoke(userID, id uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyRepositoryRevokeMethod), log.TraceMethodInputs(commonLogFields, userID, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyRepositoryRevokeMethod), commonLogFields...)
+
+	result := r.db.Model(&internaldto.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("APIKey"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -4,21 +4,42 @@ import (
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
 	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 
 	"gorm.io/gorm"
 )
 
 const (
 	// Favorite repository methods
-	FavoriteRepositoryAddMethod    = "FavoriteRepositoryAdd"
-	FavoriteRepositoryRemoveMethod = "FavoriteRepositoryRemove"
-	FavoriteRepositoryListMethod   = "FavoriteRepositoryList"
+	FavoriteRepositoryAddMethod     = "FavoriteRepositoryAdd"
+	FavoriteRepositoryRemoveMethod  = "FavoriteRepositoryRemove"
+	FavoriteRepositoryListMethod    = "FavoriteRepositoryList"
+	FavoriteRepositoryListAllMethod = "FavoriteRepositoryListAll"
 )
 
 type FavoriteRepository interface {
 	Add(userID, propertyID uint) error
 	Remove(userID, propertyID uint) error
-	List(userID uint, page, pageSize int) ([]dto.FavoriteResponse, int64, error)
+	List(userID uint, request dto.PageRequest) ([]dto.FavoriteResponse, pagination.Page, error)
+	ListAll(userID uint) ([]dto.FavoriteExport, error)
+}
+
+// favoriteKeyset orders favorites most recently saved first
+var favoriteKeyset = keyset{sort: "saved:desc", id: "favourites.id", descending: true}
+
+// favoriteRow is a saved property as read from the favourites and properties join
+type favoriteRow struct {
+	ID          uint
+	UserID      uint
+	PropertyID  uint
+	Title       string
+	Description string
+	Price       float64
+	PriceUnit   string
+	City        string
+	Address     string
+	URL         string
+	Status      string
 }
 
 type favoriteRepository struct {
@@ -68,33 +89,92 @@ func (r *favoriteRepository) Remove(userID, propertyID uint) error {
 	return nil
 }
 
-func (r *favoriteRepository) List(userID uint, page, pageSize int) ([]dto.FavoriteResponse, int64, error) {
+// List lists one page of the properties a user saved, most recent first. Deleted properties are left out.
+func (r *favoriteRepository) List(userID uint, request dto.PageRequest) ([]dto.FavoriteResponse, pagination.Page, error) {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(FavoriteRepositoryListMethod), commonLogFields...)
+	log.Logger.Debug(log.TraceMsgFuncStart(FavoriteRepositoryListMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(FavoriteRepositoryListMethod), commonLogFields...)
 
-	var favorites []dto.FavoriteResponse
-	var total int64
+	var (
+		rows  []favoriteRow
+		page  pagination.Page
+		total *int64
+	)
+
+	cursor, err := pagination.Decode(request.Cursor, favoriteKeyset.sort)
+	if err != nil {
+		return nil, page, err
+	}
 
 	query := r.db.Table("favourites").
-		Select("favourites.*, properties.*").
-		Joins("JOIN properties ON properties.id = favourites.property_id").
-		Where("favourites.user_id = ?", userID)
+		Joins("JOIN properties ON properties.id = favourites.property_id AND properties.deleted_at IS NULL").
+		Where("favourites.user_id = ?", userID).
+		Session(&gorm.Session{})
+
+	if request.IncludeTotal {
+		var count int64
+		err = query.Count(&count).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("Favorites"), log.TraceError(commonLogFields, err)...)
+			return nil, page, err
+		}
+		total = &count
+	}
 
-	err := query.Count(&total).Error
+	pageSize := pagination.Size(request.PageSize)
+	err = favoriteKeyset.page(query, cursor, pageSize).
+		Select("favourites.id, favourites.user_id, favourites.property_id, properties.title, properties.description, " +
+			"properties.price, properties.price_unit, properties.city, properties.address, properties.status, " +
+			"(SELECT url FROM property_images WHERE property_images.property_id = properties.id " +
+			"ORDER BY is_primary DESC, id LIMIT 1) AS url").
+		Scan(&rows).Error
 	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("Favorites"), log.TraceError(commonLogFields, err)...)
-		return nil, 0, err
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Favorites"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
 	}
 
-	err = query.Offset((page - 1) * pageSize).
-		Limit(pageSize).
-		Scan(&favorites).Error
+	rows, page = pagination.Trim(rows, cursor, pageSize, favoriteKeyset.sort, func(row favoriteRow) (any, uint) { return nil, row.ID })
+	page.Total = total
+
+	favorites := make([]dto.FavoriteResponse, 0, len(rows))
+	for _, row := range rows {
+		favorites = append(favorites, dto.FavoriteResponse{
+			ID:         row.ID,
+			UserID:     row.UserID,
+			PropertyID: row.PropertyID,
+			Property: dto.PropertyDetail{
+				ID:          row.PropertyID,
+				Title:       row.Title,
+				Description: row.Description,
+				Price:       row.Price,
+				PriceUnit:   row.PriceUnit,
+				City:        row.City,
+				Address:     row.Address,
+				URL:         row.URL,
+				Status:      row.Status,
+			},
+		})
+	}
+
+	return favorites, page, nil
+}
 
+// ListAll lists every property the user saved, most recent first
+func (r *favoriteRepository) ListAll(userID uint) ([]dto.FavoriteExport, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(FavoriteRepositoryListAllMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(FavoriteRepositoryListAllMethod), commonLogFields...)
+
+	var favorites []dto.FavoriteExport
+	err := r.db.Table("favourites").
+		Select("property_id, created_at").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Scan(&favorites).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Favorites"), log.TraceError(commonLogFields, err)...)
-		return nil, 0, err
+		return nil, err
 	}
 
-	return favorites, total, nil
+	return favorites, nil
 }
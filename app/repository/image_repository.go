@@ -4,25 +4,32 @@ import (
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
 	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
 	"gorm.io/gorm"
 )
 
 const (
 	// Image repository methods
-	ImageRepositoryUploadMethod     = "ImageRepositoryUpload"
-	ImageRepositoryDeleteMethod     = "ImageRepositoryDelete"
-	ImageRepositorySetPrimaryMethod = "ImageRepositorySetPrimary"
-	ImageRepositoryListMethod       = "ImageRepositoryList"
+	ImageRepositoryUploadMethod        = "ImageRepositoryUpload"
+	ImageRepositoryDeleteMethod        = "ImageRepositoryDelete"
+	ImageRepositorySetPrimaryMethod    = "ImageRepositorySetPrimary"
+	ImageRepositoryListMethod          = "ImageRepositoryList"
+	ImageRepositoryGetPropertyIDMethod = "ImageRepositoryGetPropertyID"
 )
 
 type ImageRepository interface {
-	Upload(propertyID uint, url string, isPrimary bool) (*dto.ImageResponse, error)
-	Delete(imageID uint) error
-	SetPrimary(imageID uint) error
-	List(propertyID uint) ([]dto.ImageResponse, error)
+	Upload(propertyID uint, url string, isPrimary bool, changedBy uint, reviewNote string) (*dto.ImageResponse, error)
+	Delete(imageID, changedBy uint, reviewNote string) error
+	SetPrimary(imageID, changedBy uint, reviewNote string) error
+	List(propertyID uint, request dto.PageRequest) ([]dto.ImageResponse, pagination.Page, error)
+	GetPropertyID(imageID uint) (uint, error)
 }
 
+// imageKeyset orders images in upload order
+var imageKeyset = keyset{sort: "uploaded:asc", id: "id"}
+
 type imageRepository struct {
 	_                 struct{}
 	repositoryContext Context
@@ -37,102 +44,205 @@ func CreateImageRepository(requestID string) ImageRepository {
 	}
 }
 
-func (r *imageRepository) Upload(propertyID uint, url string, isPrimary bool) (*dto.ImageResponse, error) {
+// Upload adds an image to a property and stores the property as its next revision.
+// A reviewNote sends a published property back to review along with the change.
+func (r *imageRepository) Upload(propertyID uint, url string, isPrimary bool, changedBy uint, reviewNote string) (*dto.ImageResponse, error) {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryUploadMethod), commonLogFields...)
+	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryUploadMethod), log.TraceMethodInputs(commonLogFields, propertyID, url, isPrimary, changedBy, reviewNote)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(ImageRepositoryUploadMethod), commonLogFields...)
 
-	// If this is the primary image, unset any existing primary images
-	if isPrimary {
-		err := r.db.Table("property_images").
-			Where("property_id = ?", propertyID).
-			Update("is_primary", false).Error
-		if err != nil {
-			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PropertyImages"), log.TraceError(commonLogFields, err)...)
-			return nil, err
+	var response dto.ImageResponse
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := r.listings().createBaselineRevision(tx, propertyID); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+			return err
 		}
-	}
 
-	var response dto.ImageResponse
-	err := r.db.Table("property_images").Create(map[string]interface{}{
-		"property_id": propertyID,
-		"url":         url,
-		"is_primary":  isPrimary,
-	}).Scan(&response).Error
+		// If this is the primary image, unset any existing primary images
+		if isPrimary {
+			err := tx.Table("property_images").
+				Where("property_id = ?", propertyID).
+				Update("is_primary", false).Error
+			if err != nil {
+				log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PropertyImages"), log.TraceError(commonLogFields, err)...)
+				return err
+			}
+		}
+
+		err := tx.Table("property_images").Create(map[string]interface{}{
+			"property_id": propertyID,
+			"url":         url,
+			"is_primary":  isPrimary,
+		}).Scan(&response).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyImage"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
 
+		return r.recordChange(tx, propertyID, changedBy, constant.ListingImageAddedNote, reviewNote)
+	})
 	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyImage"), log.TraceError(commonLogFields, err)...)
 		return nil, err
 	}
 	return &response, nil
 }
 
-func (r *imageRepository) Delete(imageID uint) error {
+// Delete removes an image of a property and stores the property as its next revision.
+// A reviewNote sends a published property back to review along with the change.
+func (r *imageRepository) Delete(imageID, changedBy uint, reviewNote string) error {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryDeleteMethod), commonLogFields...)
+	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryDeleteMethod), log.TraceMethodInputs(commonLogFields, imageID, changedBy, reviewNote)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(ImageRepositoryDeleteMethod), commonLogFields...)
 
-	err := r.db.Table("property_images").
-		Where("id = ?", imageID).
-		Delete(&struct{}{}).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		propertyID, err := r.propertyID(tx, imageID)
+		if err != nil {
+			return err
+		}
+		if err = r.listings().createBaselineRevision(tx, propertyID); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
 
-	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("PropertyImage"), log.TraceError(commonLogFields, err)...)
-		return err
-	}
-	return nil
+		err = tx.Table("property_images").
+			Where("id = ?", imageID).
+			Delete(&struct{}{}).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("PropertyImage"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return r.recordChange(tx, propertyID, changedBy, constant.ListingImageDeletedNote, reviewNote)
+	})
 }
 
-func (r *imageRepository) SetPrimary(imageID uint) error {
+// SetPrimary makes an image the primary image of its property and stores the property as
+// its next revision. A reviewNote sends a published property back to review along with the change.
+func (r *imageRepository) SetPrimary(imageID, changedBy uint, reviewNote string) error {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositorySetPrimaryMethod), commonLogFields...)
+	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositorySetPrimaryMethod), log.TraceMethodInputs(commonLogFields, imageID, changedBy, reviewNote)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(ImageRepositorySetPrimaryMethod), commonLogFields...)
 
-	// Get the property ID for this image
-	var propertyID uint
-	err := r.db.Table("property_images").
-		Select("property_id").
-		Where("id = ?", imageID).
-		Scan(&propertyID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Get the property ID for this image
+		propertyID, err := r.propertyID(tx, imageID)
+		if err != nil {
+			return err
+		}
+		if err = r.listings().createBaselineRevision(tx, propertyID); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		// Unset any existing primary images
+		err = tx.Table("property_images").
+			Where("property_id = ?", propertyID).
+			Update("is_primary", false).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PropertyImages"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		// Set the new primary image
+		err = tx.Table("property_images").
+			Where("id = ?", imageID).
+			Update("is_primary", true).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PropertyImage"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return r.recordChange(tx, propertyID, changedBy, constant.ListingImagePrimaryNote, reviewNote)
+	})
+}
+
+// List lists one page of the images of a property in upload order
+func (r *imageRepository) List(propertyID uint, request dto.PageRequest) ([]dto.ImageResponse, pagination.Page, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryListMethod), log.TraceMethodInputs(commonLogFields, propertyID, request)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ImageRepositoryListMethod), commonLogFields...)
+
+	var (
+		images []dto.ImageResponse
+		page   pagination.Page
+		total  *int64
+	)
+
+	cursor, err := pagination.Decode(request.Cursor, imageKeyset.sort)
 	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyImage"), log.TraceError(commonLogFields, err)...)
-		return err
+		return nil, page, err
 	}
 
-	// Unset any existing primary images
-	err = r.db.Table("property_images").
+	query := r.db.Table("property_images").
 		Where("property_id = ?", propertyID).
-		Update("is_primary", false).Error
-	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PropertyImages"), log.TraceError(commonLogFields, err)...)
-		return err
+		Session(&gorm.Session{})
+
+	if request.IncludeTotal {
+		var count int64
+		err = query.Count(&count).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("PropertyImages"), log.TraceError(commonLogFields, err)...)
+			return nil, page, err
+		}
+		total = &count
 	}
 
-	// Set the new primary image
-	err = r.db.Table("property_images").
-		Where("id = ?", imageID).
-		Update("is_primary", true).Error
+	pageSize := pagination.Size(request.PageSize)
+	err = imageKeyset.page(query, cursor, pageSize).Find(&images).Error
 	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PropertyImage"), log.TraceError(commonLogFields, err)...)
-		return err
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyImages"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
 	}
 
-	return nil
+	images, page = pagination.Trim(images, cursor, pageSize, imageKeyset.sort, func(image dto.ImageResponse) (any, uint) { return nil, image.ID })
+	page.Total = total
+	return images, page, nil
 }
 
-func (r *imageRepository) List(propertyID uint) ([]dto.ImageResponse, error) {
+func (r *imageRepository) GetPropertyID(imageID uint) (uint, error) {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryListMethod), commonLogFields...)
-	defer log.Logger.Debug(log.TraceMsgFuncEnd(ImageRepositoryListMethod), commonLogFields...)
+	log.Logger.Debug(log.TraceMsgFuncStart(ImageRepositoryGetPropertyIDMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ImageRepositoryGetPropertyIDMethod), commonLogFields...)
 
-	var images []dto.ImageResponse
-	err := r.db.Table("property_images").
-		Where("property_id = ?", propertyID).
-		Find(&images).Error
+	var image dto.PropertyImage
+	err := r.db.Select("id", "property_id").Where("id = ?", imageID).First(&image).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyImage"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
 
+	return image.PropertyID, nil
+}
+
+// propertyID reads the property of an image within the transaction
+func (r *imageRepository) propertyID(tx *gorm.DB, imageID uint) (uint, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+
+	var propertyID uint
+	err := tx.Table("property_images").
+		Select("property_id").
+		Where("id = ?", imageID).
+		Scan(&propertyID).Error
 	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyImages"), log.TraceError(commonLogFields, err)...)
-		return nil, err
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyImage"), log.TraceError(commonLogFields, err)...)
+		return 0, err
 	}
-	return images, nil
+
+	return propertyID, nil
+}
+
+// recordChange stores the property as an image change left it as its next revision, and sends
+// a published property back to review when a reviewNote is given
+func (r *imageRepository) recordChange(tx *gorm.DB, propertyID, changedBy uint, revisionNote, reviewNote string) error {
+	listings := r.listings()
+	if err := listings.createRevision(tx, propertyID, changedBy, revisionNote); err != nil {
+		return err
+	}
+
+	return listings.sendToReview(tx, propertyID, changedBy, reviewNote)
+}
+
+// listings gives the image changes access to the revisions and status changes of their property
+func (r *imageRepository) listings() *propertyRepository {
+	return &propertyRepository{repositoryContext: r.repositoryContext, db: r.db}
 }
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+
+	"gorm.io/gorm"
+)
+
+// keyset orders a list on a sort value with the row id as tiebreaker, so a page can resume
+// right after (or before) any row without counting the rows in front of it
+type keyset struct {
+	// sort names the ordering and is recorded in the cursors issued for it
+	sort string
+	// orderBy is the column or selected alias the rows are ordered on, empty to order on the id alone
+	orderBy string
+	// expression computes the sort value in a WHERE clause, with args
+	expression string
+	args       []any
+	id         string
+	descending bool
+}
+
+// columnKeyset orders on a plain column
+func columnKeyset(sort, column, id string, descending bool) keyset {
+	return keyset{sort: sort, orderBy: column, expression: column, id: id, descending: descending}
+}
+
+// page restricts the query to the rows after the cursor and fetches one more row than
+// pageSize, which pagination.Trim uses to tell whether another page follows
+func (k keyset) page(query *gorm.DB, cursor *pagination.Cursor, pageSize int) *gorm.DB {
+	descending := k.descending
+	if cursor != nil && cursor.Direction == pagination.DirectionPrev {
+		descending = !descending
+	}
+
+	operator, direction := ">", "ASC"
+	if descending {
+		operator, direction = "<", "DESC"
+	}
+
+	if cursor != nil {
+		if k.orderBy == "" {
+			query = query.Where(fmt.Sprintf("%s %s ?", k.id, operator), cursor.ID)
+		} else {
+			args := append(append([]any{}, k.args...), cursor.Value, cursor.ID)
+			query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", k.expression, k.id, operator), args...)
+		}
+	}
+
+	order := fmt.Sprintf("%s %s", k.id, direction)
+	if k.orderBy != "" {
+		order = fmt.Sprintf("%s %s, %s", k.orderBy, direction, order)
+	}
+
+	return query.Order(order).Limit(pageSize + 1)
+}
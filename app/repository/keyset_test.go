@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB renders statements without a database connection
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db
+}
+
+func TestKeysetPage(t *testing.T) {
+	rankKeyset := keyset{sort: "relevance", orderBy: "rank", expression: "ts_rank(search_vector, plainto_tsquery(?))", args: []any{"villa"}, id: "id", descending: true}
+
+	tests := []struct {
+		name     string
+		keyset   keyset
+		cursor   *pagination.Cursor
+		wantSQL  string
+		wantVars []any
+	}{
+		{
+			name:     "first page on the id",
+			keyset:   imageKeyset,
+			wantSQL:  `SELECT * FROM "rows" ORDER BY id ASC LIMIT $1`,
+			wantVars: []any{11},
+		},
+		{
+			name:     "next page on the id",
+			keyset:   imageKeyset,
+			cursor:   &pagination.Cursor{Direction: pagination.DirectionNext, ID: 7},
+			wantSQL:  `SELECT * FROM "rows" WHERE id > $1 ORDER BY id ASC LIMIT $2`,
+			wantVars: []any{uint(7), 11},
+		},
+		{
+			name:     "previous page on the id",
+			keyset:   imageKeyset,
+			cursor:   &pagination.Cursor{Direction: pagination.DirectionPrev, ID: 7},
+			wantSQL:  `SELECT * FROM "rows" WHERE id < $1 ORDER BY id DESC LIMIT $2`,
+			wantVars: []any{uint(7), 11},
+		},
+		{
+			name:     "first page on a descending column",
+			keyset:   newestPropertyKeyset,
+			wantSQL:  `SELECT * FROM "rows" ORDER BY created_at DESC, id DESC LIMIT $1`,
+			wantVars: []any{11},
+		},
+		{
+			name:     "next page on a descending column",
+			keyset:   newestPropertyKeyset,
+			cursor:   &pagination.Cursor{Direction: pagination.DirectionNext, Value: "2025-03-01 10:15:00", ID: 7},
+			wantSQL:  `SELECT * FROM "rows" WHERE (created_at, id) < ($1, $2) ORDER BY created_at DESC, id DESC LIMIT $3`,
+			wantVars: []any{"2025-03-01 10:15:00", uint(7), 11},
+		},
+		{
+			name:     "previous page on a descending column",
+			keyset:   newestPropertyKeyset,
+			cursor:   &pagination.Cursor{Direction: pagination.DirectionPrev, Value: "2025-03-01 10:15:00", ID: 7},
+			wantSQL:  `SELECT * FROM "rows" WHERE (created_at, id) > ($1, $2) ORDER BY created_at ASC, id ASC LIMIT $3`,
+			wantVars: []any{"2025-03-01 10:15:00", uint(7), 11},
+		},
+		{
+			name:     "next page on an expression",
+			keyset:   rankKeyset,
+			cursor:   &pagination.Cursor{Direction: pagination.DirectionNext, Value: 0.5, ID: 7},
+			wantSQL:  `SELECT * FROM "rows" WHERE (ts_rank(search_vector, plainto_tsquery($1)), id) < ($2, $3) ORDER BY rank DESC, id DESC LIMIT $4`,
+			wantVars: []any{"villa", 0.5, uint(7), 11},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []map[string]any
+			statement := tt.keyset.page(dryRunDB(t).Table("rows"), tt.cursor, 10).Find(&rows).Statement
+
+			if got := statement.SQL.String(); got != tt.wantSQL {
+				t.Errorf("page() SQL = %s\nwant %s", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(statement.Vars, tt.wantVars) {
+				t.Errorf("page() vars = %#v, want %#v", statement.Vars, tt.wantVars)
+			}
+		})
+	}
+
+	if len(rankKeyset.args) != 1 {
+		t.Errorf("page() changed the args of the keyset to %v", rankKeyset.args)
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"time"
+
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Login attempt repository methods
+	LoginAttemptRepositoryRecordMethod             = "LoginAttemptRepositoryRecord"
+	LoginAttemptRepositoryCountEmailFailuresMethod = "LoginAttemptRepositoryCountEmailFailures"
+	LoginAttemptRepositoryCountIPFailuresMethod    = "LoginAttemptRepositoryCountIPFailures"
+)
+
+type LoginAttemptRepository interface {
+	Record(email, ipAddress string, succeeded bool) error
+	CountEmailFailures(email string, since time.Time) (int64, error)
+	CountIPFailures(ipAddress string, since time.Time) (int64, error)
+}
+
+type loginAttemptRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateLoginAttemptRepository creates a new instance of LoginAttemptRepository
+func CreateLoginAttemptRepository(requestID string) LoginAttemptRepository {
+	return &loginAttemptRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+func (r *loginAttemptRepository) Record(email, ipAddress string, succeeded bool) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(LoginAttemptRepositoryRecordMethod), log.TraceMethodInputs(commonLogFields, ipAddress, succeeded)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(LoginAttemptRepositoryRecordMethod), commonLogFields...)
+
+	err := r.db.Create(&internaldto.LoginAttempt{
+		Email:     email,
+		IPAddress: ipAddress,
+		Succeeded: succeeded,
+	}).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("LoginAttempt"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// CountEmailFailures counts the failed attempts for an email since the given time.
+// A successful login or unlock resets the count.
+func (r *loginAttemptRepository) CountEmailFailures(email string, since time.Time) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(LoginAttemptRepositoryCountEmailFailuresMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(LoginAttemptRepositoryCountEmailFailuresMethod), commonLogFields...)
+
+	lastSuccess := r.db.Model(&internaldto.LoginAttempt{}).
+		Select("COALESCE(MAX(created_at), ?)", since).
+		Where("email = ? AND succeeded", email)
+
+	var count int64
+	err := r.db.Model(&internaldto.LoginAttempt{}).
+		Where("email = ? AND NOT succeeded AND created_at > ? AND created_at > (?)", email, since, lastSuccess).
+		Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("LoginAttempt"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountIPFailures counts the failed attempts from a client IP since the given time
+func (r *loginAttemptRepository) CountIPFailures(ipAddress string, since time.Time) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(LoginAttemptRepositoryCountIPFailuresMethod), log.TraceMethodInputs(commonLogFields, ipAddress)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(LoginAttemptRepositoryCountIPFailuresMethod), commonLogFields...)
+
+	var count int64
+	err := r.db.Model(&internaldto.LoginAttempt{}).
+		Where("ip_address = ? AND NOT succeeded AND created_at > ?", ipAddress, since).
+		Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("LoginAttempt"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -16,8 +16,21 @@ const (
 	LookupRepositoryGetConditionsMethod     = "LookupRepositoryGetConditions"
 	LookupRepositoryGetUtilitiesMethod      = "LookupRepositoryGetUtilities"
 	LookupRepositoryGetAmenitiesMethod      = "LookupRepositoryGetAmenities"
+	LookupRepositoryCreateMethod            = "LookupRepositoryCreate"
+	LookupRepositoryUpdateMethod            = "LookupRepositoryUpdate"
+	LookupRepositoryDeleteMethod            = "LookupRepositoryDelete"
 )
 
+// LookupTables maps the lookup type used in the API to its table
+var LookupTables = map[string]string{
+	"purpose-types":   "purpose_types",
+	"property-types":  "property_types",
+	"furniture-types": "furniture_types",
+	"conditions":      "property_conditions",
+	"utilities":       "utilities",
+	"amenities":       "amenities",
+}
+
 type LookupRepository interface {
 	GetPurposeTypes() ([]dto.LookupResponse, error)
 	GetPropertyTypes() ([]dto.LookupResponse, error)
@@ -25,6 +38,9 @@ type LookupRepository interface {
 	GetConditions() ([]dto.LookupResponse, error)
 	GetUtilities() ([]dto.LookupResponse, error)
 	GetAmenities() ([]dto.LookupResponse, error)
+	Create(table, name string) (*dto.LookupResponse, error)
+	Update(table string, id uint, name string) (*dto.LookupResponse, error)
+	Delete(table string, id uint) error
 }
 
 type lookupRepository struct {
@@ -47,7 +63,7 @@ func (r *lookupRepository) GetPurposeTypes() ([]dto.LookupResponse, error) {
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryGetPurposeTypesMethod), commonLogFields...)
 
 	var purposes []dto.LookupResponse
-	err := r.db.Table("purpose_types").Find(&purposes).Error
+	err := r.db.Table("purpose_types").Where("deleted_at IS NULL").Find(&purposes).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PurposeTypes"), log.TraceError(commonLogFields, err)...)
 		return nil, err
@@ -61,7 +77,7 @@ func (r *lookupRepository) GetPropertyTypes() ([]dto.LookupResponse, error) {
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryGetPropertyTypesMethod), commonLogFields...)
 
 	var types []dto.LookupResponse
-	err := r.db.Table("property_types").Find(&types).Error
+	err := r.db.Table("property_types").Where("deleted_at IS NULL").Find(&types).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyTypes"), log.TraceError(commonLogFields, err)...)
 		return nil, err
@@ -75,7 +91,7 @@ func (r *lookupRepository) GetFurnitureTypes() ([]dto.LookupResponse, error) {
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryGetFurnitureTypesMethod), commonLogFields...)
 
 	var types []dto.LookupResponse
-	err := r.db.Table("furniture_types").Find(&types).Error
+	err := r.db.Table("furniture_types").Where("deleted_at IS NULL").Find(&types).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("FurnitureTypes"), log.TraceError(commonLogFields, err)...)
 		return nil, err
@@ -89,7 +105,7 @@ func (r *lookupRepository) GetConditions() ([]dto.LookupResponse, error) {
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryGetConditionsMethod), commonLogFields...)
 
 	var conditions []dto.LookupResponse
-	err := r.db.Table("property_conditions").Find(&conditions).Error
+	err := r.db.Table("property_conditions").Where("deleted_at IS NULL").Find(&conditions).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyConditions"), log.TraceError(commonLogFields, err)...)
 		return nil, err
@@ -103,7 +119,7 @@ func (r *lookupRepository) GetUtilities() ([]dto.LookupResponse, error) {
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryGetUtilitiesMethod), commonLogFields...)
 
 	var utilities []dto.LookupResponse
-	err := r.db.Table("utilities").Find(&utilities).Error
+	err := r.db.Table("utilities").Where("deleted_at IS NULL").Find(&utilities).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Utilities"), log.TraceError(commonLogFields, err)...)
 		return nil, err
@@ -117,10 +133,70 @@ func (r *lookupRepository) GetAmenities() ([]dto.LookupResponse, error) {
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryGetAmenitiesMethod), commonLogFields...)
 
 	var amenities []dto.LookupResponse
-	err := r.db.Table("amenities").Find(&amenities).Error
+	err := r.db.Table("amenities").Where("deleted_at IS NULL").Find(&amenities).Error
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Amenities"), log.TraceError(commonLogFields, err)...)
 		return nil, err
 	}
 	return amenities, nil
 }
+
+func (r *lookupRepository) Create(table, name string) (*dto.LookupResponse, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(LookupRepositoryCreateMethod), log.TraceMethodInputs(commonLogFields, table, name)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryCreateMethod), commonLogFields...)
+
+	err := r.db.Table(table).Create(map[string]any{"name": name}).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting(table), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	var lookup dto.LookupResponse
+	err = r.db.Table(table).Where("name = ? AND deleted_at IS NULL", name).First(&lookup).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting(table), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &lookup, nil
+}
+
+func (r *lookupRepository) Update(table string, id uint, name string) (*dto.LookupResponse, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(LookupRepositoryUpdateMethod), log.TraceMethodInputs(commonLogFields, table, id, name)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryUpdateMethod), commonLogFields...)
+
+	result := r.db.Table(table).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Updates(map[string]any{"name": name, "updated_at": gorm.Expr("CURRENT_TIMESTAMP")})
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating(table), log.TraceError(commonLogFields, result.Error)...)
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &dto.LookupResponse{ID: id, Name: name}, nil
+}
+
+// Delete soft deletes a lookup so properties referencing it stay intact
+func (r *lookupRepository) Delete(table string, id uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(LookupRepositoryDeleteMethod), log.TraceMethodInputs(commonLogFields, table, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(LookupRepositoryDeleteMethod), commonLogFields...)
+
+	result := r.db.Table(table).
+		Where("id = ? AND deleted_at IS NULL", id).
+		Update("deleted_at", gorm.Expr("CURRENT_TIMESTAMP"))
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting(table), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,357 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Organization repository methods
+	OrganizationRepositoryCreateMethod           = "OrganizationRepositoryCreate"
+	OrganizationRepositoryGetByIDMethod          = "OrganizationRepositoryGetByID"
+	OrganizationRepositoryUpdateMethod           = "OrganizationRepositoryUpdate"
+	OrganizationRepositoryListByMemberMethod     = "OrganizationRepositoryListByMember"
+	OrganizationRepositoryGetMemberMethod        = "OrganizationRepositoryGetMember"
+	OrganizationRepositoryListMembersMethod      = "OrganizationRepositoryListMembers"
+	OrganizationRepositoryCountMembersMethod     = "OrganizationRepositoryCountMembers"
+	OrganizationRepositoryUpdateMemberMethod     = "OrganizationRepositoryUpdateMemberRole"
+	OrganizationRepositoryRemoveMemberMethod     = "OrganizationRepositoryRemoveMember"
+	OrganizationRepositoryCreateInviteMethod     = "OrganizationRepositoryCreateInvitation"
+	OrganizationRepositoryListInvitesMethod      = "OrganizationRepositoryListInvitations"
+	OrganizationRepositoryGetInviteByHashMethod  = "OrganizationRepositoryGetInvitationByHash"
+	OrganizationRepositoryRevokeInviteMethod     = "OrganizationRepositoryRevokeInvitation"
+	OrganizationRepositoryAcceptInvitationMethod = "OrganizationRepositoryAcceptInvitation"
+)
+
+type OrganizationRepository interface {
+	Create(organization *internaldto.Organization) error
+	GetByID(id uint) (*internaldto.Organization, error)
+	Update(organization *internaldto.Organization) error
+	ListByMember(userID uint) ([]dto.OrganizationResponse, error)
+	GetMember(organizationID, userID uint) (*internaldto.OrganizationMember, error)
+	ListMembers(organizationID uint) ([]dto.OrganizationMemberResponse, error)
+	CountMembers(organizationID uint, role string) (int64, error)
+	UpdateMemberRole(organizationID, userID uint, role string) error
+	RemoveMember(organizationID, userID uint) error
+	CreateInvitation(invitation *internaldto.OrganizationInvitation) error
+	ListInvitations(organizationID uint) ([]internaldto.OrganizationInvitation, error)
+	GetInvitationByHash(tokenHash string) (*internaldto.OrganizationInvitation, error)
+	RevokeInvitation(organizationID, id uint) error
+	AcceptInvitation(invitation *internaldto.OrganizationInvitation, userID uint) error
+}
+
+type organizationRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateOrganizationRepository creates a new instance of OrganizationRepository
+func CreateOrganizationRepository(requestID string) OrganizationRepository {
+	return &organizationRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+// Create stores a new organization and makes its creator the first owner
+func (r *organizationRepository) Create(organization *internaldto.Organization) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryCreateMethod), log.TraceMethodInputs(commonLogFields, organization)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryCreateMethod), commonLogFields...)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(organization).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("Organization"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		member := internaldto.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         organization.CreatedBy,
+			Role:           auth.OrganizationRoleOwner,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("OrganizationMember"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *organizationRepository) GetByID(id uint) (*internaldto.Organization, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryGetByIDMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryGetByIDMethod), commonLogFields...)
+
+	var organization internaldto.Organization
+	if err := r.db.Where("id = ?", id).First(&organization).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Organization"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &organization, nil
+}
+
+// Update saves the profile fields of an organization
+func (r *organizationRepository) Update(organization *internaldto.Organization) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryUpdateMethod), log.TraceMethodInputs(commonLogFields, organization)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryUpdateMethod), commonLogFields...)
+
+	err := r.db.Model(organization).
+		Updates(map[string]interface{}{
+			"name":         organization.Name,
+			"description":  organization.Description,
+			"email":        organization.Email,
+			"phone_number": organization.PhoneNumber,
+			"website":      organization.Website,
+			"logo_url":     organization.LogoURL,
+			"city":         organization.City,
+			"updated_at":   time.Now(),
+		}).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("Organization"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// ListByMember lists the organizations a user belongs to, with the user's role in each
+func (r *organizationRepository) ListByMember(userID uint) ([]dto.OrganizationResponse, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryListByMemberMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryListByMemberMethod), commonLogFields...)
+
+	var organizations []dto.OrganizationResponse
+	err := r.db.Table("organizations").
+		Select("organizations.*, organization_members.role").
+		Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+		Where("organization_members.user_id = ?", userID).
+		Order("organizations.name").
+		Scan(&organizations).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Organization"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return organizations, nil
+}
+
+func (r *organizationRepository) GetMember(organizationID, userID uint) (*internaldto.OrganizationMember, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryGetMemberMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryGetMemberMethod), commonLogFields...)
+
+	var member internaldto.OrganizationMember
+	err := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).First(&member).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("OrganizationMember"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// ListMembers lists the members of an organization, owners first
+func (r *organizationRepository) ListMembers(organizationID uint) ([]dto.OrganizationMemberResponse, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryListMembersMethod), log.TraceMethodInputs(commonLogFields, organizationID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryListMembersMethod), commonLogFields...)
+
+	var members []dto.OrganizationMemberResponse
+	err := r.db.Table("organization_members").
+		Select("organization_members.user_id, users.full_name, users.email, organization_members.role, organization_members.created_at").
+		Joins("JOIN users ON users.id = organization_members.user_id").
+		Where("organization_members.organization_id = ?", organizationID).
+		Order(gorm.Expr("CASE organization_members.role WHEN ? THEN 0 WHEN ? THEN 1 ELSE 2 END, users.full_name",
+			auth.OrganizationRoleOwner, auth.OrganizationRoleManager)).
+		Scan(&members).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("OrganizationMember"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// CountMembers counts the members of an organization, only those holding role unless it is empty
+func (r *organizationRepository) CountMembers(organizationID uint, role string) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryCountMembersMethod), log.TraceMethodInputs(commonLogFields, organizationID, role)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryCountMembersMethod), commonLogFields...)
+
+	query := r.db.Model(&internaldto.OrganizationMember{}).Where("organization_id = ?", organizationID)
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("OrganizationMember"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdateMemberRole changes the role of a member. It returns gorm.ErrRecordNotFound
+// when the user is not a member.
+func (r *organizationRepository) UpdateMemberRole(organizationID, userID uint, role string) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryUpdateMemberMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, role)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryUpdateMemberMethod), commonLogFields...)
+
+	result := r.db.Model(&internaldto.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("OrganizationMember"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RemoveMember removes a user from an organization. It returns gorm.ErrRecordNotFound
+// when the user is not a member.
+func (r *organizationRepository) RemoveMember(organizationID, userID uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryRemoveMemberMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryRemoveMemberMethod), commonLogFields...)
+
+	result := r.db.Where("organization_id = ? AND user_id = ?", organizationID, userID).Delete(&internaldto.OrganizationMember{})
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("OrganizationMember"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateInvitation stores a new invitation and revokes the pending ones for the same
+// email, so only the most recently emailed link works.
+func (r *organizationRepository) CreateInvitation(invitation *internaldto.OrganizationInvitation) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryCreateInviteMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryCreateInviteMethod), commonLogFields...)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&internaldto.OrganizationInvitation{}).
+			Where("organization_id = ? AND email = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.OrganizationID, invitation.Email).
+			Update("revoked_at", time.Now()).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("OrganizationInvitation"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		if err = tx.Create(invitation).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("OrganizationInvitation"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// ListInvitations lists the invitations of an organization that are still pending
+func (r *organizationRepository) ListInvitations(organizationID uint) ([]internaldto.OrganizationInvitation, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryListInvitesMethod), log.TraceMethodInputs(commonLogFields, organizationID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryListInvitesMethod), commonLogFields...)
+
+	var invitations []internaldto.OrganizationInvitation
+	err := r.db.Where("organization_id = ? AND accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?", organizationID, time.Now()).
+		Order("created_at DESC").
+		Find(&invitations).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("OrganizationInvitation"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func (r *organizationRepository) GetInvitationByHash(tokenHash string) (*internaldto.OrganizationInvitation, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryGetInviteByHashMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryGetInviteByHashMethod), commonLogFields...)
+
+	var invitation internaldto.OrganizationInvitation
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&invitation).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("OrganizationInvitation"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// RevokeInvitation revokes a pending invitation. It returns gorm.ErrRecordNotFound
+// when the organization has no such pending invitation.
+func (r *organizationRepository) RevokeInvitation(organizationID, id uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryRevokeInviteMethod), log.TraceMethodInputs(commonLogFields, organizationID, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryRevokeInviteMethod), commonLogFields...)
+
+	result := r.db.Model(&internaldto.OrganizationInvitation{}).
+		Where("id = ? AND organization_id = ? AND accepted_at IS NULL AND revoked_at IS NULL", id, organizationID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("OrganizationInvitation"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// AcceptInvitation marks an invitation accepted and adds the user to the organization
+// in one transaction. It returns gorm.ErrRecordNotFound when the invitation was used
+// or revoked in the meantime.
+func (r *organizationRepository) AcceptInvitation(invitation *internaldto.OrganizationInvitation, userID uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationRepositoryAcceptInvitationMethod), log.TraceMethodInputs(commonLogFields, invitation.ID, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationRepositoryAcceptInvitationMethod), commonLogFields...)
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&internaldto.OrganizationInvitation{}).
+			Where("id = ? AND accepted_at IS NULL AND revoked_at IS NULL", invitation.ID).
+			Update("accepted_at", time.Now())
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("OrganizationInvitation"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		member := internaldto.OrganizationMember{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         userID,
+			Role:           invitation.Role,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("OrganizationMember"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"time"
+
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Phone verification repository methods
+	PhoneVerificationRepositoryCreateMethod              = "PhoneVerificationRepositoryCreate"
+	PhoneVerificationRepositoryGetLatestMethod           = "PhoneVerificationRepositoryGetLatest"
+	PhoneVerificationRepositoryRecordFailedAttemptMethod = "PhoneVerificationRepositoryRecordFailedAttempt"
+	PhoneVerificationRepositoryConfirmMethod             = "PhoneVerificationRepositoryConfirm"
+)
+
+type PhoneVerificationRepository interface {
+	Create(verification *internaldto.PhoneVerification) error
+	GetLatest(userID uint) (*internaldto.PhoneVerification, error)
+	RecordFailedAttempt(id uint) error
+	Confirm(verification *internaldto.PhoneVerification) error
+}
+
+type phoneVerificationRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreatePhoneVerificationRepository creates a new instance of PhoneVerificationRepository
+func CreatePhoneVerificationRepository(requestID string) PhoneVerificationRepository {
+	return &phoneVerificationRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+// Create stores a new code and retires the unused codes of the user, so only the
+// most recently sent code works.
+func (r *phoneVerificationRepository) Create(verification *internaldto.PhoneVerification) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PhoneVerificationRepositoryCreateMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PhoneVerificationRepositoryCreateMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&internaldto.PhoneVerification{}).
+			Where("user_id = ? AND used_at IS NULL", verification.UserID).
+			Update("used_at", time.Now()).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PhoneVerification"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		if err = tx.Create(verification).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PhoneVerification"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PhoneVerificationRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// GetLatest returns the most recently sent code of the user, used or not
+func (r *phoneVerificationRepository) GetLatest(userID uint) (*internaldto.PhoneVerification, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PhoneVerificationRepositoryGetLatestMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PhoneVerificationRepositoryGetLatestMethod), commonLogFields...)
+
+	var verification internaldto.PhoneVerification
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").First(&verification).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PhoneVerification"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &verification, nil
+}
+
+// RecordFailedAttempt counts a wrong guess against a code
+func (r *phoneVerificationRepository) RecordFailedAttempt(id uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PhoneVerificationRepositoryRecordFailedAttemptMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PhoneVerificationRepositoryRecordFailedAttemptMethod), commonLogFields...)
+
+	err := r.db.Model(&internaldto.PhoneVerification{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PhoneVerification"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// Confirm marks a code as used and the phone number of the user as verified. Both
+// updates are conditional, so a code is used only once and only while the user still
+// has the number it was sent to, otherwise gorm.ErrRecordNotFound is returned.
+func (r *phoneVerificationRepository) Confirm(verification *internaldto.PhoneVerification) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PhoneVerificationRepositoryConfirmMethod), log.TraceMethodInputs(commonLogFields, verification.ID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PhoneVerificationRepositoryConfirmMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&internaldto.PhoneVerification{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", verification.ID, now).
+			Update("used_at", now)
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("PhoneVerification"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		result = tx.Model(&internaldto.User{}).
+			Where("id = ? AND phone_number = ?", verification.UserID, verification.PhoneNumber).
+			Update("phone_verified_at", now)
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("User"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PhoneVerificationRepositoryConfirmMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
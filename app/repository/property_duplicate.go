@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/geo"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property duplicate methods
+	PropertyRepositoryListDuplicateCandidatesMethod = "PropertyRepositoryListDuplicateCandidates"
+	PropertyRepositorySaveDuplicateMethod           = "PropertyRepositorySaveDuplicate"
+	PropertyRepositoryListDuplicatesMethod          = "PropertyRepositoryListDuplicates"
+	PropertyRepositoryGetDuplicateByIDMethod        = "PropertyRepositoryGetDuplicateByID"
+	PropertyRepositoryMergeDuplicateMethod          = "PropertyRepositoryMergeDuplicate"
+	PropertyRepositoryDismissDuplicateMethod        = "PropertyRepositoryDismissDuplicate"
+)
+
+// duplicateKeyset orders the open duplicate flags most similar first
+var duplicateKeyset = columnKeyset("score:desc", "score", "id", true)
+
+// ListDuplicateCandidates lists the listings, other than archived ones, that may be the same property:
+// those within the radius, those in the same city with the same address or a price within the
+// tolerance, and those sharing an image URL. The newest are compared first, up to limit.
+func (r *propertyRepository) ListDuplicateCandidates(property dto.Property, radiusKm, priceTolerance float64, limit int) ([]dto.Property, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListDuplicateCandidatesMethod), log.TraceMethodInputs(commonLogFields, property.ID, radiusKm, limit)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListDuplicateCandidatesMethod), commonLogFields...)
+
+	candidates := r.db.Where("LOWER(city) = LOWER(?) AND (LOWER(address) = LOWER(?) OR price BETWEEN ? AND ?)",
+		property.City, property.Address, property.Price*(1-priceTolerance), property.Price*(1+priceTolerance))
+	if property.Latitude != 0 || property.Longitude != 0 {
+		center := geo.Point{Lat: property.Latitude, Lng: property.Longitude}
+		candidates = candidates.Or(withinBounds(r.db, geo.RadiusBounds(center, radiusKm)))
+	}
+	if len(property.PropertyImages) > 0 {
+		urls := make([]string, 0, len(property.PropertyImages))
+		for _, image := range property.PropertyImages {
+			urls = append(urls, image.URL)
+		}
+		candidates = candidates.Or("id IN (?)", r.db.Model(&dto.PropertyImage{}).Select("property_id").Where("url IN ?", urls))
+	}
+
+	var properties []dto.Property
+	err := r.db.Preload("PropertyImages").
+		Where("id <> ? AND status <> ?", property.ID, constant.PropertyStatusArchived).
+		Where(candidates).
+		Order("id DESC").
+		Limit(limit).
+		Find(&properties).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+// SaveDuplicate flags a pair of listings as duplicates. A pair flagged before keeps its flag: an open
+// one is updated with the new score and reasons, a merged or dismissed one is left as it was resolved.
+func (r *propertyRepository) SaveDuplicate(duplicate internaldto.PropertyDuplicate) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositorySaveDuplicateMethod), log.TraceMethodInputs(commonLogFields, duplicate)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositorySaveDuplicateMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing internaldto.PropertyDuplicate
+		err := tx.Where("property_id = ? AND duplicate_of_id = ?", duplicate.PropertyID, duplicate.DuplicateOfID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			duplicate.Status = constant.PropertyDuplicateStatusOpen
+			return tx.Create(&duplicate).Error
+		}
+		if err != nil || existing.Status != constant.PropertyDuplicateStatusOpen {
+			return err
+		}
+
+		return tx.Model(&existing).Updates(map[string]any{
+			"score":   duplicate.Score,
+			"reasons": duplicate.Reasons,
+		}).Error
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyDuplicate"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// ListDuplicates lists one page of the open duplicate flags, most similar first
+func (r *propertyRepository) ListDuplicates(request dto.PageRequest) ([]internaldto.PropertyDuplicate, pagination.Page, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListDuplicatesMethod), log.TraceMethodInputs(commonLogFields, request)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListDuplicatesMethod), commonLogFields...)
+
+	var (
+		duplicates []internaldto.PropertyDuplicate
+		page       pagination.Page
+		total      *int64
+	)
+
+	cursor, err := pagination.Decode(request.Cursor, duplicateKeyset.sort)
+	if err != nil {
+		return nil, page, err
+	}
+
+	query := r.db.Model(&internaldto.PropertyDuplicate{}).
+		Where("status = ?", constant.PropertyDuplicateStatusOpen).
+		Session(&gorm.Session{})
+
+	if request.IncludeTotal {
+		var count int64
+		if err = query.Count(&count).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("PropertyDuplicate"), log.TraceError(commonLogFields, err)...)
+			return nil, page, err
+		}
+		total = &count
+	}
+
+	pageSize := pagination.Size(request.PageSize)
+	if err = duplicateKeyset.page(query, cursor, pageSize).Find(&duplicates).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyDuplicate"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
+
+	duplicates, page = pagination.Trim(duplicates, cursor, pageSize, duplicateKeyset.sort, func(d internaldto.PropertyDuplicate) (any, uint) {
+		return d.Score, d.ID
+	})
+	page.Total = total
+	return duplicates, page, nil
+}
+
+// GetDuplicateByID reads a duplicate flag
+func (r *propertyRepository) GetDuplicateByID(id uint) (internaldto.PropertyDuplicate, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryGetDuplicateByIDMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryGetDuplicateByIDMethod), commonLogFields...)
+
+	var duplicate internaldto.PropertyDuplicate
+	if err := r.db.Where("id = ?", id).First(&duplicate).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyDuplicate"), log.TraceError(commonLogFields, err)...)
+		return duplicate, err
+	}
+
+	return duplicate, nil
+}
+
+// MergeDuplicate resolves an open duplicate flag by archiving the listing that is not kept, recording
+// the transition with archiveNote. It returns gorm.ErrRecordNotFound when the flag is no longer open
+// or the listing changed status meanwhile.
+func (r *propertyRepository) MergeDuplicate(id uint, archive dto.Property, resolvedBy uint, note, archiveNote string) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryMergeDuplicateMethod), log.TraceMethodInputs(commonLogFields, id, archive.ID, resolvedBy, note)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryMergeDuplicateMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := resolveDuplicate(tx, id, constant.PropertyDuplicateStatusMerged, resolvedBy, note)
+		if err != nil || archive.Status == constant.PropertyStatusArchived {
+			return err
+		}
+
+		return r.changeStatus(tx, archive.ID, archive.Status, constant.PropertyStatusArchived, resolvedBy, archiveNote, nil)
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PropertyRepositoryMergeDuplicateMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// DismissDuplicate resolves an open duplicate flag as not a duplicate, leaving both listings as they are.
+// It returns gorm.ErrRecordNotFound when the flag is no longer open.
+func (r *propertyRepository) DismissDuplicate(id uint, resolvedBy uint, note string) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryDismissDuplicateMethod), log.TraceMethodInputs(commonLogFields, id, resolvedBy, note)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryDismissDuplicateMethod), commonLogFields...)
+
+	if err := resolveDuplicate(r.db, id, constant.PropertyDuplicateStatusDismissed, resolvedBy, note); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PropertyRepositoryDismissDuplicateMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// resolveDuplicate moves an open duplicate flag to the given status
+func resolveDuplicate(tx *gorm.DB, id uint, status string, resolvedBy uint, note string) error {
+	result := tx.Model(&internaldto.PropertyDuplicate{}).
+		Where("id = ? AND status = ?", id, constant.PropertyDuplicateStatusOpen).
+		Updates(map[string]any{
+			"status":      status,
+			"resolved_by": resolvedBy,
+			"resolved_at": time.Now(),
+			"note":        note,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property expiry methods
+	PropertyRepositoryListExpiredMethod        = "PropertyRepositoryListExpired"
+	PropertyRepositoryListExpiringMethod       = "PropertyRepositoryListExpiring"
+	PropertyRepositoryMarkExpiryRemindedMethod = "PropertyRepositoryMarkExpiryReminded"
+	PropertyRepositoryRenewMethod              = "PropertyRepositoryRenew"
+	PropertyRepositoryCountRenewalsMethod      = "PropertyRepositoryCountRenewals"
+)
+
+// ListExpired lists published properties whose listing period ended by now, the longest overdue first
+func (r *propertyRepository) ListExpired(now time.Time, limit int) ([]dto.Property, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListExpiredMethod), log.TraceMethodInputs(commonLogFields, now, limit)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListExpiredMethod), commonLogFields...)
+
+	var properties []dto.Property
+	err := r.db.Where("status = ? AND expires_at <= ?", constant.PropertyStatusPublished, now).
+		Order("expires_at, id").Limit(limit).Find(&properties).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+// ListExpiring lists published properties whose listing period ends after now but by before,
+// leaving out those whose owner was already reminded
+func (r *propertyRepository) ListExpiring(now, before time.Time, limit int) ([]dto.Property, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListExpiringMethod), log.TraceMethodInputs(commonLogFields, now, before, limit)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListExpiringMethod), commonLogFields...)
+
+	var properties []dto.Property
+	err := r.db.Where("status = ? AND expires_at > ? AND expires_at <= ? AND expiry_reminded_at IS NULL", constant.PropertyStatusPublished, now, before).
+		Order("expires_at, id").Limit(limit).Find(&properties).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return properties, nil
+}
+
+// MarkExpiryReminded records that the owner was reminded of the coming expiry of a property.
+// It returns gorm.ErrRecordNotFound when the owner was already reminded.
+func (r *propertyRepository) MarkExpiryReminded(id uint, remindedAt time.Time) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryMarkExpiryRemindedMethod), log.TraceMethodInputs(commonLogFields, id, remindedAt)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryMarkExpiryRemindedMethod), commonLogFields...)
+
+	// Matching on the empty reminder keeps two running jobs from both sending it
+	result := r.db.Model(&dto.Property{}).Where("id = ? AND expiry_reminded_at IS NULL", id).Update("expiry_reminded_at", remindedAt)
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("Property"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Renew extends the listing period of a property and records the renewal, publishing the
+// property again when it had expired. It returns gorm.ErrRecordNotFound when the property
+// is no longer in the status it was read in.
+func (r *propertyRepository) Renew(property dto.Property, expiresAt time.Time, userID uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryRenewMethod), log.TraceMethodInputs(commonLogFields, property.ID, property.Status, expiresAt, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryRenewMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{"expires_at": expiresAt, "expiry_reminded_at": nil}
+		republish := property.Status == constant.PropertyStatusExpired
+		if republish {
+			now := time.Now()
+			updates["status"] = constant.PropertyStatusPublished
+			updates["status_changed_at"] = now
+			updates["published_at"] = now
+		}
+
+		result := tx.Model(&dto.Property{}).Where("id = ? AND status = ?", property.ID, property.Status).Updates(updates)
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("Property"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if republish {
+			err := createStatusChange(tx, property.ID, property.Status, constant.PropertyStatusPublished, userID, constant.ListingRenewedNote)
+			if err != nil {
+				log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyStatusChange"), log.TraceError(commonLogFields, err)...)
+				return err
+			}
+		}
+
+		renewal := &internaldto.PropertyRenewal{
+			PropertyID:        property.ID,
+			UserID:            userID,
+			PreviousExpiresAt: property.ExpiresAt,
+			ExpiresAt:         expiresAt,
+		}
+		if err := tx.Create(renewal).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyRenewal"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PropertyRepositoryRenewMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// CountRenewalsByUserID counts the renewals made by the user since the given time
+func (r *propertyRepository) CountRenewalsByUserID(userID uint, since time.Time) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryCountRenewalsMethod), log.TraceMethodInputs(commonLogFields, userID, since)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryCountRenewalsMethod), commonLogFields...)
+
+	var count int64
+	err := r.db.Model(&internaldto.PropertyRenewal{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyRenewal"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
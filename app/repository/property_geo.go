@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/geo"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property location methods
+	MigratePropertyLocationMethod = "MigratePropertyLocation"
+)
+
+const (
+	// haversineDistanceKm is the great-circle distance in km between a listing and the point given
+	// as (radius, lat, lat, lng); LEAST guards ASIN against rounding just above 1
+	haversineDistanceKm = "? * 2 * ASIN(LEAST(1, SQRT(POWER(SIN(RADIANS(latitude - ?) / 2), 2) + " +
+		"COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2))))"
+	// postGISPoint builds a geography point from (lng, lat)
+	postGISPoint = "ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography"
+	// propertyDistanceAlias is the selected distance from the filter's point
+	propertyDistanceAlias = "distance_km"
+)
+
+// postGISEnabled is set at start up when properties.location is available
+var postGISEnabled bool
+
+// propertyLocationMigrations add a geography column PostgreSQL keeps in sync with latitude and longitude
+var propertyLocationMigrations = []string{
+	`ALTER TABLE properties ADD COLUMN IF NOT EXISTS location geography(Point, 4326)
+		GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(longitude, latitude), 4326)::geography) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_properties_on_location ON properties USING GIST (location)`,
+}
+
+// MigratePropertyLocation adds the PostGIS location column and index to properties when the
+// postgis extension is installed. Without it location queries fall back to Haversine distances.
+func MigratePropertyLocation() error {
+	log.Logger.Debug(log.TraceMsgFuncStart(MigratePropertyLocationMethod))
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(MigratePropertyLocationMethod))
+
+	if !dbconfig.IsPostgres() {
+		return nil
+	}
+
+	db := dbconfig.GetDBConnection()
+
+	var installed bool
+	err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis')").Scan(&installed).Error
+	if err != nil || !installed {
+		return err
+	}
+
+	for _, statement := range propertyLocationMigrations {
+		if err = db.Exec(statement).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(MigratePropertyLocationMethod), log.TraceError(nil, err)...)
+			return err
+		}
+	}
+
+	postGISEnabled = true
+	return nil
+}
+
+// applyLocation restricts a property query to the radius, viewport and polygon in the filter
+// and selects distance_km when a point is given.
+func (r *propertyRepository) applyLocation(query *gorm.DB, columns *propertyColumns, filter dto.PropertyFilterRequest) (*gorm.DB, error) {
+	if filter.Latitude != nil && filter.Longitude != nil {
+		center := geo.Point{Lat: *filter.Latitude, Lng: *filter.Longitude}
+		if postGISEnabled {
+			columns.add("ST_Distance(location, "+postGISPoint+") / 1000", propertyDistanceAlias, center.Lng, center.Lat)
+			if filter.RadiusKm != nil {
+				query = query.Where("ST_DWithin(location, "+postGISPoint+", ?)", center.Lng, center.Lat, *filter.RadiusKm*1000)
+			}
+		} else {
+			columns.add(haversineDistanceKm, propertyDistanceAlias, geo.EarthRadiusKm, center.Lat, center.Lat, center.Lng)
+			if filter.RadiusKm != nil {
+				query = withinBounds(query, geo.RadiusBounds(center, *filter.RadiusKm)).
+					Where(haversineDistanceKm+" <= ?", geo.EarthRadiusKm, center.Lat, center.Lat, center.Lng, *filter.RadiusKm)
+			}
+		}
+	}
+
+	if filter.MinLat != nil && filter.MaxLat != nil && filter.MinLng != nil && filter.MaxLng != nil {
+		query = withinBounds(query, geo.Bounds{MinLat: *filter.MinLat, MinLng: *filter.MinLng, MaxLat: *filter.MaxLat, MaxLng: *filter.MaxLng})
+	}
+
+	if filter.Polygon == "" {
+		return query, nil
+	}
+
+	polygon, err := geo.ParsePolygon(filter.Polygon)
+	if err != nil {
+		return nil, err
+	}
+	if postGISEnabled {
+		return query.Where("ST_Covers(ST_GeomFromText(?, 4326), location::geometry)", geo.PolygonWKT(polygon)), nil
+	}
+
+	// Without PostGIS the polygon's bounding box narrows the candidates, which are then tested in Go
+	var candidates []struct {
+		ID        uint
+		Latitude  float64
+		Longitude float64
+	}
+	err = withinBounds(publicListings(r.db.Model(&dto.Property{})), geo.PolygonBounds(polygon)).
+		Select("id, latitude, longitude").
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for _, candidate := range candidates {
+		if geo.Contains(polygon, geo.Point{Lat: candidate.Latitude, Lng: candidate.Longitude}) {
+			ids = append(ids, candidate.ID)
+		}
+	}
+
+	return query.Where("id IN ?", ids), nil
+}
+
+// withinBounds restricts a property query to a latitude/longitude rectangle, which may cross the antimeridian
+func withinBounds(query *gorm.DB, bounds geo.Bounds) *gorm.DB {
+	query = query.Where("latitude BETWEEN ? AND ?", bounds.MinLat, bounds.MaxLat)
+	if bounds.MinLng <= bounds.MaxLng {
+		return query.Where("longitude BETWEEN ? AND ?", bounds.MinLng, bounds.MaxLng)
+	}
+	return query.Where("(longitude >= ? OR longitude <= ?)", bounds.MinLng, bounds.MaxLng)
+}
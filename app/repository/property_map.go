@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+)
+
+const (
+	// Property map methods
+	PropertyRepositoryListClustersMethod = "PropertyRepositoryListClusters"
+	PropertyRepositoryListPinsMethod     = "PropertyRepositoryListPins"
+)
+
+// ListClusters groups the listings matching the filter into a grid of cellSize degree cells
+func (r *propertyRepository) ListClusters(filter dto.PropertyFilterRequest, cellSize float64) ([]dto.PropertyCluster, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListClustersMethod), log.TraceMethodInputs(commonLogFields, filter, cellSize)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListClustersMethod), commonLogFields...)
+
+	// Ranks, snippets and distances are not needed for aggregates
+	query, _, err := r.filteredQuery(filter, &propertyColumns{})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	var clusters []dto.PropertyCluster
+	err = query.
+		Select("COUNT(*) AS count, AVG(latitude) AS latitude, AVG(longitude) AS longitude, "+
+			"MIN(price) AS min_price, MAX(price) AS max_price, "+
+			"FLOOR(latitude / ?) AS cell_lat, FLOOR(longitude / ?) AS cell_lng", cellSize, cellSize).
+		Group("cell_lat, cell_lng").
+		Order("count DESC").
+		Scan(&clusters).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return clusters, nil
+}
+
+// ListPins lists the location and price of up to limit listings matching the filter, newest first
+func (r *propertyRepository) ListPins(filter dto.PropertyFilterRequest, limit int) ([]dto.PropertyPin, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListPinsMethod), log.TraceMethodInputs(commonLogFields, filter, limit)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListPinsMethod), commonLogFields...)
+
+	query, _, err := r.filteredQuery(filter, &propertyColumns{})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	var pins []dto.PropertyPin
+	err = query.
+		Select("id, title, price, price_unit, pricing_type, latitude, longitude").
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Scan(&pins).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return pins, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property moderation methods
+	PropertyRepositoryListPendingReviewMethod       = "PropertyRepositoryListPendingReview"
+	PropertyRepositoryModerateMethod                = "PropertyRepositoryModerate"
+	PropertyRepositoryListModerationDecisionsMethod = "PropertyRepositoryListModerationDecisions"
+	PropertyRepositoryCreateScreeningMethod         = "PropertyRepositoryCreateScreening"
+	PropertyRepositoryListScreeningsMethod          = "PropertyRepositoryListScreenings"
+)
+
+// pendingReviewKeyset orders the moderation queue by the time listings were submitted, oldest first
+var pendingReviewKeyset = columnKeyset("submitted:asc", "status_changed_at", "id", false)
+
+// pendingReviewPosition reads the position of a property in the moderation queue
+func pendingReviewPosition(p dto.Property) (any, uint) {
+	if p.StatusChangedAt == nil {
+		return nil, p.ID
+	}
+	return p.StatusChangedAt.UTC().Format(pagination.TimeLayout), p.ID
+}
+
+// ListPendingReview lists one page of the properties waiting for moderation, longest waiting first
+func (r *propertyRepository) ListPendingReview(request dto.PageRequest) ([]dto.Property, pagination.Page, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListPendingReviewMethod), log.TraceMethodInputs(commonLogFields, request)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListPendingReviewMethod), commonLogFields...)
+
+	query := r.db.Model(&dto.Property{}).Where("status = ?", constant.PropertyStatusPendingReview).Session(&gorm.Session{})
+	properties, page, err := pageProperties(query, nil, pendingReviewKeyset, pendingReviewPosition, request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
+
+	return properties, page, nil
+}
+
+// Moderate moves a property under review to the status of the decision and records both the
+// transition and the decision. It returns gorm.ErrRecordNotFound when the property is no
+// longer under review.
+func (r *propertyRepository) Moderate(id uint, to string, decision internaldto.PropertyModerationDecision, expiresAt *time.Time) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryModerateMethod), log.TraceMethodInputs(commonLogFields, id, to, decision)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryModerateMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := r.changeStatus(tx, id, constant.PropertyStatusPendingReview, to, decision.ModeratorID, decision.Note, expiresAt)
+		if err != nil {
+			return err
+		}
+
+		decision.PropertyID = id
+		if err = tx.Create(&decision).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyModerationDecision"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PropertyRepositoryModerateMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// ListModerationDecisions lists the moderation decisions on a property, oldest first
+func (r *propertyRepository) ListModerationDecisions(id uint) ([]internaldto.PropertyModerationDecision, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListModerationDecisionsMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListModerationDecisionsMethod), commonLogFields...)
+
+	var decisions []internaldto.PropertyModerationDecision
+	err := r.db.Where("property_id = ?", id).Order("created_at, id").Find(&decisions).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyModerationDecision"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return decisions, nil
+}
+
+// CreateScreening records a content screening that flagged the text of a property
+func (r *propertyRepository) CreateScreening(screening *internaldto.PropertyScreening) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryCreateScreeningMethod), log.TraceMethodInputs(commonLogFields, screening)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryCreateScreeningMethod), commonLogFields...)
+
+	if err := r.db.Create(screening).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyScreening"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// ListScreenings lists the content screenings that flagged a property, oldest first
+func (r *propertyRepository) ListScreenings(id uint) ([]internaldto.PropertyScreening, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListScreeningsMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListScreeningsMethod), commonLogFields...)
+
+	var screenings []internaldto.PropertyScreening
+	err := r.db.Where("property_id = ?", id).Order("created_at, id").Find(&screenings).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyScreening"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return screenings, nil
+}
@@ -1,31 +1,94 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
 	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
 	"gorm.io/gorm"
 )
 
 const (
 	// Property repository methods
-	PropertyRepositoryCreateMethod      = "PropertyRepositoryCreate"
-	PropertyRepositoryGetByIDMethod     = "PropertyRepositoryGetByID"
-	PropertyRepositoryUpdateMethod      = "PropertyRepositoryUpdate"
-	PropertyRepositoryDeleteMethod      = "PropertyRepositoryDelete"
-	PropertyRepositoryListMethod        = "PropertyRepositoryList"
-	PropertyRepositoryCheckExistsMethod = "PropertyRepositoryCheckExists"
+	PropertyRepositoryCreateMethod        = "PropertyRepositoryCreate"
+	PropertyRepositoryGetByIDMethod       = "PropertyRepositoryGetByID"
+	PropertyRepositoryUpdateMethod        = "PropertyRepositoryUpdate"
+	PropertyRepositoryDeleteMethod        = "PropertyRepositoryDelete"
+	PropertyRepositoryListMethod          = "PropertyRepositoryList"
+	PropertyRepositoryCheckExistsMethod   = "PropertyRepositoryCheckExists"
+	PropertyRepositoryGetOwnershipMethod  = "PropertyRepositoryGetOwnership"
+	PropertyRepositoryCountByUserMethod   = "PropertyRepositoryCountByUser"
+	PropertyRepositoryListAllByUserMethod = "PropertyRepositoryListAllByUser"
+	PropertyRepositoryListByOrgMethod     = "PropertyRepositoryListByOrganization"
+	PropertyRepositoryCountByOrgMethod    = "PropertyRepositoryCountByOrganization"
+
+	PropertyRepositoryListPublicByUserMethod  = "PropertyRepositoryListPublicByUser"
+	PropertyRepositoryCountPublicByUserMethod = "PropertyRepositoryCountPublicByUser"
+	PropertyRepositoryListPublicByOrgMethod   = "PropertyRepositoryListPublicByOrganization"
+	PropertyRepositoryCountPublicByOrgMethod  = "PropertyRepositoryCountPublicByOrganization"
 )
 
 type PropertyRepository interface {
 	Create(request dto.PropertyRequest) (uint, error)
 	GetByID(id uint) (dto.Property, error)
-	Update(id uint, request dto.PropertyRequest) error
+	Update(id uint, request dto.PropertyRequest, changedBy uint, reviewNote string) error
 	Delete(id uint) error
-	List(offset, limit int) ([]dto.Property, error)
+	List(filter dto.PropertyFilterRequest) ([]dto.Property, pagination.Page, error)
 	CheckExists(id uint) (bool, error)
-	ListByUserID(userID uint, offset, limit int) ([]dto.Property, error)
+	ListByUserID(userID uint, request dto.PageRequest, statuses ...string) ([]dto.Property, pagination.Page, error)
+	ListAllByUserID(userID uint) ([]dto.Property, error)
+	GetOwnership(id uint) (uint, *uint, error)
+	CountByUserID(userID uint, statuses ...string) (int64, error)
+	ListByOrganizationID(organizationID uint, request dto.PageRequest, statuses ...string) ([]dto.Property, pagination.Page, error)
+	CountByOrganizationID(organizationID uint, statuses ...string) (int64, error)
+	ListPublicByUserID(userID uint, request dto.PageRequest) ([]dto.Property, pagination.Page, error)
+	CountPublicByUserID(userID uint) (int64, error)
+	ListPublicByOrganizationID(organizationID uint, request dto.PageRequest) ([]dto.Property, pagination.Page, error)
+	CountPublicByOrganizationID(organizationID uint) (int64, error)
+	ListClusters(filter dto.PropertyFilterRequest, cellSize float64) ([]dto.PropertyCluster, error)
+	ListPins(filter dto.PropertyFilterRequest, limit int) ([]dto.PropertyPin, error)
+	ChangeStatus(id uint, from, to string, changedBy uint, note string, expiresAt *time.Time) error
+	ListStatusChanges(id uint) ([]internaldto.PropertyStatusChange, error)
+	ListExpired(now time.Time, limit int) ([]dto.Property, error)
+	ListExpiring(now, before time.Time, limit int) ([]dto.Property, error)
+	MarkExpiryReminded(id uint, remindedAt time.Time) error
+	Renew(property dto.Property, expiresAt time.Time, userID uint) error
+	CountRenewalsByUserID(userID uint, since time.Time) (int64, error)
+	ListPendingReview(request dto.PageRequest) ([]dto.Property, pagination.Page, error)
+	Moderate(id uint, to string, decision internaldto.PropertyModerationDecision, expiresAt *time.Time) error
+	ListModerationDecisions(id uint) ([]internaldto.PropertyModerationDecision, error)
+	CreateScreening(screening *internaldto.PropertyScreening) error
+	ListScreenings(id uint) ([]internaldto.PropertyScreening, error)
+	ListDuplicateCandidates(property dto.Property, radiusKm, priceTolerance float64, limit int) ([]dto.Property, error)
+	SaveDuplicate(duplicate internaldto.PropertyDuplicate) error
+	ListDuplicates(request dto.PageRequest) ([]internaldto.PropertyDuplicate, pagination.Page, error)
+	GetDuplicateByID(id uint) (internaldto.PropertyDuplicate, error)
+	MergeDuplicate(id uint, archive dto.Property, resolvedBy uint, note, archiveNote string) error
+	DismissDuplicate(id uint, resolvedBy uint, note string) error
+	ListRevisions(id uint) ([]internaldto.PropertyRevision, error)
+	GetRevision(id uint, version int) (internaldto.PropertyRevision, error)
+}
+
+// propertySortColumns maps the sort fields accepted by the list endpoint onto property columns.
+var propertySortColumns = map[string]string{
+	constant.PropertySortPrice: "price",
+	constant.PropertySortDate:  "created_at",
+	constant.PropertySortSize:  "size",
+}
+
+// propertyContentColumns are the property columns an update writes. The organization is not among
+// them, an update only writes it when the listing is moved to another organization.
+var propertyContentColumns = []string{
+	"user_id", "title", "description", "purpose_id", "property_type_id", "furniture_type_id",
+	"condition_id", "bedrooms", "bathrooms", "size", "size_unit", "city", "address", "postal_code", "latitude",
+	"longitude", "price", "price_unit", "is_negotiable", "rental_period", "is_refundable", "pricing_type", "updated_at",
 }
 
 type propertyRepository struct {
@@ -122,6 +185,7 @@ func (r *propertyRepository) createPropertyImages(tx *gorm.DB, propertyID uint,
 func (r *propertyRepository) mapRequestToProperty(request dto.PropertyRequest) dto.Property {
 	return dto.Property{
 		UserID:          request.UserID,
+		OrganizationID:  request.OrganizationID,
 		Title:           request.Title,
 		Description:     request.Description,
 		PurposeID:       uint(request.PurposeID),
@@ -146,6 +210,20 @@ func (r *propertyRepository) mapRequestToProperty(request dto.PropertyRequest) d
 	}
 }
 
+// updatePropertyContent writes every column an edit covers, zero values included, so an edit can
+// clear a field such as the description. A request without an organization keeps the current one.
+func (r *propertyRepository) updatePropertyContent(tx *gorm.DB, id uint, request dto.PropertyRequest) *gorm.DB {
+	columns := propertyContentColumns
+	if request.OrganizationID != nil {
+		columns = append([]string{"organization_id"}, propertyContentColumns...)
+	}
+
+	return tx.Model(&dto.Property{}).
+		Where("id = ?", id).
+		Select(columns).
+		Updates(r.mapRequestToProperty(request))
+}
+
 // updateRelatedEntities is a generic function to update related entities with soft delete support
 func updateRelatedEntities[T any, K comparable](
 	tx *gorm.DB,
@@ -418,6 +496,13 @@ func (r *propertyRepository) Create(request dto.PropertyRequest) (uint, error) {
 
 	// Map request to property entity
 	property := r.mapRequestToProperty(request)
+	now := time.Now()
+	property.Status = request.Status
+	property.StatusChangedAt = &now
+	if property.Status == constant.PropertyStatusPublished {
+		property.PublishedAt = &now
+	}
+	property.ExpiresAt = request.ExpiresAt
 
 	// Create property and its associations in a transaction
 	err := r.db.Transaction(func(tx *gorm.DB) error {
@@ -427,6 +512,12 @@ func (r *propertyRepository) Create(request dto.PropertyRequest) (uint, error) {
 			return err
 		}
 
+		// The first status of a listing opens its status history
+		if err := createStatusChange(tx, property.ID, constant.Empty, property.Status, request.UserID, constant.Empty); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyStatusChange"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
 		// Create property images
 		if err := r.createPropertyImages(tx, property.ID, request.Images); err != nil {
 			return err
@@ -442,7 +533,8 @@ func (r *propertyRepository) Create(request dto.PropertyRequest) (uint, error) {
 			return err
 		}
 
-		return nil
+		// The listing as created is its first revision
+		return r.createRevision(tx, property.ID, request.UserID, constant.Empty)
 	})
 
 	if err != nil {
@@ -473,17 +565,21 @@ func (r *propertyRepository) GetByID(id uint) (dto.Property, error) {
 	return property, nil
 }
 
-func (r *propertyRepository) Update(id uint, request dto.PropertyRequest) error {
+// Update stores the new content of a property. A reviewNote sends a published
+// property back to review along with the edit.
+func (r *propertyRepository) Update(id uint, request dto.PropertyRequest, changedBy uint, reviewNote string) error {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryUpdateMethod), log.TraceMethodInputs(commonLogFields, id, request)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryUpdateMethod), log.TraceMethodInputs(commonLogFields, id, request, changedBy, reviewNote)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryUpdateMethod), commonLogFields...)
 
 	err := r.db.Transaction(func(tx *gorm.DB) error {
-		// Update property
-		property := r.mapRequestToProperty(request)
-		property.ID = id
+		if err := r.createBaselineRevision(tx, id); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
 
-		if err := tx.Model(&dto.Property{}).Where(dto.Property{ID: id}).Updates(property).Error; err != nil {
+		// Update property
+		if err := r.updatePropertyContent(tx, id, request).Error; err != nil {
 			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("Property"), log.TraceError(commonLogFields, err)...)
 			return err
 		}
@@ -503,7 +599,12 @@ func (r *propertyRepository) Update(id uint, request dto.PropertyRequest) error
 			return err
 		}
 
-		return nil
+		// The listing as updated is stored as its next revision
+		if err := r.createRevision(tx, id, changedBy, request.RevisionNote); err != nil {
+			return err
+		}
+
+		return r.sendToReview(tx, id, changedBy, reviewNote)
 	})
 
 	if err != nil {
@@ -557,25 +658,245 @@ func (r *propertyRepository) Delete(id uint) error {
 	return nil
 }
 
-func (r *propertyRepository) List(offset, limit int) ([]dto.Property, error) {
+// List lists one page of the properties matching the filter
+func (r *propertyRepository) List(filter dto.PropertyFilterRequest) ([]dto.Property, pagination.Page, error) {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListMethod), log.TraceMethodInputs(commonLogFields, offset, limit)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListMethod), log.TraceMethodInputs(commonLogFields, filter)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListMethod), commonLogFields...)
 
-	var properties []dto.Property
-	err := r.db.Preload("PropertyAmenities").Preload("PropertyUtilities").Preload("PropertyImages").
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
-		Find(&properties).Error
+	var (
+		properties []dto.Property
+		columns    propertyColumns
+		page       pagination.Page
+	)
+	query, ranked, err := r.filteredQuery(filter, &columns)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
 
+	order, position := propertyKeyset(filter, ranked, columns)
+	properties, page, err = pageProperties(query.Session(&gorm.Session{}), columns, order, position, filter.PageRequest)
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
-		return nil, err
+		return nil, page, err
 	}
 
 	log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListMethod), log.TraceMethodOutputWithErr(commonLogFields, properties, err)...)
-	return properties, nil
+	return properties, page, nil
+}
+
+// pageProperties reads the page of query described by request, counting the rows too when asked.
+// query must be a new session so it can be both counted and listed.
+func pageProperties(query *gorm.DB, columns propertyColumns, order keyset, position func(dto.Property) (any, uint), request dto.PageRequest) ([]dto.Property, pagination.Page, error) {
+	var (
+		properties []dto.Property
+		page       pagination.Page
+		total      *int64
+	)
+
+	cursor, err := pagination.Decode(request.Cursor, order.sort)
+	if err != nil {
+		return nil, page, err
+	}
+
+	if request.IncludeTotal {
+		var count int64
+		if err = query.Count(&count).Error; err != nil {
+			return nil, page, err
+		}
+		total = &count
+	}
+
+	pageSize := pagination.Size(request.PageSize)
+	err = order.page(columns.apply(query), cursor, pageSize).
+		Preload("PropertyAmenities").Preload("PropertyUtilities").Preload("PropertyImages").
+		Find(&properties).Error
+	if err != nil {
+		return nil, page, err
+	}
+
+	properties, page = pagination.Trim(properties, cursor, pageSize, order.sort, position)
+	page.Total = total
+	return properties, page, nil
+}
+
+// filteredQuery builds the published property query shared by the list and map endpoints, selecting
+// any computed columns into columns. It reports whether the results can be ordered by rank.
+func (r *propertyRepository) filteredQuery(filter dto.PropertyFilterRequest, columns *propertyColumns) (*gorm.DB, bool, error) {
+	query := publicListings(r.db.Model(&dto.Property{}))
+	query, ranked := r.applySearch(r.applyFilter(query, filter), columns, filter.Query)
+	query, err := r.applyLocation(query, columns, filter)
+	return query, ranked, err
+}
+
+// publicListings restricts a property query to the listings shown to everyone: published and within
+// their listing period, as the expiry job may not have caught up with an overdue listing yet
+func publicListings(query *gorm.DB) *gorm.DB {
+	return query.Where("status = ? AND (expires_at IS NULL OR expires_at > ?)", constant.PropertyStatusPublished, time.Now())
+}
+
+// applyFilter narrows a property query down to the listings matching the filter.
+// Amenities and utilities must all be present on a listing for it to match.
+func (r *propertyRepository) applyFilter(query *gorm.DB, filter dto.PropertyFilterRequest) *gorm.DB {
+	if filter.PurposeID != 0 {
+		query = query.Where("purpose_id = ?", filter.PurposeID)
+	}
+	if filter.PropertyTypeID != 0 {
+		query = query.Where("property_type_id = ?", filter.PropertyTypeID)
+	}
+	if filter.PricingType != "" {
+		query = query.Where("pricing_type = ?", filter.PricingType)
+	}
+	if filter.FurnitureTypeID != 0 {
+		query = query.Where("furniture_type_id = ?", filter.FurnitureTypeID)
+	}
+	if filter.ConditionID != 0 {
+		query = query.Where("condition_id = ?", filter.ConditionID)
+	}
+	if city := strings.TrimSpace(filter.City); city != "" {
+		query = query.Where("LOWER(city) = LOWER(?)", city)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.MinBedrooms > 0 {
+		query = query.Where("bedrooms >= ?", filter.MinBedrooms)
+	}
+	if filter.MinBathrooms > 0 {
+		query = query.Where("bathrooms >= ?", filter.MinBathrooms)
+	}
+	if filter.MinSize != nil {
+		query = query.Where("size >= ?", *filter.MinSize)
+	}
+	if filter.MaxSize != nil {
+		query = query.Where("size <= ?", *filter.MaxSize)
+	}
+	if filter.IsNegotiable != nil {
+		query = query.Where("is_negotiable = ?", *filter.IsNegotiable)
+	}
+	if ids := uniqueIDs(filter.AmenityIDs); len(ids) > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&dto.PropertyAmenity{}).
+			Select("property_id").
+			Where("amenity_id IN ?", ids).
+			Group("property_id").
+			Having("COUNT(DISTINCT amenity_id) = ?", len(ids)))
+	}
+	if ids := uniqueIDs(filter.UtilityIDs); len(ids) > 0 {
+		query = query.Where("id IN (?)", r.db.Model(&dto.PropertyUtility{}).
+			Select("property_id").
+			Where("utility_id IN ?", ids).
+			Group("property_id").
+			Having("COUNT(DISTINCT utility_id) = ?", len(ids)))
+	}
+
+	return query
+}
+
+// propertyColumn is a computed column selected alongside properties.*
+type propertyColumn struct {
+	expression string
+	alias      string
+	args       []any
+}
+
+// propertyColumns collects the computed columns selected alongside properties.*
+type propertyColumns []propertyColumn
+
+func (c *propertyColumns) add(expression, alias string, args ...any) {
+	*c = append(*c, propertyColumn{expression: expression, alias: alias, args: args})
+}
+
+func (c propertyColumns) get(alias string) propertyColumn {
+	for _, column := range c {
+		if column.alias == alias {
+			return column
+		}
+	}
+	return propertyColumn{}
+}
+
+func (c propertyColumns) apply(query *gorm.DB) *gorm.DB {
+	if len(c) == 0 {
+		return query
+	}
+
+	var (
+		expressions = make([]string, 0, len(c))
+		args        []any
+	)
+	for _, column := range c {
+		expressions = append(expressions, fmt.Sprintf("%s AS %s", column.expression, column.alias))
+		args = append(args, column.args...)
+	}
+	return query.Select("properties.*, "+strings.Join(expressions, ", "), args...)
+}
+
+// propertyKeyset returns the ordering of a property list and how to read a property's position in it.
+// Keyword searches without a sort_by are ordered by relevance, anything else defaults to newest first.
+func propertyKeyset(filter dto.PropertyFilterRequest, ranked bool, columns propertyColumns) (keyset, func(dto.Property) (any, uint)) {
+	sortBy := filter.SortBy
+	if sortBy == "" {
+		sortBy = constant.PropertySortDate
+		if ranked {
+			sortBy = propertySortRelevance
+		}
+	}
+
+	descending := !strings.EqualFold(filter.SortOrder, "asc")
+	direction := "desc"
+	if !descending {
+		direction = "asc"
+	}
+
+	switch sortBy {
+	case propertySortRelevance:
+		rank := columns.get(propertySearchRankAlias)
+		order := keyset{sort: propertySortRelevance, orderBy: rank.alias, expression: rank.expression, args: rank.args, id: "id", descending: true}
+		return order, func(p dto.Property) (any, uint) { return p.SearchRank, p.ID }
+	case constant.PropertySortDistance:
+		// distance_km is only selected when the filter has a point, which the validator enforces
+		distance := columns.get(propertyDistanceAlias)
+		order := keyset{sort: sortBy + ":" + direction, orderBy: distance.alias, expression: distance.expression, args: distance.args, id: "id", descending: descending}
+		return order, func(p dto.Property) (any, uint) {
+			if p.DistanceKm == nil {
+				return nil, p.ID
+			}
+			return *p.DistanceKm, p.ID
+		}
+	case constant.PropertySortPrice:
+		return columnKeyset(sortBy+":"+direction, propertySortColumns[sortBy], "id", descending),
+			func(p dto.Property) (any, uint) { return p.Price, p.ID }
+	case constant.PropertySortSize:
+		return columnKeyset(sortBy+":"+direction, propertySortColumns[sortBy], "id", descending),
+			func(p dto.Property) (any, uint) { return p.Size, p.ID }
+	default:
+		return columnKeyset(constant.PropertySortDate+":"+direction, propertySortColumns[constant.PropertySortDate], "id", descending), newestPropertyPosition
+	}
+}
+
+// newestPropertyKeyset orders properties newest first
+var newestPropertyKeyset = columnKeyset(constant.PropertySortDate+":desc", "created_at", "id", true)
+
+// newestPropertyPosition reads the position of a property in a created_at ordering
+func newestPropertyPosition(p dto.Property) (any, uint) {
+	return p.CreatedAt.UTC().Format(pagination.TimeLayout), p.ID
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]struct{}, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		unique = append(unique, id)
+	}
+	return unique
 }
 
 func (r *propertyRepository) CheckExists(id uint) (bool, error) {
@@ -593,17 +914,34 @@ func (r *propertyRepository) CheckExists(id uint) (bool, error) {
 	return count > 0, nil
 }
 
-func (r *propertyRepository) ListByUserID(userID uint, offset, limit int) ([]dto.Property, error) {
+// ListByUserID lists one page of the properties listed by a user in any of the given statuses, or
+// in any status when none is given, newest first
+func (r *propertyRepository) ListByUserID(userID uint, request dto.PageRequest, statuses ...string) ([]dto.Property, pagination.Page, error) {
 	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListMethod), log.TraceMethodInputs(commonLogFields, userID, offset, limit)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListMethod), log.TraceMethodInputs(commonLogFields, userID, request, statuses)...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListMethod), commonLogFields...)
 
+	query := withStatuses(r.db.Model(&dto.Property{}).Where("user_id = ?", userID), statuses).Session(&gorm.Session{})
+	properties, page, err := pageProperties(query, nil, newestPropertyKeyset, newestPropertyPosition, request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
+
+	log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListMethod), log.TraceMethodOutputWithErr(commonLogFields, properties, err)...)
+	return properties, page, nil
+}
+
+// ListAllByUserID lists every property listed by a user, newest first
+func (r *propertyRepository) ListAllByUserID(userID uint) ([]dto.Property, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListAllByUserMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListAllByUserMethod), commonLogFields...)
+
 	var properties []dto.Property
 	err := r.db.Preload("PropertyAmenities").Preload("PropertyUtilities").Preload("PropertyImages").
 		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Offset(offset).
-		Limit(limit).
+		Order("created_at DESC, id DESC").
 		Find(&properties).Error
 
 	if err != nil {
@@ -611,6 +949,136 @@ func (r *propertyRepository) ListByUserID(userID uint, offset, limit int) ([]dto
 		return nil, err
 	}
 
-	log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListMethod), log.TraceMethodOutputWithErr(commonLogFields, properties, err)...)
 	return properties, nil
 }
+
+// GetOwnership returns the user who listed a property and the organization it belongs to, if any
+func (r *propertyRepository) GetOwnership(id uint) (uint, *uint, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryGetOwnershipMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryGetOwnershipMethod), commonLogFields...)
+
+	var property dto.Property
+	err := r.db.Select("id", "user_id", "organization_id").Where("id = ?", id).First(&property).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return 0, nil, err
+	}
+
+	return property.UserID, property.OrganizationID, nil
+}
+
+// CountByUserID counts the properties listed by a user in any of the given statuses, or in any
+// status when none is given, soft deleted ones excluded
+func (r *propertyRepository) CountByUserID(userID uint, statuses ...string) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryCountByUserMethod), log.TraceMethodInputs(commonLogFields, userID, statuses)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryCountByUserMethod), commonLogFields...)
+
+	var count int64
+	err := withStatuses(r.db.Model(&dto.Property{}).Where("user_id = ?", userID), statuses).Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("Property"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListByOrganizationID lists one page of the properties of an organization in any of the given
+// statuses, or in any status when none is given, newest first
+func (r *propertyRepository) ListByOrganizationID(organizationID uint, request dto.PageRequest, statuses ...string) ([]dto.Property, pagination.Page, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListByOrgMethod), log.TraceMethodInputs(commonLogFields, organizationID, request, statuses)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListByOrgMethod), commonLogFields...)
+
+	query := withStatuses(r.db.Model(&dto.Property{}).Where("organization_id = ?", organizationID), statuses).Session(&gorm.Session{})
+	properties, page, err := pageProperties(query, nil, newestPropertyKeyset, newestPropertyPosition, request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
+
+	return properties, page, nil
+}
+
+// CountByOrganizationID counts the properties of an organization in any of the given statuses, or
+// in any status when none is given, soft deleted ones excluded
+func (r *propertyRepository) CountByOrganizationID(organizationID uint, statuses ...string) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryCountByOrgMethod), log.TraceMethodInputs(commonLogFields, organizationID, statuses)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryCountByOrgMethod), commonLogFields...)
+
+	var count int64
+	err := withStatuses(r.db.Model(&dto.Property{}).Where("organization_id = ?", organizationID), statuses).Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("Property"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListPublicByUserID lists one page of the properties of a user shown to everyone, newest first
+func (r *propertyRepository) ListPublicByUserID(userID uint, request dto.PageRequest) ([]dto.Property, pagination.Page, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListPublicByUserMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListPublicByUserMethod), commonLogFields...)
+
+	query := publicListings(r.db.Model(&dto.Property{}).Where("user_id = ?", userID)).Session(&gorm.Session{})
+	properties, page, err := pageProperties(query, nil, newestPropertyKeyset, newestPropertyPosition, request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
+
+	return properties, page, nil
+}
+
+// CountPublicByUserID counts the properties of a user shown to everyone
+func (r *propertyRepository) CountPublicByUserID(userID uint) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryCountPublicByUserMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryCountPublicByUserMethod), commonLogFields...)
+
+	var count int64
+	err := publicListings(r.db.Model(&dto.Property{}).Where("user_id = ?", userID)).Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("Property"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ListPublicByOrganizationID lists one page of the properties of an organization shown to everyone, newest first
+func (r *propertyRepository) ListPublicByOrganizationID(organizationID uint, request dto.PageRequest) ([]dto.Property, pagination.Page, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListPublicByOrgMethod), log.TraceMethodInputs(commonLogFields, organizationID, request)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListPublicByOrgMethod), commonLogFields...)
+
+	query := publicListings(r.db.Model(&dto.Property{}).Where("organization_id = ?", organizationID)).Session(&gorm.Session{})
+	properties, page, err := pageProperties(query, nil, newestPropertyKeyset, newestPropertyPosition, request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return nil, page, err
+	}
+
+	return properties, page, nil
+}
+
+// CountPublicByOrganizationID counts the properties of an organization shown to everyone
+func (r *propertyRepository) CountPublicByOrganizationID(organizationID uint) (int64, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryCountPublicByOrgMethod), log.TraceMethodInputs(commonLogFields, organizationID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryCountPublicByOrgMethod), commonLogFields...)
+
+	var count int64
+	err := publicListings(r.db.Model(&dto.Property{}).Where("organization_id = ?", organizationID)).Count(&count).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenCounting("Property"), log.TraceError(commonLogFields, err)...)
+		return 0, err
+	}
+
+	return count, nil
+}
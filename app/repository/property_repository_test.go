@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// assignmentPattern reads the "column"=$n pairs of the SET clause of an update
+var assignmentPattern = regexp.MustCompile(`"(\w+)"=\$(\d+)`)
+
+func TestUpdatePropertyContentWritesZeroValues(t *testing.T) {
+	// An edit clearing every optional field of the listing
+	request := dto.PropertyRequest{
+		UserID:      3,
+		Title:       "Two bedroom apartment in Colombo",
+		City:        "Colombo",
+		Address:     "12 Galle Road",
+		Price:       25000000,
+		PriceUnit:   "LKR",
+		PricingType: "sell",
+	}
+
+	statement := (&propertyRepository{}).updatePropertyContent(dryRunDB(t), 7, request).Statement
+	assigned := assignments(statement)
+
+	for _, column := range propertyContentColumns {
+		if _, ok := assigned[column]; !ok {
+			t.Errorf("update does not write %s: %s", column, statement.SQL.String())
+		}
+	}
+
+	want := map[string]any{
+		"description":   "",
+		"bedrooms":      0,
+		"bathrooms":     0,
+		"size":          float64(0),
+		"postal_code":   "",
+		"is_negotiable": false,
+		"rental_period": "",
+		"is_refundable": false,
+	}
+	for column, value := range want {
+		if got := assigned[column]; !reflect.DeepEqual(got, value) {
+			t.Errorf("update sets %s = %#v, want %#v", column, got, value)
+		}
+	}
+
+	if got := statement.Vars[len(statement.Vars)-1]; got != uint(7) {
+		t.Errorf("update targets property %v, want 7", got)
+	}
+}
+
+func TestUpdatePropertyContentKeepsOrganization(t *testing.T) {
+	request := dto.PropertyRequest{UserID: 3, Title: "Two bedroom apartment in Colombo", PricingType: "sell"}
+
+	statement := (&propertyRepository{}).updatePropertyContent(dryRunDB(t), 7, request).Statement
+	if got, ok := assignments(statement)["organization_id"]; ok {
+		t.Errorf("update without an organization sets organization_id = %#v, want it left as is", got)
+	}
+
+	organizationID := uint(4)
+	request.OrganizationID = &organizationID
+	statement = (&propertyRepository{}).updatePropertyContent(dryRunDB(t), 7, request).Statement
+	if got := assignments(statement)["organization_id"]; !reflect.DeepEqual(got, &organizationID) {
+		t.Errorf("update moving the listing sets organization_id = %#v, want %d", got, organizationID)
+	}
+}
+
+// assignments maps the columns an update sets onto the values it sets them to
+func assignments(statement *gorm.Statement) map[string]any {
+	assigned := make(map[string]any)
+	for _, match := range assignmentPattern.FindAllStringSubmatch(statement.SQL.String(), -1) {
+		position, _ := strconv.Atoi(match[2])
+		assigned[match[1]] = statement.Vars[position-1]
+	}
+	return assigned
+}
+
+func TestPublicListingsByOwner(t *testing.T) {
+	db := dryRunDB(t)
+	var statements []string
+	err := db.Callback().Query().After("gorm:query").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	logger := log.Logger
+	log.Logger = zap.NewNop()
+	t.Cleanup(func() { log.Logger = logger })
+	r := &propertyRepository{repositoryContext: Context{RequestID: "test"}, db: db}
+
+	r.CountPublicByUserID(3)
+	r.ListPublicByUserID(3, dto.PageRequest{PageSize: 10})
+	r.CountPublicByOrganizationID(4)
+	r.ListPublicByOrganizationID(4, dto.PageRequest{PageSize: 10})
+
+	// Overdue listings the expiry job has not caught up with yet are left out like on the other public paths
+	for _, owner := range []string{"user_id", "organization_id"} {
+		found := false
+		for _, statement := range statements {
+			if strings.Contains(statement, owner+" = $1 AND (status = $2 AND (expires_at IS NULL OR expires_at > $3))") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("no public listing query by %s, ran %v", owner, statements)
+		}
+	}
+}
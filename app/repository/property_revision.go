@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property revision methods
+	PropertyRepositoryListRevisionsMethod = "PropertyRepositoryListRevisions"
+	PropertyRepositoryGetRevisionMethod   = "PropertyRepositoryGetRevision"
+)
+
+// ListRevisions lists the stored revisions of a property, oldest first
+func (r *propertyRepository) ListRevisions(id uint) ([]internaldto.PropertyRevision, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListRevisionsMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListRevisionsMethod), commonLogFields...)
+
+	var revisions []internaldto.PropertyRevision
+	err := r.db.Where("property_id = ?", id).Order("version").Find(&revisions).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetRevision reads one revision of a property
+func (r *propertyRepository) GetRevision(id uint, version int) (internaldto.PropertyRevision, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryGetRevisionMethod), log.TraceMethodInputs(commonLogFields, id, version)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryGetRevisionMethod), commonLogFields...)
+
+	var revision internaldto.PropertyRevision
+	err := r.db.Where("property_id = ? AND version = ?", id, version).First(&revision).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+		return revision, err
+	}
+
+	return revision, nil
+}
+
+// createBaselineRevision stores the content of a property that has no revisions yet, such as one created
+// before revisions were kept, so an update does not lose what the listing looked like before it
+func (r *propertyRepository) createBaselineRevision(tx *gorm.DB, id uint) error {
+	var count int64
+	if err := tx.Model(&internaldto.PropertyRevision{}).Where("property_id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	// Who last changed the listing is not known, so no user is recorded
+	return r.createRevision(tx, id, 0, constant.ListingBaselineRevisionNote)
+}
+
+// createRevision stores the current content of a property as its next revision
+func (r *propertyRepository) createRevision(tx *gorm.DB, id uint, changedBy uint, note string) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+
+	var property dto.Property
+	err := tx.Preload("PropertyAmenities").Preload("PropertyUtilities").Preload("PropertyImages").
+		Where("id = ?", id).
+		First(&property).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Property"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	snapshot, err := json.Marshal(propertySnapshot(property))
+	if err != nil {
+		return err
+	}
+
+	var version int
+	err = tx.Model(&internaldto.PropertyRevision{}).Where("property_id = ?", id).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	revision := internaldto.PropertyRevision{
+		PropertyID: id,
+		Version:    version + 1,
+		Snapshot:   string(snapshot),
+		ChangedBy:  changedBy,
+		Note:       note,
+	}
+	if err = tx.Create(&revision).Error; err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyRevision"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// propertySnapshot builds the revision content of a property read with its amenities, utilities and images.
+// Amenities and utilities are sorted so their order never shows as a change; images keep the primary first.
+func propertySnapshot(property dto.Property) dto.PropertySnapshot {
+	snapshot := dto.PropertySnapshot{
+		OrganizationID:  property.OrganizationID,
+		Title:           property.Title,
+		Description:     property.Description,
+		PurposeID:       property.PurposeID,
+		PropertyTypeID:  property.PropertyTypeID,
+		FurnitureTypeID: property.FurnitureTypeID,
+		ConditionID:     property.ConditionID,
+		Bedrooms:        property.Bedrooms,
+		Bathrooms:       property.Bathrooms,
+		Size:            property.Size,
+		SizeUnit:        property.SizeUnit,
+		City:            property.City,
+		Address:         property.Address,
+		PostalCode:      property.PostalCode,
+		Latitude:        property.Latitude,
+		Longitude:       property.Longitude,
+		Price:           property.Price,
+		PriceUnit:       property.PriceUnit,
+		IsNegotiable:    property.IsNegotiable,
+		RentalPeriod:    property.RentalPeriod,
+		IsRefundable:    property.IsRefundable,
+		PricingType:     property.PricingType,
+		AmenityIDs:      make([]uint, 0, len(property.PropertyAmenities)),
+		UtilityIDs:      make([]uint, 0, len(property.PropertyUtilities)),
+		Images:          make([]string, 0, len(property.PropertyImages)),
+	}
+
+	for _, amenity := range property.PropertyAmenities {
+		snapshot.AmenityIDs = append(snapshot.AmenityIDs, amenity.AmenityID)
+	}
+	slices.Sort(snapshot.AmenityIDs)
+
+	for _, utility := range property.PropertyUtilities {
+		snapshot.UtilityIDs = append(snapshot.UtilityIDs, utility.UtilityID)
+	}
+	slices.Sort(snapshot.UtilityIDs)
+
+	images := slices.Clone(property.PropertyImages)
+	slices.SortStableFunc(images, func(a, b dto.PropertyImage) int {
+		switch {
+		case a.IsPrimary == b.IsPrimary:
+			return int(a.ID) - int(b.ID)
+		case a.IsPrimary:
+			return -1
+		}
+		return 1
+	})
+	for _, image := range images {
+		snapshot.Images = append(snapshot.Images, image.URL)
+	}
+
+	return snapshot
+}
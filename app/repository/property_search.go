@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property search methods
+	MigratePropertySearchMethod = "MigratePropertySearch"
+)
+
+const (
+	// propertySearchConfig is the text search configuration used for both the vector and the query
+	propertySearchConfig = "english"
+	// maxSearchTerms caps how many words of a keyword query are used
+	maxSearchTerms = 10
+	// propertySortRelevance orders keyword results by rank, best match first
+	propertySortRelevance   = "relevance"
+	propertySearchRankAlias = "search_rank"
+	// propertySearchHeadlineOptions marks the matched words in the snippet
+	propertySearchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2, FragmentDelimiter= ... "
+)
+
+// propertySearchMigrations keeps properties.search_vector in sync with the listing text.
+// The column is generated, so PostgreSQL maintains it on every insert and update; the
+// configuration must stay the same as propertySearchConfig for the index to be used.
+var propertySearchMigrations = []string{
+	`ALTER TABLE properties ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('english', coalesce(city, '')), 'B') ||
+		setweight(to_tsvector('english', coalesce(description, '')), 'C') ||
+		setweight(to_tsvector('english', coalesce(address, '')), 'D')
+	) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_properties_on_search_vector ON properties USING GIN (search_vector)`,
+}
+
+// MigratePropertySearch adds the full-text search column and index to properties.
+// It is a no-op on databases other than PostgreSQL, which fall back to LIKE matching.
+func MigratePropertySearch() error {
+	log.Logger.Debug(log.TraceMsgFuncStart(MigratePropertySearchMethod))
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(MigratePropertySearchMethod))
+
+	if !dbconfig.IsPostgres() {
+		return nil
+	}
+
+	db := dbconfig.GetDBConnection()
+	for _, statement := range propertySearchMigrations {
+		if err := db.Exec(statement).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(MigratePropertySearchMethod), log.TraceError(nil, err)...)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applySearch restricts a property query to the listings matching the keywords in q.
+// On PostgreSQL every word is prefix matched against search_vector and the rank and a
+// highlighted snippet are selected; it reports whether the results can be ordered by rank.
+func (r *propertyRepository) applySearch(query *gorm.DB, columns *propertyColumns, q string) (*gorm.DB, bool) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return query, false
+	}
+
+	if !dbconfig.IsPostgres() {
+		for _, term := range terms {
+			pattern := "%" + term + "%"
+			query = query.Where("(LOWER(title) LIKE ? OR LOWER(description) LIKE ? OR LOWER(city) LIKE ? OR LOWER(address) LIKE ?)",
+				pattern, pattern, pattern, pattern)
+		}
+		return query, false
+	}
+
+	tsQuery := prefixTSQuery(terms)
+	columns.add(fmt.Sprintf("ts_rank(search_vector, to_tsquery('%s', ?))", propertySearchConfig), propertySearchRankAlias, tsQuery)
+	columns.add(fmt.Sprintf("ts_headline('%s', coalesce(title, '') || ' - ' || coalesce(description, ''), to_tsquery('%s', ?), '%s')",
+		propertySearchConfig, propertySearchConfig, propertySearchHeadlineOptions), "search_snippet", tsQuery)
+
+	return query.Where(fmt.Sprintf("search_vector @@ to_tsquery('%s', ?)", propertySearchConfig), tsQuery), true
+}
+
+// searchTerms splits a keyword query into lower-cased words, dropping punctuation so
+// the words are safe to use as tsquery lexemes and LIKE patterns.
+func searchTerms(q string) []string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > maxSearchTerms {
+		words = words[:maxSearchTerms]
+	}
+	return words
+}
+
+// prefixTSQuery builds a tsquery where every word must match, each as a prefix.
+func prefixTSQuery(terms []string) string {
+	lexemes := make([]string, len(terms))
+	for i, term := range terms {
+		lexemes[i] = term + ":*"
+	}
+	return strings.Join(lexemes, " & ")
+}
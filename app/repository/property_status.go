@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Property status methods
+	PropertyRepositoryChangeStatusMethod      = "PropertyRepositoryChangeStatus"
+	PropertyRepositoryListStatusChangesMethod = "PropertyRepositoryListStatusChanges"
+)
+
+// ChangeStatus moves a property from one status to another and records the transition,
+// starting a new listing period when expiresAt is given.
+// It returns gorm.ErrRecordNotFound when the property is no longer in the from status.
+func (r *propertyRepository) ChangeStatus(id uint, from, to string, changedBy uint, note string, expiresAt *time.Time) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryChangeStatusMethod), log.TraceMethodInputs(commonLogFields, id, from, to, changedBy)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryChangeStatusMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		return r.changeStatus(tx, id, from, to, changedBy, note, expiresAt)
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(PropertyRepositoryChangeStatusMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// ListStatusChanges lists the status history of a property, oldest first
+func (r *propertyRepository) ListStatusChanges(id uint) ([]internaldto.PropertyStatusChange, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyRepositoryListStatusChangesMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(PropertyRepositoryListStatusChangesMethod), commonLogFields...)
+
+	var changes []internaldto.PropertyStatusChange
+	err := r.db.Where("property_id = ?", id).Order("created_at, id").Find(&changes).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("PropertyStatusChange"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// changeStatus moves a property between statuses within the transaction and records the transition
+func (r *propertyRepository) changeStatus(tx *gorm.DB, id uint, from, to string, changedBy uint, note string, expiresAt *time.Time) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+
+	now := time.Now()
+	updates := map[string]any{"status": to, "status_changed_at": now}
+	if to == constant.PropertyStatusPublished {
+		updates["published_at"] = now
+	}
+	if expiresAt != nil {
+		updates["expires_at"] = *expiresAt
+		updates["expiry_reminded_at"] = nil
+	}
+
+	// Matching on the current status keeps two concurrent changes from both applying
+	result := tx.Model(&dto.Property{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("Property"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	if err := createStatusChange(tx, id, from, to, changedBy, note); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("PropertyStatusChange"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// sendToReview moves a published property back to pending review within the transaction.
+// Nothing changes without a note or when the property is not published.
+func (r *propertyRepository) sendToReview(tx *gorm.DB, id uint, changedBy uint, note string) error {
+	if note == constant.Empty {
+		return nil
+	}
+
+	err := r.changeStatus(tx, id, constant.PropertyStatusPublished, constant.PropertyStatusPendingReview, changedBy, note, nil)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// createStatusChange records a status transition of a property
+func createStatusChange(tx *gorm.DB, propertyID uint, from, to string, changedBy uint, note string) error {
+	return tx.Create(&internaldto.PropertyStatusChange{
+		PropertyID: propertyID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		Note:       note,
+	}).Error
+}
+
+// withStatuses restricts a property query to the given statuses, leaving it as is when none is given
+func withStatuses(query *gorm.DB, statuses []string) *gorm.DB {
+	if len(statuses) == 0 {
+		return query
+	}
+	return query.Where("status IN ?", statuses)
+}
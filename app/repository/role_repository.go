@@ -0,0 +1,159 @@
+package repository
+
+import (
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Role repository methods
+	RoleRepositoryListMethod            = "RoleRepositoryList"
+	RoleRepositoryGetByNamesMethod      = "RoleRepositoryGetByNames"
+	RoleRepositoryGetUserRolesMethod    = "RoleRepositoryGetUserRoles"
+	RoleRepositorySetUserRolesMethod    = "RoleRepositorySetUserRoles"
+	RoleRepositoryGetListingLimitMethod = "RoleRepositoryGetListingLimit"
+)
+
+type RoleRepository interface {
+	List() ([]internaldto.Role, error)
+	GetByNames(names []string) ([]internaldto.Role, error)
+	GetUserRoles(userID uint) ([]internaldto.Role, error)
+	SetUserRoles(userID uint, roleIDs []uint) error
+	GetListingLimit(userID uint) (int, error)
+}
+
+type roleRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateRoleRepository creates a new instance of RoleRepository
+func CreateRoleRepository(requestID string) RoleRepository {
+	return &roleRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+func (r *roleRepository) List() ([]internaldto.Role, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(RoleRepositoryListMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(RoleRepositoryListMethod), commonLogFields...)
+
+	var roles []internaldto.Role
+	err := r.db.Preload("Permissions").Order("id").Find(&roles).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Role"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) GetByNames(names []string) ([]internaldto.Role, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(RoleRepositoryGetByNamesMethod), log.TraceMethodInputs(commonLogFields, names)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(RoleRepositoryGetByNamesMethod), commonLogFields...)
+
+	var roles []internaldto.Role
+	err := r.db.Preload("Permissions").Where("name IN ?", names).Find(&roles).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("Role"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (r *roleRepository) GetUserRoles(userID uint) ([]internaldto.Role, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(RoleRepositoryGetUserRolesMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(RoleRepositoryGetUserRolesMethod), commonLogFields...)
+
+	var roles []internaldto.Role
+	err := r.db.Preload("Permissions").
+		Joins("JOIN user_roles ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Find(&roles).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("UserRole"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// SetUserRoles replaces the roles of a user in a single transaction
+func (r *roleRepository) SetUserRoles(userID uint, roleIDs []uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(RoleRepositorySetUserRolesMethod), log.TraceMethodInputs(commonLogFields, userID, roleIDs)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(RoleRepositorySetUserRolesMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&internaldto.UserRole{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("UserRole"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		if len(roleIDs) == 0 {
+			return nil
+		}
+
+		userRoles := make([]internaldto.UserRole, len(roleIDs))
+		for i, roleID := range roleIDs {
+			userRoles[i] = internaldto.UserRole{
+				UserID: userID,
+				RoleID: roleID,
+			}
+		}
+		if err := tx.Create(&userRoles).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("UserRole"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(RoleRepositorySetUserRolesMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// GetListingLimit returns the most generous listing limit of the roles of a user.
+// Users without roles get the limit of the member role. 0 means unlimited.
+func (r *roleRepository) GetListingLimit(userID uint) (int, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(RoleRepositoryGetListingLimitMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(RoleRepositoryGetListingLimitMethod), commonLogFields...)
+
+	roles, err := r.GetUserRoles(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(roles) == 0 {
+		roles, err = r.GetByNames([]string{auth.RoleMember})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	limit := 0
+	for i, role := range roles {
+		if role.ListingLimit == 0 {
+			return 0, nil
+		}
+		if i == 0 || role.ListingLimit > limit {
+			limit = role.ListingLimit
+		}
+	}
+
+	return limit, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"time"
+
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Session repository methods
+	SessionRepositoryCreateMethod           = "SessionRepositoryCreate"
+	SessionRepositoryGetByIDMethod          = "SessionRepositoryGetByID"
+	SessionRepositoryListActiveMethod       = "SessionRepositoryListActive"
+	SessionRepositoryTouchMethod            = "SessionRepositoryTouch"
+	SessionRepositoryRevokeMethod           = "SessionRepositoryRevoke"
+	SessionRepositoryRevokeAllForUserMethod = "SessionRepositoryRevokeAllForUser"
+
+	// sessionTouchInterval limits how often last_used_at is written for a session
+	sessionTouchInterval = time.Minute
+)
+
+type SessionRepository interface {
+	Create(session *internaldto.UserSession) error
+	GetByID(id uint) (*internaldto.UserSession, error)
+	ListActive(userID uint) ([]internaldto.UserSession, error)
+	Touch(id uint, expiresAt *time.Time) error
+	Revoke(userID, id uint) error
+	RevokeAllForUser(userID, exceptID uint) error
+}
+
+type sessionRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateSessionRepository creates a new instance of SessionRepository
+func CreateSessionRepository(requestID string) SessionRepository {
+	return &sessionRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+func (r *sessionRepository) Create(session *internaldto.UserSession) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(SessionRepositoryCreateMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(SessionRepositoryCreateMethod), commonLogFields...)
+
+	err := r.db.Create(session).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("UserSession"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+func (r *sessionRepository) GetByID(id uint) (*internaldto.UserSession, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(SessionRepositoryGetByIDMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(SessionRepositoryGetByIDMethod), commonLogFields...)
+
+	var session internaldto.UserSession
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("UserSession"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// ListActive lists the sessions of a user that are neither revoked nor expired
+func (r *sessionRepository) ListActive(userID uint) ([]internaldto.UserSession, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(SessionRepositoryListActiveMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(SessionRepositoryListActiveMethod), commonLogFields...)
+
+	var sessions []internaldto.UserSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("UserSession"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// Touch records that a session was used. When expiresAt is set the session is
+// extended, otherwise last_used_at is written at most once per sessionTouchInterval.
+func (r *sessionRepository) Touch(id uint, expiresAt *time.Time) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(SessionRepositoryTouchMethod), log.TraceMethodInputs(commonLogFields, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(SessionRepositoryTouchMethod), commonLogFields...)
+
+	now := time.Now()
+	query := r.db.Model(&internaldto.UserSession{}).Where("id = ?", id)
+	updates := map[string]any{"last_used_at": now}
+	if expiresAt != nil {
+		updates["expires_at"] = *expiresAt
+	} else {
+		query = query.Where("last_used_at < ?", now.Add(-sessionTouchInterval))
+	}
+
+	err := query.Updates(updates).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserSession"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// Revoke revokes a session of the user together with its refresh tokens. It returns
+// gorm.ErrRecordNotFound when the user has no such active session.
+func (r *sessionRepository) Revoke(userID, id uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(SessionRepositoryRevokeMethod), log.TraceMethodInputs(commonLogFields, userID, id)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(SessionRepositoryRevokeMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&internaldto.UserSession{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+			Update("revoked_at", now)
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserSession"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		err := tx.Model(&internaldto.RefreshToken{}).
+			Where("session_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", now).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("RefreshToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(SessionRepositoryRevokeMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeAllForUser revokes every session of the user except exceptID, together with
+// their refresh tokens. Pass 0 to revoke all sessions.
+func (r *sessionRepository) RevokeAllForUser(userID, exceptID uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(SessionRepositoryRevokeAllForUserMethod), log.TraceMethodInputs(commonLogFields, userID, exceptID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(SessionRepositoryRevokeAllForUserMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Model(&internaldto.UserSession{}).
+			Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserSession"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		err = tx.Model(&internaldto.RefreshToken{}).
+			Where("user_id = ? AND session_id <> ? AND revoked_at IS NULL", userID, exceptID).
+			Update("revoked_at", now).Error
+		if err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("RefreshToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(SessionRepositoryRevokeAllForUserMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"time"
+
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Token repository methods
+	TokenRepositoryCreateRefreshTokenMethod    = "TokenRepositoryCreateRefreshToken"
+	TokenRepositoryGetRefreshTokenByHashMethod = "TokenRepositoryGetRefreshTokenByHash"
+	TokenRepositoryRotateRefreshTokenMethod    = "TokenRepositoryRotateRefreshToken"
+	TokenRepositoryRevokeRefreshTokenMethod    = "TokenRepositoryRevokeRefreshToken"
+)
+
+type TokenRepository interface {
+	CreateRefreshToken(token *internaldto.RefreshToken) error
+	GetRefreshTokenByHash(tokenHash string) (*internaldto.RefreshToken, error)
+	RotateRefreshToken(oldTokenID uint, newToken *internaldto.RefreshToken) error
+	RevokeRefreshToken(tokenHash string) error
+}
+
+type tokenRepository struct {
+	_                 struct{}
+	repositoryContext Context
+	db                *gorm.DB
+}
+
+// CreateTokenRepository creates a new instance of TokenRepository
+func CreateTokenRepository(requestID string) TokenRepository {
+	return &tokenRepository{
+		repositoryContext: CreateRepositoryContext(requestID),
+		db:                dbconfig.GetDBConnection(),
+	}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *internaldto.RefreshToken) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(TokenRepositoryCreateRefreshTokenMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(TokenRepositoryCreateRefreshTokenMethod), commonLogFields...)
+
+	err := r.db.Create(token).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("RefreshToken"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(tokenHash string) (*internaldto.RefreshToken, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(TokenRepositoryGetRefreshTokenByHashMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(TokenRepositoryGetRefreshTokenByHashMethod), commonLogFields...)
+
+	var token internaldto.RefreshToken
+	err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("RefreshToken"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateRefreshToken stores the new token and revokes the old one in a single transaction.
+// The old token is only revoked if it is still active, so a concurrent rotation of the
+// same token fails with gorm.ErrRecordNotFound.
+func (r *tokenRepository) RotateRefreshToken(oldTokenID uint, newToken *internaldto.RefreshToken) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(TokenRepositoryRotateRefreshTokenMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(TokenRepositoryRotateRefreshTokenMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newToken).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenInserting("RefreshToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		result := tx.Model(&internaldto.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldTokenID).
+			Updates(map[string]any{
+				"revoked_at":     time.Now(),
+				"replaced_by_id": newToken.ID,
+			})
+		if result.Error != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("RefreshToken"), log.TraceError(commonLogFields, result.Error)...)
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(TokenRepositoryRotateRefreshTokenMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+func (r *tokenRepository) RevokeRefreshToken(tokenHash string) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(TokenRepositoryRevokeRefreshTokenMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(TokenRepositoryRevokeRefreshTokenMethod), commonLogFields...)
+
+	err := r.db.Model(&internaldto.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", tokenHash).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("RefreshToken"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
@@ -1,6 +1,12 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	appdto "github.com/chazool/serendib_asia_service/app/routes/dto"
 	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
 	"github.com/chazool/serendib_asia_service/pkg/config/dbconfig"
@@ -12,14 +18,33 @@ import (
 
 const (
 	// User repository methods
-	UserRepositoryRegisterMethod         = "UserRepositoryRegister"
-	UserRepositoryLoginMethod            = "UserRepositoryLogin"
-	UserRepositoryGetProfileMethod       = "UserRepositoryGetProfile"
-	UserRepositoryUpdateProfileMethod    = "UserRepositoryUpdateProfile"
-	UserRepositoryUpdatePasswordMethod   = "UserRepositoryUpdatePassword"
-	UserRepositoryCheckEmailExistsMethod = "UserRepositoryCheckEmailExists"
+	UserRepositoryRegisterMethod          = "UserRepositoryRegister"
+	UserRepositoryLoginMethod             = "UserRepositoryLogin"
+	UserRepositoryGetProfileMethod        = "UserRepositoryGetProfile"
+	UserRepositoryUpdateProfileMethod     = "UserRepositoryUpdateProfile"
+	UserRepositoryUpdatePasswordMethod    = "UserRepositoryUpdatePassword"
+	UserRepositoryCheckEmailExistsMethod  = "UserRepositoryCheckEmailExists"
+	UserRepositoryGetUserByIDMethod       = "UserRepositoryGetUserByID"
+	UserRepositoryGetUserByEmailMethod    = "UserRepositoryGetUserByEmail"
+	UserRepositorySetSuspendedAtMethod    = "UserRepositorySetSuspendedAt"
+	UserRepositoryResetPasswordMethod     = "UserRepositoryResetPassword"
+	UserRepositoryMarkEmailVerifiedMethod = "UserRepositoryMarkEmailVerified"
+	UserRepositoryDeleteAccountMethod     = "UserRepositoryDeleteAccount"
+)
+
+// anonymised profile of a deleted account
+const (
+	deletedUserName        = "Deleted user"
+	deletedUserEmailFormat = "deleted-user-%d@deleted.invalid"
 )
 
+// dummyPasswordHash is compared against when no user matches a login, so unknown
+// emails take as long to reject as wrong passwords
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
 type UserRepository interface {
 	Register(request *appdto.UserRegisterRequest) (*appdto.UserProfileResponse, error)
 	Login(email, password string) (*appdto.UserProfileResponse, error)
@@ -27,6 +52,12 @@ type UserRepository interface {
 	UpdateProfile(userID uint, request *appdto.UserUpdateProfileRequest) (*appdto.UserProfileResponse, error)
 	UpdatePassword(userID uint, currentPassword, newPassword string) error
 	CheckEmailExists(email string) (bool, error)
+	GetUserByID(userID uint) (*internaldto.User, error)
+	GetUserByEmail(email string) (*internaldto.User, error)
+	SetSuspendedAt(userID uint, suspendedAt *time.Time) error
+	ResetPassword(userID uint, newPassword string) error
+	MarkEmailVerified(userID uint) error
+	DeleteAccount(userID uint, password string, deleteListings bool) error
 }
 
 type userRepository struct {
@@ -60,6 +91,7 @@ func (r *userRepository) Register(request *appdto.UserRegisterRequest) (*appdto.
 		FullName:     request.Name,
 		Email:        request.Email,
 		PasswordHash: string(hashedPassword),
+		PhoneNumber:  request.PhoneNumber,
 	}
 
 	err = r.db.Create(newUser).Error
@@ -89,6 +121,9 @@ func (r *userRepository) Login(email, password string) (*appdto.UserProfileRespo
 	var user internaldto.User
 	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+		}
 		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("User"), log.TraceError(commonLogFields, err)...)
 		return nil, err
 	}
@@ -102,12 +137,14 @@ func (r *userRepository) Login(email, password string) (*appdto.UserProfileRespo
 
 	// Convert to response DTO
 	response := &appdto.UserProfileResponse{
-		ID:           user.ID,
-		FullName:     user.FullName,
-		Email:        user.Email,
-		PhoneNumber:  user.PhoneNumber,
-		ProfileImage: user.ProfileImage,
-		CreatedAt:    user.CreatedAt,
+		ID:              user.ID,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		PhoneNumber:     user.PhoneNumber,
+		ProfileImage:    user.ProfileImage,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneVerified:   user.PhoneVerifiedAt != nil,
+		CreatedAt:       user.CreatedAt,
 	}
 
 	return response, nil
@@ -127,12 +164,14 @@ func (r *userRepository) GetProfile(userID uint) (*appdto.UserProfileResponse, e
 
 	// Convert to response DTO
 	response := &appdto.UserProfileResponse{
-		ID:           user.ID,
-		FullName:     user.FullName,
-		Email:        user.Email,
-		PhoneNumber:  user.PhoneNumber,
-		ProfileImage: user.ProfileImage,
-		CreatedAt:    user.CreatedAt,
+		ID:              user.ID,
+		FullName:        user.FullName,
+		Email:           user.Email,
+		PhoneNumber:     user.PhoneNumber,
+		ProfileImage:    user.ProfileImage,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		PhoneVerified:   user.PhoneVerifiedAt != nil,
+		CreatedAt:       user.CreatedAt,
 	}
 
 	return response, nil
@@ -143,10 +182,12 @@ func (r *userRepository) UpdateProfile(userID uint, request *appdto.UserUpdatePr
 	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositoryUpdateProfileMethod), commonLogFields...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositoryUpdateProfileMethod), commonLogFields...)
 
+	// A new phone number has to be verified again
 	updates := map[string]interface{}{
-		"full_name":     request.FullName,
-		"phone_number":  request.PhoneNumber,
-		"profile_image": request.ProfileImage,
+		"full_name":         request.FullName,
+		"phone_number":      request.PhoneNumber,
+		"profile_image":     request.ProfileImage,
+		"phone_verified_at": gorm.Expr("CASE WHEN phone_number = ? THEN phone_verified_at END", request.PhoneNumber),
 	}
 
 	err := r.db.Model(&internaldto.User{}).
@@ -213,3 +254,255 @@ func (r *userRepository) CheckEmailExists(email string) (bool, error) {
 
 	return count > 0, nil
 }
+
+func (r *userRepository) GetUserByID(userID uint) (*internaldto.User, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositoryGetUserByIDMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositoryGetUserByIDMethod), commonLogFields...)
+
+	var user internaldto.User
+	err := r.db.First(&user, userID).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("User"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) GetUserByEmail(email string) (*internaldto.User, error) {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositoryGetUserByEmailMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositoryGetUserByEmailMethod), commonLogFields...)
+
+	var user internaldto.User
+	err := r.db.Where("email = ?", email).First(&user).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("User"), log.TraceError(commonLogFields, err)...)
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SetSuspendedAt suspends the user at the given time, or lifts the suspension when nil
+func (r *userRepository) SetSuspendedAt(userID uint, suspendedAt *time.Time) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositorySetSuspendedAtMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositorySetSuspendedAtMethod), commonLogFields...)
+
+	result := r.db.Model(&internaldto.User{}).
+		Where("id = ?", userID).
+		Update("suspended_at", suspendedAt)
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("User"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ResetPassword sets a new password without the current one and records when it
+// changed, so access tokens issued before the reset are no longer accepted
+func (r *userRepository) ResetPassword(userID uint, newPassword string) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositoryResetPasswordMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositoryResetPasswordMethod), commonLogFields...)
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhen("hashing new password"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	result := r.db.Model(&internaldto.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"password_hash":       string(hashedPassword),
+			"password_changed_at": time.Now(),
+		})
+	if result.Error != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserPassword"), log.TraceError(commonLogFields, result.Error)...)
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// MarkEmailVerified records that the user confirmed their email address. Verifying
+// an already verified address keeps the original timestamp.
+func (r *userRepository) MarkEmailVerified(userID uint) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositoryMarkEmailVerifiedMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositoryMarkEmailVerifiedMethod), commonLogFields...)
+
+	err := r.db.Model(&internaldto.User{}).
+		Where("id = ? AND email_verified_at IS NULL", userID).
+		Update("email_verified_at", time.Now()).Error
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("User"), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteAccount verifies the password and erases the personal data of a user in one
+// transaction. The user row is kept, anonymised, so listings and audit rows keep a
+// valid owner. Listings are removed with their images when deleteListings is set,
+// otherwise they are unpublished (soft deleted).
+func (r *userRepository) DeleteAccount(userID uint, password string, deleteListings bool) error {
+	commonLogFields := log.CommonLogField(r.repositoryContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserRepositoryDeleteAccountMethod), log.TraceMethodInputs(commonLogFields, userID, deleteListings)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserRepositoryDeleteAccountMethod), commonLogFields...)
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user internaldto.User
+		if err := tx.Where("id = ? AND deleted_at IS NULL", userID).First(&user).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenSelecting("User"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhen("verifying password"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		// Organization listings stay with the organization
+		if deleteListings {
+			if err := deleteUserListings(tx, userID); err != nil {
+				log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("Property"), log.TraceError(commonLogFields, err)...)
+				return err
+			}
+		} else if err := tx.Where("user_id = ? AND organization_id IS NULL", userID).Delete(&appdto.Property{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("Property"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		// Favorites, roles and one-time tokens have no value once the account is gone
+		if err := tx.Table("favourites").Where("user_id = ?", userID).Delete(&struct{}{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("Favorite"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&internaldto.UserRole{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("UserRole"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&internaldto.OrganizationMember{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("OrganizationMember"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&internaldto.UserActionToken{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("UserActionToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&internaldto.PhoneVerification{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("PhoneVerification"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Where("email = ?", strings.ToLower(user.Email)).Delete(&internaldto.LoginAttempt{}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenDeleting("LoginAttempt"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&internaldto.UserSession{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("UserSession"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Model(&internaldto.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("RefreshToken"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+		if err := tx.Model(&internaldto.APIKey{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("APIKey"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		// The placeholder email keeps the unique constraint and frees the real address
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"full_name":         deletedUserName,
+			"email":             fmt.Sprintf(deletedUserEmailFormat, userID),
+			"password_hash":     "",
+			"phone_number":      "",
+			"profile_image":     "",
+			"email_verified_at": nil,
+			"phone_verified_at": nil,
+			"deleted_at":        now,
+		}).Error; err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhenUpdating("User"), log.TraceError(commonLogFields, err)...)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserRepositoryDeleteAccountMethod), log.TraceError(commonLogFields, err)...)
+		return err
+	}
+
+	return nil
+}
+
+// deleteUserListings permanently removes the listings of a user, including those
+// already unpublished, together with their images, relations and other users' favorites
+func deleteUserListings(tx *gorm.DB, userID uint) error {
+	var propertyIDs []uint
+	if err := tx.Unscoped().Model(&appdto.Property{}).Where("user_id = ? AND organization_id IS NULL", userID).Pluck("id", &propertyIDs).Error; err != nil {
+		return err
+	}
+	if len(propertyIDs) == 0 {
+		return nil
+	}
+
+	return deleteListingRows(tx, propertyIDs)
+}
+
+// deleteListingRows permanently removes the given listings and every row kept about them
+func deleteListingRows(tx *gorm.DB, propertyIDs []uint) error {
+	if err := tx.Table("favourites").Where("property_id IN ?", propertyIDs).Delete(&struct{}{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("property_id IN ?", propertyIDs).Delete(&appdto.PropertyAmenity{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("property_id IN ?", propertyIDs).Delete(&appdto.PropertyUtility{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("property_id IN ?", propertyIDs).Delete(&appdto.PropertyImage{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("property_id IN ?", propertyIDs).Delete(&internaldto.PropertyStatusChange{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("property_id IN ?", propertyIDs).Delete(&internaldto.PropertyRenewal{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("property_id IN ?", propertyIDs).Delete(&internaldto.PropertyModerationDecision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("property_id IN ?", propertyIDs).Delete(&internaldto.PropertyScreening{}).Error; err != nil {
+		return err
+	}
+	// A listing may be flagged either as the duplicate or as the listing it duplicates
+	if err := tx.Where("property_id IN ? OR duplicate_of_id IN ?", propertyIDs, propertyIDs).Delete(&internaldto.PropertyDuplicate{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("property_id IN ?", propertyIDs).Delete(&internaldto.PropertyRevision{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Where("id IN ?", propertyIDs).Delete(&appdto.Property{}).Error
+}
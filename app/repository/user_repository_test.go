@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"slices"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestDeleteListingRows(t *testing.T) {
+	db := dryRunDB(t)
+	var statements []string
+	err := db.Callback().Delete().After("gorm:delete").Register("test:record", func(tx *gorm.DB) {
+		statements = append(statements, tx.Statement.SQL.String())
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if err = deleteListingRows(db, []uint{4, 9}); err != nil {
+		t.Fatalf("deleteListingRows() error = %v", err)
+	}
+
+	// The listings go last, after every row that refers to them
+	want := []string{
+		`DELETE FROM "favourites" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_amenities" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_utilities" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_images" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_status_changes" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_renewals" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_moderation_decisions" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_screenings" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "property_duplicates" WHERE property_id IN ($1,$2) OR duplicate_of_id IN ($3,$4)`,
+		`DELETE FROM "property_revisions" WHERE property_id IN ($1,$2)`,
+		`DELETE FROM "properties" WHERE id IN ($1,$2)`,
+	}
+	for _, statement := range want {
+		if !slices.Contains(statements, statement) {
+			t.Errorf("deleteListingRows() did not run %s\nran %v", statement, statements)
+		}
+	}
+	if last := statements[len(statements)-1]; last != want[len(want)-1] {
+		t.Errorf("deleteListingRows() ran %s last, want the listings deleted last", last)
+	}
+}
@@ -2,9 +2,15 @@ package routes
 
 import (
 	"github.com/chazool/serendib_asia_service/app/routes/handler"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/web/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
+	"go.uber.org/zap"
 )
 
 // APIRoutes sets up the API routes for the application.
@@ -18,20 +24,42 @@ func APIRoutes(app *fiber.App) {
 
 	route := app.Group("/api/v1")
 
+	// authenticated routes resolve the bearer token to a user principal. A misconfigured
+	// verifier stops the service rather than accepting another kind of token.
+	verifier, err := auth.NewTokenVerifier()
+	if err != nil {
+		log.Logger.Fatal("token verifier is not configured", zap.Error(err))
+	}
+	requireAuth := middleware.AuthMiddleware(verifier, services.ResolvePrincipal)
+	// public routes that also recognise a signed in user
+	optionalAuth := middleware.OptionalAuthMiddleware(requireAuth)
+	requireVerifiedEmail := middleware.RequireVerifiedEmail(config.GetConfig().AuthConfig.RequireVerifiedEmail)
+	// partner integrations may call these routes with a scoped api key instead of a bearer token
+	propertiesWrite := middleware.APIKeyMiddleware(services.ResolveAPIKey, auth.ScopePropertiesWrite, requireAuth)
+	imagesWrite := middleware.APIKeyMiddleware(services.ResolveAPIKey, auth.ScopeImagesWrite, requireAuth)
+
 	// property related endpoints
 	property := route.Group("/properties")
-	property.Post("/", handler.HandleCreateProperty)
-	property.Get("/:id", handler.HandleGetProperty)
-	property.Put("/:id", handler.HandleUpdateProperty)
-	property.Delete("/:id", handler.HandleDeleteProperty)
+	property.Post("/", propertiesWrite, requireVerifiedEmail, handler.HandleCreateProperty)
+	property.Get("/map", handler.HandlePropertyMap)
+	property.Get("/mine", requireAuth, handler.HandleListMyProperties)
+	property.Get("/:id", optionalAuth, handler.HandleGetProperty)
+	property.Put("/:id", propertiesWrite, handler.HandleUpdateProperty)
+	property.Delete("/:id", propertiesWrite, handler.HandleDeleteProperty)
+	property.Put("/:id/status", propertiesWrite, handler.HandleChangePropertyStatus)
+	property.Get("/:id/status/history", requireAuth, handler.HandlePropertyStatusHistory)
+	property.Post("/:id/renew", propertiesWrite, handler.HandleRenewProperty)
+	property.Get("/:id/revisions", requireAuth, handler.HandleListPropertyRevisions)
+	property.Get("/:id/revisions/diff", requireAuth, handler.HandleDiffPropertyRevisions)
+	property.Post("/:id/revisions/:version/restore", propertiesWrite, handler.HandleRestorePropertyRevision)
 	property.Get("/", handler.HandleListProperties)
 	property.Get("/user/:id", handler.HandleListPropertiesByUser)
 
 	// property image routes
-	property.Post("/:propertyId/images", handler.HandleUploadImage)
-	property.Delete("/images/:imageId", handler.HandleDeleteImage)
-	property.Put("/images/:imageId/primary", handler.HandleSetPrimaryImage)
-	property.Get("/:propertyId/images", handler.HandleListImages)
+	property.Post("/:propertyId/images", imagesWrite, handler.HandleUploadImage)
+	property.Delete("/images/:imageId", imagesWrite, handler.HandleDeleteImage)
+	property.Put("/images/:imageId/primary", imagesWrite, handler.HandleSetPrimaryImage)
+	property.Get("/:propertyId/images", optionalAuth, handler.HandleListImages)
 
 	// lookup tables endpoints
 	lookup := route.Group("/lookups")
@@ -49,15 +77,45 @@ func APIRoutes(app *fiber.App) {
 	user.Post("/register", userHandler.Register)
 	// login user
 	user.Post("/login", userHandler.Login)
+	// rotate refresh token
+	user.Post("/token/refresh", userHandler.RefreshToken)
+	// revoke refresh token
+	user.Post("/logout", userHandler.Logout)
+	// lift login lockout
+	user.Post("/unlock", userHandler.UnlockAccount)
+	// email password reset link
+	user.Post("/password/forgot", userHandler.ForgotPassword)
+	// reset password with reset token
+	user.Post("/password/reset", userHandler.ResetPassword)
+	// confirm email address
+	user.Post("/email/verify", userHandler.VerifyEmail)
+	// resend email verification link
+	user.Post("/email/verify/resend", requireAuth, userHandler.ResendVerification)
+	// text phone verification code
+	user.Post("/phone/verify/send", requireAuth, userHandler.SendPhoneVerification)
+	// confirm phone number
+	user.Post("/phone/verify", requireAuth, userHandler.VerifyPhone)
 	// get user profile
-	user.Get("/profile", userHandler.GetProfile)
+	user.Get("/profile", requireAuth, userHandler.GetProfile)
 	// update user profile
-	user.Put("/profile", userHandler.UpdateProfile)
+	user.Put("/profile", requireAuth, userHandler.UpdateProfile)
 	// update user password
-	user.Put("/password", userHandler.UpdatePassword)
+	user.Put("/password", requireAuth, userHandler.UpdatePassword)
+	// list signed in sessions
+	user.Get("/sessions", requireAuth, userHandler.ListSessions)
+	// log out everywhere
+	user.Delete("/sessions", requireAuth, userHandler.RevokeAllSessions)
+	// revoke a single session
+	user.Delete("/sessions/:id", requireAuth, userHandler.RevokeSession)
+	// download personal data export
+	user.Get("/me/export", requireAuth, userHandler.ExportData)
+	// delete own account
+	user.Delete("/me", requireAuth, userHandler.DeleteAccount)
+	// public seller profile
+	user.Get("/:id/public", userHandler.GetPublicProfile)
 
 	// property favorites endpoints
-	favorites := route.Group("/favorites")
+	favorites := route.Group("/favorites", requireAuth)
 	favoriteHandler := handler.CreateFavoriteHandler("")
 	// add to favorites
 	favorites.Post("", favoriteHandler.AddFavorite)
@@ -65,4 +123,78 @@ func APIRoutes(app *fiber.App) {
 	favorites.Delete("/:id", favoriteHandler.RemoveFavorite)
 	// list user favorites
 	favorites.Get("", favoriteHandler.ListFavorites)
+
+	// organization endpoints, members manage the shared listings according to their role
+	organizations := route.Group("/organizations")
+	// create organization
+	organizations.Post("", requireAuth, handler.HandleCreateOrganization)
+	// list my organizations
+	organizations.Get("", requireAuth, handler.HandleListMyOrganizations)
+	// accept invitation
+	organizations.Post("/invitations/accept", requireAuth, handler.HandleAcceptOrganizationInvitation)
+	// public agency profile
+	organizations.Get("/:id/public", handler.HandleGetOrganizationProfile)
+	// update organization
+	organizations.Put("/:id", requireAuth, handler.HandleUpdateOrganization)
+	// list members
+	organizations.Get("/:id/members", requireAuth, handler.HandleListOrganizationMembers)
+	// change member role
+	organizations.Put("/:id/members/:userId", requireAuth, handler.HandleUpdateOrganizationMember)
+	// remove member or leave
+	organizations.Delete("/:id/members/:userId", requireAuth, handler.HandleRemoveOrganizationMember)
+	// invite member
+	organizations.Post("/:id/invitations", requireAuth, handler.HandleInviteOrganizationMember)
+	// list pending invitations
+	organizations.Get("/:id/invitations", requireAuth, handler.HandleListOrganizationInvitations)
+	// revoke invitation
+	organizations.Delete("/:id/invitations/:invitationId", requireAuth, handler.HandleRevokeOrganizationInvitation)
+
+	// api key endpoints, keys can only be managed with a bearer token
+	apiKeys := route.Group("/api-keys", requireAuth)
+	// create api key
+	apiKeys.Post("", handler.HandleCreateAPIKey)
+	// list api keys
+	apiKeys.Get("", handler.HandleListAPIKeys)
+	// revoke api key
+	apiKeys.Delete("/:id", handler.HandleRevokeAPIKey)
+
+	// admin endpoints, every group declares the permission it requires
+	admin := route.Group("/admin", requireAuth)
+	adminRoles := admin.Group("/roles", middleware.RequirePermission(auth.PermissionManageUsers))
+	// list roles
+	adminRoles.Get("", handler.HandleListRoles)
+
+	adminUsers := admin.Group("/users", middleware.RequirePermission(auth.PermissionManageUsers))
+	// assign roles to a user
+	adminUsers.Put("/:id/roles", handler.HandleAssignRoles)
+	// suspend user
+	adminUsers.Post("/:id/suspend", handler.HandleSuspendUser)
+	// unsuspend user
+	adminUsers.Delete("/:id/suspend", handler.HandleUnsuspendUser)
+
+	adminLookups := admin.Group("/lookups", middleware.RequirePermission(auth.PermissionManageLookups))
+	// create lookup value
+	adminLookups.Post("/:type", handler.HandleCreateLookup)
+	// update lookup value
+	adminLookups.Put("/:type/:id", handler.HandleUpdateLookup)
+	// delete lookup value
+	adminLookups.Delete("/:type/:id", handler.HandleDeleteLookup)
+
+	adminModeration := admin.Group("/moderation", middleware.RequirePermission(auth.PermissionModerateProperties))
+	// list listings waiting for review
+	adminModeration.Get("/properties", handler.HandleListModerationQueue)
+	// approve a listing
+	adminModeration.Post("/properties/:id/approve", handler.HandleApproveProperty)
+	// reject a listing
+	adminModeration.Post("/properties/:id/reject", handler.HandleRejectProperty)
+	// moderation history of a listing
+	adminModeration.Get("/properties/:id/history", handler.HandlePropertyModerationHistory)
+	// content screenings that flagged a listing
+	adminModeration.Get("/properties/:id/screenings", handler.HandlePropertyScreeningHistory)
+	// list listings flagged as duplicates
+	adminModeration.Get("/duplicates", handler.HandleListDuplicates)
+	// keep one listing of a duplicate pair and archive the other
+	adminModeration.Post("/duplicates/:id/merge", handler.HandleMergeDuplicate)
+	// dismiss a duplicate flag
+	adminModeration.Post("/duplicates/:id/dismiss", handler.HandleDismissDuplicate)
 }
@@ -0,0 +1,11 @@
+package dto
+
+// AssignRolesRequest represents the request to replace the roles of a user
+type AssignRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,min=1,dive,required"`
+}
+
+// LookupRequest represents the request to create or rename a lookup
+type LookupRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // FavoriteRequest represents the request to add a property to favorites
 type FavoriteRequest struct {
 	PropertyID uint `json:"property_id" validate:"required"` // INTEGER REFERENCES properties(id)
@@ -28,6 +30,7 @@ type PropertyDetail struct {
 	City        string  `json:"city"`        // VARCHAR(50)
 	Address     string  `json:"address"`     // TEXT
 	URL         string  `json:"url"`         // TEXT (from property_images)
+	Status      string  `json:"status"`      // VARCHAR(20)
 }
 
 // FavoriteListResponse represents a list of favorite properties
@@ -35,3 +38,9 @@ type FavoriteListResponse struct {
 	Items []FavoriteResponse `json:"items"`
 	Total int64              `json:"total"`
 }
+
+// FavoriteExport represents a saved property in a personal data export
+type FavoriteExport struct {
+	PropertyID uint      `json:"property_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+)
+
+// OrganizationRequest represents the request to create or update an organization
+type OrganizationRequest struct {
+	Name        string `json:"name" validate:"required,max=150,singleLine"`
+	Description string `json:"description"`
+	Email       string `json:"email" validate:"omitempty,email,max=100"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,max=20"`
+	Website     string `json:"website" validate:"omitempty,url"`
+	LogoURL     string `json:"logo_url" validate:"omitempty,url"`
+	City        string `json:"city" validate:"max=50"`
+}
+
+// OrganizationResponse represents an organization together with the role of the current user in it
+type OrganizationResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Email       string    `json:"email"`
+	PhoneNumber string    `json:"phone_number"`
+	Website     string    `json:"website"`
+	LogoURL     string    `json:"logo_url"`
+	City        string    `json:"city"`
+	Role        string    `json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OrganizationMemberResponse represents a member of an organization
+type OrganizationMemberResponse struct {
+	UserID    uint      `json:"user_id"`
+	FullName  string    `json:"full_name"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"joined_at"`
+}
+
+// UpdateMemberRoleRequest represents the request to change the role of a member
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner manager agent"`
+}
+
+// InviteMemberRequest represents the request to invite someone to an organization
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email,max=100"`
+	Role  string `json:"role" validate:"required,oneof=owner manager agent"`
+}
+
+// InvitationResponse represents a pending invitation
+type InvitationResponse struct {
+	ID        uint      `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	InvitedBy uint      `json:"invited_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AcceptInvitationRequest represents the request to accept an emailed invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// OrganizationProfileResponse is the public agency page. Like PublicProfileResponse it
+// never lists the members or their private details.
+type OrganizationProfileResponse struct {
+	ID                 uint            `json:"id"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description"`
+	Email              string          `json:"email,omitempty"`
+	PhoneNumber        string          `json:"phone_number,omitempty"`
+	Website            string          `json:"website,omitempty"`
+	LogoURL            string          `json:"logo_url,omitempty"`
+	City               string          `json:"city,omitempty"`
+	MemberSince        time.Time       `json:"member_since"`
+	MemberCount        int64           `json:"member_count"`
+	ActiveListingCount int64           `json:"active_listing_count"`
+	Listings           []Property      `json:"listings"`
+	Pagination         pagination.Page `json:"pagination"`
+}
@@ -9,6 +9,7 @@ type Property struct {
 	*Base
 	ID                uint              `gorm:"not null; column:id; primaryKey; autoIncrement"`
 	UserID            uint              `gorm:"not null; column:user_id; index:idx_properties_on_user_id, type:btree"`
+	OrganizationID    *uint             `gorm:"column:organization_id; index:idx_properties_on_organization_id, type:btree"`
 	Title             string            `gorm:"not null; column:title; type:varchar(150)"`
 	Description       string            `gorm:"column:description; type:text"`
 	PurposeID         uint              `gorm:"not null; column:purpose_id; index:idx_properties_on_purpose_id, type:btree"`
@@ -22,18 +23,26 @@ type Property struct {
 	City              string            `gorm:"not null; column:city; type:varchar(50)"`
 	Address           string            `gorm:"not null; column:address; type:text"`
 	PostalCode        string            `gorm:"column:postal_code; type:varchar(10)"`
-	Latitude          float64           `gorm:"column:latitude"`
-	Longitude         float64           `gorm:"column:longitude"`
+	Latitude          float64           `gorm:"column:latitude; index:idx_properties_on_latitude_longitude, type:btree"`
+	Longitude         float64           `gorm:"column:longitude; index:idx_properties_on_latitude_longitude, type:btree"`
 	Price             float64           `gorm:"not null; column:price"`
 	PriceUnit         string            `gorm:"not null; column:price_unit; type:varchar(20)"`
 	IsNegotiable      bool              `gorm:"column:is_negotiable; default:false"`
 	RentalPeriod      string            `gorm:"column:rental_period; type:varchar(20)"`
 	IsRefundable      bool              `gorm:"column:is_refundable; default:false"`
 	PricingType       string            `gorm:"not null; column:pricing_type; type:varchar(10)"`
+	Status            string            `gorm:"not null; column:status; type:varchar(20); default:published; index:idx_properties_on_status, type:btree"`
+	StatusChangedAt   *time.Time        `gorm:"column:status_changed_at"`
+	PublishedAt       *time.Time        `gorm:"column:published_at"`
+	ExpiresAt         *time.Time        `gorm:"column:expires_at; index:idx_properties_on_expires_at, type:btree"`
+	ExpiryRemindedAt  *time.Time        `gorm:"column:expiry_reminded_at"`
 	CreatedAt         time.Time         `gorm:"not null; column:created_at; default:CURRENT_TIMESTAMP"`
 	PropertyAmenities []PropertyAmenity `gorm:"foreignKey:PropertyID"`
 	PropertyUtilities []PropertyUtility `gorm:"foreignKey:PropertyID"`
 	PropertyImages    []PropertyImage   `gorm:"foreignKey:PropertyID"`
+	SearchRank        float64           `gorm:"->; -:migration; column:search_rank" json:",omitempty"`
+	SearchSnippet     string            `gorm:"->; -:migration; column:search_snippet" json:",omitempty"`
+	DistanceKm        *float64          `gorm:"->; -:migration; column:distance_km" json:",omitempty"`
 }
 
 // TableName specifies the table name for PropertyAmenity
@@ -106,39 +115,254 @@ func (PropertyImage) TableName() string {
 	return "property_images"
 }
 
-// PropertyRequest represents the request for creating/updating a property
+// PropertyRequest represents the request for creating/updating a property.
+// Status is only read on create: a new listing is published unless saved as a draft.
+// ExpiresAt is set by the service from the listing period of the pricing type, and
+// RevisionNote is recorded against the revision an update stores.
 type PropertyRequest struct {
-	UserID          uint     `json:"user_id" validate:"required"`
-	Title           string   `json:"title" validate:"required,max=150"`
+	UserID          uint       `json:"-" swaggerignore:"true"`
+	ExpiresAt       *time.Time `json:"-" swaggerignore:"true"`
+	RevisionNote    string     `json:"-" swaggerignore:"true"`
+	OrganizationID  *uint      `json:"organization_id"`
+	Title           string     `json:"title" validate:"required,max=150"`
+	Description     string     `json:"description"`
+	PurposeID       int        `json:"purpose_id" validate:"required"`
+	PropertyTypeID  int        `json:"property_type_id" validate:"required"`
+	FurnitureTypeID int        `json:"furniture_type_id"`
+	ConditionID     int        `json:"condition_id"`
+	Bedrooms        int        `json:"bedrooms"`
+	Bathrooms       int        `json:"bathrooms"`
+	Size            float64    `json:"size"`
+	SizeUnit        string     `json:"size_unit" validate:"max=20"`
+	City            string     `json:"city" validate:"required,max=50"`
+	Address         string     `json:"address" validate:"required"`
+	PostalCode      string     `json:"postal_code" validate:"max=10"`
+	Latitude        float64    `json:"latitude" validate:"min=-90,max=90"`
+	Longitude       float64    `json:"longitude" validate:"min=-180,max=180"`
+	Price           float64    `json:"price" validate:"required"`
+	PriceUnit       string     `json:"price_unit" validate:"required,max=20"`
+	IsNegotiable    bool       `json:"is_negotiable"`
+	RentalPeriod    string     `json:"rental_period" validate:"max=20"`
+	IsRefundable    bool       `json:"is_refundable"`
+	PricingType     string     `json:"pricing_type" validate:"required,oneof=sell rent stay"`
+	Status          string     `json:"status" validate:"omitempty,oneof=draft published"`
+	AmenityIDs      []int      `json:"amenity_ids"`
+	UtilityIDs      []int      `json:"utility_ids"`
+	Images          []string   `json:"images"`
+}
+
+// PropertyFilterRequest represents the query parameters for listing properties
+type PropertyFilterRequest struct {
+	PageRequest
+	Query           string   `query:"q" json:"q" validate:"max=100"`
+	PurposeID       uint     `query:"purpose_id" json:"purpose_id"`
+	PropertyTypeID  uint     `query:"property_type_id" json:"property_type_id"`
+	PricingType     string   `query:"pricing_type" json:"pricing_type" validate:"omitempty,oneof=sell rent stay"`
+	FurnitureTypeID uint     `query:"furniture_type_id" json:"furniture_type_id"`
+	ConditionID     uint     `query:"condition_id" json:"condition_id"`
+	City            string   `query:"city" json:"city" validate:"max=50"`
+	MinPrice        *float64 `query:"min_price" json:"min_price" validate:"omitempty,min=0"`
+	MaxPrice        *float64 `query:"max_price" json:"max_price" validate:"omitempty,min=0"`
+	MinBedrooms     int      `query:"min_bedrooms" json:"min_bedrooms" validate:"min=0"`
+	MinBathrooms    int      `query:"min_bathrooms" json:"min_bathrooms" validate:"min=0"`
+	MinSize         *float64 `query:"min_size" json:"min_size" validate:"omitempty,min=0"`
+	MaxSize         *float64 `query:"max_size" json:"max_size" validate:"omitempty,min=0"`
+	AmenityIDs      []uint   `query:"amenity_ids" json:"amenity_ids"`
+	UtilityIDs      []uint   `query:"utility_ids" json:"utility_ids"`
+	IsNegotiable    *bool    `query:"is_negotiable" json:"is_negotiable"`
+	Latitude        *float64 `query:"lat" json:"lat" validate:"omitempty,min=-90,max=90"`
+	Longitude       *float64 `query:"lng" json:"lng" validate:"omitempty,min=-180,max=180"`
+	RadiusKm        *float64 `query:"radius_km" json:"radius_km" validate:"omitempty,gt=0,max=500"`
+	MinLat          *float64 `query:"min_lat" json:"min_lat" validate:"omitempty,min=-90,max=90"`
+	MaxLat          *float64 `query:"max_lat" json:"max_lat" validate:"omitempty,min=-90,max=90"`
+	MinLng          *float64 `query:"min_lng" json:"min_lng" validate:"omitempty,min=-180,max=180"`
+	MaxLng          *float64 `query:"max_lng" json:"max_lng" validate:"omitempty,min=-180,max=180"`
+	Polygon         string   `query:"polygon" json:"polygon" validate:"max=4000"`
+	SortBy          string   `query:"sort_by" json:"sort_by" validate:"omitempty,oneof=price date size distance"`
+	SortOrder       string   `query:"sort_order" json:"sort_order" validate:"omitempty,oneof=asc desc"`
+}
+
+// MyPropertiesRequest represents the query parameters for listing the properties of the signed in user
+type MyPropertiesRequest struct {
+	PageRequest
+	Status string `query:"status" json:"status" validate:"omitempty,oneof=draft pending_review rejected published reserved sold rented expired archived"`
+}
+
+// PropertyStatusRequest represents a request to move a property to another status
+type PropertyStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=draft pending_review rejected published reserved sold rented expired archived"`
+	Note   string `json:"note" validate:"max=500"`
+}
+
+// PropertyApprovalRequest represents a moderator approving a listing under review
+type PropertyApprovalRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// PropertyRejectionRequest represents a moderator rejecting a listing under review.
+// The reason code and note are sent to the owner.
+type PropertyRejectionRequest struct {
+	ReasonCode string `json:"reason_code" validate:"required,oneof=scam spam duplicate prohibited_content wrong_category inaccurate_details poor_images other"`
+	Note       string `json:"note" validate:"required_if=ReasonCode other,max=500"`
+}
+
+// PropertyDuplicateResponse represents a pair of listings flagged as likely the same property
+type PropertyDuplicateResponse struct {
+	ID            uint       `json:"id"`
+	PropertyID    uint       `json:"property_id"`
+	DuplicateOfID uint       `json:"duplicate_of_id"`
+	Score         int        `json:"score"`
+	Reasons       []string   `json:"reasons"`
+	Status        string     `json:"status"`
+	ResolvedBy    *uint      `json:"resolved_by,omitempty"`
+	Note          string     `json:"note,omitempty"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// PropertyDuplicateMergeRequest represents a moderator merging a pair of duplicate listings.
+// The listing that is not kept is archived.
+type PropertyDuplicateMergeRequest struct {
+	KeepPropertyID uint   `json:"keep_property_id" validate:"required"`
+	Note           string `json:"note" validate:"max=500"`
+}
+
+// PropertyDuplicateDismissRequest represents a moderator dismissing a pair of listings flagged as duplicates
+type PropertyDuplicateDismissRequest struct {
+	Note string `json:"note" validate:"max=500"`
+}
+
+// PropertyScreeningResponse represents one content screening of a property that flagged its text
+type PropertyScreeningResponse struct {
+	Score     int              `json:"score"`
+	Matches   []ScreeningMatch `json:"matches"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// ScreeningMatch represents a screening rule that matched the text of a property
+type ScreeningMatch struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field"`
+	Excerpt string `json:"excerpt"`
+	Weight  int    `json:"weight"`
+}
+
+// PropertyModerationDecisionResponse represents one moderation decision on a property
+type PropertyModerationDecisionResponse struct {
+	Decision    string    `json:"decision"`
+	ReasonCode  string    `json:"reason_code,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	ModeratorID uint      `json:"moderator_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PropertyStatusChangeResponse represents one transition in the status history of a property
+type PropertyStatusChangeResponse struct {
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  uint      `json:"changed_by"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// PropertySnapshot represents the content of a property as stored in a revision: its fields
+// together with its amenity, utility and image sets
+type PropertySnapshot struct {
+	OrganizationID  *uint    `json:"organization_id"`
+	Title           string   `json:"title"`
 	Description     string   `json:"description"`
-	PurposeID       int      `json:"purpose_id" validate:"required"`
-	PropertyTypeID  int      `json:"property_type_id" validate:"required"`
-	FurnitureTypeID int      `json:"furniture_type_id"`
-	ConditionID     int      `json:"condition_id"`
+	PurposeID       uint     `json:"purpose_id"`
+	PropertyTypeID  uint     `json:"property_type_id"`
+	FurnitureTypeID uint     `json:"furniture_type_id"`
+	ConditionID     uint     `json:"condition_id"`
 	Bedrooms        int      `json:"bedrooms"`
 	Bathrooms       int      `json:"bathrooms"`
 	Size            float64  `json:"size"`
-	SizeUnit        string   `json:"size_unit" validate:"max=20"`
-	City            string   `json:"city" validate:"required,max=50"`
-	Address         string   `json:"address" validate:"required"`
-	PostalCode      string   `json:"postal_code" validate:"max=10"`
+	SizeUnit        string   `json:"size_unit"`
+	City            string   `json:"city"`
+	Address         string   `json:"address"`
+	PostalCode      string   `json:"postal_code"`
 	Latitude        float64  `json:"latitude"`
 	Longitude       float64  `json:"longitude"`
-	Price           float64  `json:"price" validate:"required"`
-	PriceUnit       string   `json:"price_unit" validate:"required,max=20"`
+	Price           float64  `json:"price"`
+	PriceUnit       string   `json:"price_unit"`
 	IsNegotiable    bool     `json:"is_negotiable"`
-	RentalPeriod    string   `json:"rental_period" validate:"max=20"`
+	RentalPeriod    string   `json:"rental_period"`
 	IsRefundable    bool     `json:"is_refundable"`
-	PricingType     string   `json:"pricing_type" validate:"required,oneof=sell rent stay"`
-	AmenityIDs      []int    `json:"amenity_ids"`
-	UtilityIDs      []int    `json:"utility_ids"`
+	PricingType     string   `json:"pricing_type"`
+	AmenityIDs      []uint   `json:"amenity_ids"`
+	UtilityIDs      []uint   `json:"utility_ids"`
 	Images          []string `json:"images"`
 }
 
+// PropertyRevisionResponse represents one stored revision of a property
+type PropertyRevisionResponse struct {
+	Version   int              `json:"version"`
+	ChangedBy uint             `json:"changed_by"`
+	Note      string           `json:"note,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+	Snapshot  PropertySnapshot `json:"snapshot"`
+}
+
+// PropertyRevisionDiffRequest represents the query parameters naming the two revisions to compare
+type PropertyRevisionDiffRequest struct {
+	From int `query:"from" json:"from" validate:"required,min=1"`
+	To   int `query:"to" json:"to" validate:"required,min=1"`
+}
+
+// PropertyRevisionDiffResponse represents the fields that differ between two revisions of a property
+type PropertyRevisionDiffResponse struct {
+	From    int                   `json:"from"`
+	To      int                   `json:"to"`
+	Changes []PropertyFieldChange `json:"changes"`
+}
+
+// PropertyFieldChange represents a field whose value differs between two revisions
+type PropertyFieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+// PropertyMapRequest represents the query parameters for the map view; the viewport is required
+type PropertyMapRequest struct {
+	PropertyFilterRequest
+	Zoom int `query:"zoom" json:"zoom" validate:"min=0,max=22"`
+}
+
+// PropertyCluster represents the listings of one map grid cell
+type PropertyCluster struct {
+	Count     int64   `json:"count"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	MinPrice  float64 `json:"min_price"`
+	MaxPrice  float64 `json:"max_price"`
+}
+
+// PropertyPin represents a single listing on the map
+type PropertyPin struct {
+	ID          uint    `json:"id"`
+	Title       string  `json:"title"`
+	Price       float64 `json:"price"`
+	PriceUnit   string  `json:"price_unit"`
+	PricingType string  `json:"pricing_type"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+}
+
+// PropertyMapResponse represents the map view: clusters below the listing zoom, listings from it
+type PropertyMapResponse struct {
+	Zoom      int               `json:"zoom"`
+	Clusters  []PropertyCluster `json:"clusters,omitempty"`
+	Listings  []PropertyPin     `json:"listings,omitempty"`
+	Truncated bool              `json:"truncated"`
+}
+
 // PropertyResponse represents the response for a property
 type PropertyResponse struct {
 	ID              uint      `json:"id"`
 	UserID          uint      `json:"user_id"`
+	OrganizationID  *uint     `json:"organization_id"`
 	Title           string    `json:"title"`
 	Description     string    `json:"description"`
 	PurposeID       int       `json:"purpose_id"`
@@ -160,6 +384,7 @@ type PropertyResponse struct {
 	RentalPeriod    string    `json:"rental_period"`
 	IsRefundable    bool      `json:"is_refundable"`
 	PricingType     string    `json:"pricing_type"`
+	Status          string    `json:"status"`
 	CreatedAt       time.Time `json:"created_at"`
 	Amenities       []int     `json:"amenities"`
 	Utilities       []int     `json:"utilities"`
@@ -80,6 +80,13 @@ type PaginationRequest struct {
 	Offset int `json:"offset" validate:"min=0"`
 }
 
+// PageRequest is a struct that represents the keyset pagination parameters shared by list endpoints.
+type PageRequest struct {
+	PageSize     int    `query:"page_size" json:"page_size" validate:"omitempty,min=1,max=50"`
+	Cursor       string `query:"cursor" json:"cursor" validate:"max=512"`
+	IncludeTotal bool   `query:"include_total" json:"include_total"`
+}
+
 // RunbookFilterRequest is a struct that represents a request for runbook filters.
 type RunbookFilterRequest struct {
 	PaginationRequest
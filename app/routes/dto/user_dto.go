@@ -2,6 +2,8 @@ package dto
 
 import (
 	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 )
 
 // UserRegisterRequest represents the request to register a new user
@@ -9,36 +11,75 @@ type UserRegisterRequest struct {
 	Email        string `json:"email" validate:"required,email,max=100"`
 	Password     string `json:"password" validate:"required,min=8"`
 	Name         string `json:"name" validate:"required,max=100"`
-	PhoneNumber  string `json:"phone_number" validate:"omitempty,max=15"`
+	PhoneNumber  string `json:"phone_number" validate:"omitempty,max=20"`
 	ProfileImage string `json:"profile_image"`
+	DeviceName   string `json:"device_name" validate:"omitempty,max=100"`
 }
 
 // UserLoginRequest represents the request to login a user
 type UserLoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+	DeviceName string `json:"device_name" validate:"omitempty,max=100"`
+}
+
+// ClientInfo describes the client a session is started from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// SessionResponse represents a signed in device of the user
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	DeviceName string    `json:"device_name"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Current    bool      `json:"current"`
 }
 
 // UserLoginResponse represents the response after successful login
 type UserLoginResponse struct {
-	User  UserProfileResponse `json:"user"`
-	Token string              `json:"token"`
+	User UserProfileResponse `json:"user"`
+	TokenResponse
+}
+
+// TokenResponse represents a freshly issued access and refresh token pair
+type TokenResponse struct {
+	Token                 string    `json:"token"`
+	TokenExpiresAt        time.Time `json:"token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// RefreshTokenRequest represents the request to rotate a refresh token
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogoutRequest represents the request to revoke a refresh token
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 // UserProfileResponse represents a user's profile information
 type UserProfileResponse struct {
-	ID           uint      `json:"id"`
-	FullName     string    `json:"full_name"`
-	Email        string    `json:"email"`
-	PhoneNumber  string    `json:"phone_number,omitempty"`
-	ProfileImage string    `json:"profile_image,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID              uint       `json:"id"`
+	FullName        string     `json:"full_name"`
+	Email           string     `json:"email"`
+	PhoneNumber     string     `json:"phone_number,omitempty"`
+	ProfileImage    string     `json:"profile_image,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	PhoneVerified   bool       `json:"phone_verified"`
+	CreatedAt       time.Time  `json:"created_at"`
 }
 
 // UserUpdateProfileRequest represents the request to update a user's profile
 type UserUpdateProfileRequest struct {
 	FullName     string `json:"full_name" validate:"required,max=100"`
-	PhoneNumber  string `json:"phone_number" validate:"omitempty,max=15"`
+	PhoneNumber  string `json:"phone_number" validate:"omitempty,max=20"`
 	ProfileImage string `json:"profile_image"`
 }
 
@@ -47,3 +88,63 @@ type UserUpdatePasswordRequest struct {
 	CurrentPassword string `json:"current_password" validate:"required"`
 	NewPassword     string `json:"new_password" validate:"required,min=8"`
 }
+
+// ForgotPasswordRequest represents the request to email a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents the request to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// VerifyEmailRequest represents the request to confirm an email address
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// UnlockAccountRequest represents the request to lift a login lockout
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// DeleteAccountRequest represents the request to delete the authenticated user's account
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+// UserDataExport is the personal data export of a user
+type UserDataExport struct {
+	ExportedAt time.Time            `json:"exported_at"`
+	Profile    *UserProfileResponse `json:"profile"`
+	Roles      []string             `json:"roles"`
+	Properties []Property           `json:"properties"`
+	Favorites  []FavoriteExport     `json:"favorites"`
+	Sessions   []SessionResponse    `json:"sessions"`
+}
+
+// VerifyPhoneRequest represents the request to confirm a phone number with an SMS code
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,numeric"`
+}
+
+// PublicProfileResponse is the public view of a seller. It must never carry the
+// email, phone number or any other private field of the user.
+type PublicProfileResponse struct {
+	ID                 uint               `json:"id"`
+	DisplayName        string             `json:"display_name"`
+	ProfileImage       string             `json:"profile_image,omitempty"`
+	MemberSince        time.Time          `json:"member_since"`
+	Badges             VerificationBadges `json:"badges"`
+	ActiveListingCount int64              `json:"active_listing_count"`
+	Listings           []Property         `json:"listings"`
+	Pagination         pagination.Page    `json:"pagination"`
+}
+
+// VerificationBadges tells which contact details of a seller have been verified
+type VerificationBadges struct {
+	EmailVerified bool `json:"email_verified"`
+	PhoneVerified bool `json:"phone_verified"`
+}
@@ -0,0 +1,375 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Admin handler methods
+	HandleListRolesMethod     = "HandleListRoles"
+	HandleAssignRolesMethod   = "HandleAssignRoles"
+	HandleSuspendUserMethod   = "HandleSuspendUser"
+	HandleUnsuspendUserMethod = "HandleUnsuspendUser"
+	HandleCreateLookupMethod  = "HandleCreateLookup"
+	HandleUpdateLookupMethod  = "HandleUpdateLookup"
+	HandleDeleteLookupMethod  = "HandleDeleteLookup"
+
+	lookupTypeParam = "type"
+)
+
+// HandleListRoles handles listing all roles
+// @Summary List roles
+// @Description Lists all roles with their permissions and listing limits
+// @Tags admin
+// @Produce json
+// @Success 200 {object} []internaldto.Role
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/roles [get]
+func HandleListRoles(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListRolesMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListRolesMethod), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		response     []internaldto.Role
+		adminService = services.CreateAdminService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		response, errorResult = adminService.ListRoles(principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListRolesMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleAssignRoles handles replacing the roles of a user
+// @Summary Assign roles to a user
+// @Description Replaces the roles of a user with the given roles
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param roles body dto.AssignRolesRequest true "Role names"
+// @Success 200 {object} []internaldto.Role
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/users/{id}/roles [put]
+func HandleAssignRoles(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleAssignRolesMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleAssignRolesMethod), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		request      dto.AssignRolesRequest
+		response     []internaldto.Role
+		userID       uint
+		adminService = services.CreateAdminService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		userID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.AssignRolesRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = adminService.AssignRoles(principal, userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleAssignRolesMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleSuspendUser handles suspending a user
+// @Summary Suspend a user
+// @Description Suspends a user and revokes all of their refresh tokens
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/users/{id}/suspend [post]
+func HandleSuspendUser(ctx *fiber.Ctx) error {
+	return handleUserSuspension(ctx, HandleSuspendUserMethod, true)
+}
+
+// HandleUnsuspendUser handles lifting the suspension of a user
+// @Summary Unsuspend a user
+// @Description Lifts the suspension of a user
+// @Tags admin
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/users/{id}/suspend [delete]
+func HandleUnsuspendUser(ctx *fiber.Ctx) error {
+	return handleUserSuspension(ctx, HandleUnsuspendUserMethod, false)
+}
+
+func handleUserSuspension(ctx *fiber.Ctx, method string, suspend bool) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(method), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(method), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		userID       uint
+		adminService = services.CreateAdminService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		userID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		if suspend {
+			errorResult = adminService.SuspendUser(principal, userID)
+		} else {
+			errorResult = adminService.UnsuspendUser(principal, userID)
+		}
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(method), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleCreateLookup handles adding a value to a lookup table
+// @Summary Create a lookup value
+// @Description Adds a value to a lookup table
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param type path string true "Lookup type" Enums(purpose-types, property-types, furniture-types, conditions, utilities, amenities)
+// @Param lookup body dto.LookupRequest true "Lookup value"
+// @Success 200 {object} dto.LookupResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/lookups/{type} [post]
+func HandleCreateLookup(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleCreateLookupMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleCreateLookupMethod), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		request      dto.LookupRequest
+		response     *dto.LookupResponse
+		adminService = services.CreateAdminService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.LookupRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = adminService.CreateLookup(principal, ctx.Params(lookupTypeParam), request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleCreateLookupMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleUpdateLookup handles renaming a value of a lookup table
+// @Summary Update a lookup value
+// @Description Renames a value of a lookup table
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param type path string true "Lookup type" Enums(purpose-types, property-types, furniture-types, conditions, utilities, amenities)
+// @Param id path int true "Lookup ID"
+// @Param lookup body dto.LookupRequest true "Lookup value"
+// @Success 200 {object} dto.LookupResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/lookups/{type}/{id} [put]
+func HandleUpdateLookup(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleUpdateLookupMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleUpdateLookupMethod), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		request      dto.LookupRequest
+		response     *dto.LookupResponse
+		lookupID     uint
+		adminService = services.CreateAdminService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		lookupID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.LookupRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = adminService.UpdateLookup(principal, ctx.Params(lookupTypeParam), lookupID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUpdateLookupMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleDeleteLookup handles removing a value from a lookup table
+// @Summary Delete a lookup value
+// @Description Soft deletes a value of a lookup table, existing properties keep their reference
+// @Tags admin
+// @Produce json
+// @Param type path string true "Lookup type" Enums(purpose-types, property-types, furniture-types, conditions, utilities, amenities)
+// @Param id path int true "Lookup ID"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/lookups/{type}/{id} [delete]
+func HandleDeleteLookup(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleDeleteLookupMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleDeleteLookupMethod), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		lookupID     uint
+		adminService = services.CreateAdminService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		lookupID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		errorResult = adminService.DeleteLookup(principal, ctx.Params(lookupTypeParam), lookupID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleDeleteLookupMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
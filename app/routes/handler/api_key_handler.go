@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// API key handler methods
+	HandleCreateAPIKeyMethod = "HandleCreateAPIKey"
+	HandleListAPIKeysMethod  = "HandleListAPIKeys"
+	HandleRevokeAPIKeyMethod = "HandleRevokeAPIKey"
+)
+
+// HandleCreateAPIKey handles issuing a new API key
+// @Summary Create an API key
+// @Description Issues a scoped API key for partner integrations. The key is only returned once.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param apiKey body dto.CreateAPIKeyRequest true "API key details"
+// @Success 200 {object} dto.CreateAPIKeyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/api-keys [post]
+func HandleCreateAPIKey(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleCreateAPIKeyMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleCreateAPIKeyMethod), commonLogFields...)
+
+	var (
+		statusCode    int
+		errorResult   *custom.ErrorResult
+		errRes        custom.ErrorResult
+		request       dto.CreateAPIKeyRequest
+		response      *dto.CreateAPIKeyResponse
+		apiKeyService = services.CreateAPIKeyService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.CreateAPIKeyRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = apiKeyService.Create(userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleCreateAPIKeyMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleListAPIKeys handles listing the API keys of the current user
+// @Summary List API keys
+// @Description Lists the active API keys of the current user, without their secrets
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} []dto.APIKeyResponse
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/api-keys [get]
+func HandleListAPIKeys(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListAPIKeysMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListAPIKeysMethod), commonLogFields...)
+
+	var (
+		statusCode    int
+		errorResult   *custom.ErrorResult
+		errRes        custom.ErrorResult
+		response      []dto.APIKeyResponse
+		apiKeyService = services.CreateAPIKeyService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		response, errorResult = apiKeyService.List(userID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListAPIKeysMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleRevokeAPIKey handles revoking an API key of the current user
+// @Summary Revoke an API key
+// @Description Revokes an API key so it can no longer be used
+// @Tags api-keys
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/api-keys/{id} [delete]
+func HandleRevokeAPIKey(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleRevokeAPIKeyMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleRevokeAPIKeyMethod), commonLogFields...)
+
+	var (
+		statusCode    int
+		errorResult   *custom.ErrorResult
+		errRes        custom.ErrorResult
+		apiKeyID      uint
+		apiKeyService = services.CreateAPIKeyService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		apiKeyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		errorResult = apiKeyService.Revoke(userID, apiKeyID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleRevokeAPIKeyMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
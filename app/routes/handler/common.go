@@ -3,6 +3,7 @@ package handler
 import (
 	"strconv"
 
+	"github.com/chazool/serendib_asia_service/pkg/auth"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
@@ -21,14 +22,33 @@ func CreateHandlerContext(requestID string) Context {
 	}
 }
 
+// GetPrincipalFromContext extracts the authenticated principal from the request context
+func GetPrincipalFromContext(c *fiber.Ctx) (*auth.Principal, *custom.ErrorResult) {
+	principal, ok := c.Locals(auth.PrincipalContextKey).(*auth.Principal)
+	if !ok || principal == nil {
+		errRes := custom.BuildUnauthorizedErrResult(constant.ErrAccessTokenCode, "Principal not found in context", "Authorization")
+		return nil, &errRes
+	}
+	return principal, nil
+}
+
 // GetUserIDFromContext extracts the user ID from the request context
 func GetUserIDFromContext(c *fiber.Ctx) (uint, *custom.ErrorResult) {
-	userID, ok := c.Locals("user_id").(uint)
-	if !ok {
-		errRes := custom.BuildBadReqErrResult(constant.ErrAccessTokenCode, "User ID not found in context", "Authorization")
+	principal, errRes := GetPrincipalFromContext(c)
+	if errRes != nil {
+		return 0, errRes
+	}
+	return principal.ID, nil
+}
+
+// GetUintFromParams extracts a numeric route parameter other than the id, naming field in the error
+func GetUintFromParams(c *fiber.Ctx, param, field string) (uint, *custom.ErrorResult) {
+	value, err := strconv.ParseUint(c.Params(param), 10, 32)
+	if err != nil {
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, "Invalid "+field, field)
 		return 0, &errRes
 	}
-	return userID, nil
+	return uint(value), nil
 }
 
 // GetIDFromParams extracts the property ID from the request parameters
@@ -2,10 +2,13 @@ package handler
 
 import (
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
 	"github.com/chazool/serendib_asia_service/app/services"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -98,19 +101,38 @@ func (h *FavoriteHandler) ListFavorites(c *fiber.Ctx) error {
 	log.Logger.Debug(log.TraceMsgFuncStart(FavoriteHandlerListMethod), commonLogFields...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(FavoriteHandlerListMethod), commonLogFields...)
 
+	var (
+		statusCode  = fiber.StatusOK
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		favorites   []dto.FavoriteResponse
+		page        pagination.Page
+	)
+
 	// Get user ID from context
-	userID, err := GetUserIDFromContext(c)
-	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(FavoriteHandlerListMethod), log.TraceError(commonLogFields, err)...)
-		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	userID, errorResult := GetUserIDFromContext(c)
+	if errorResult == nil {
+		// Get the requested page
+		var request dto.PageRequest
+		request, errorResult = validator.ValidatePageRequest(h.handlerContext.RequestID, c)
+		if errorResult == nil {
+			favorites, page, errorResult = h.favoriteSvc.ListFavorites(userID, request)
+		}
+	}
+	if errorResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(FavoriteHandlerListMethod), log.TraceCustomError(commonLogFields, *errorResult)...)
+		statusCode, errRes = HandleError(errorResult)
 	}
 
-	// Get favorites
-	favorites, err := h.favoriteSvc.ListFavorites(userID)
-	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(FavoriteHandlerListMethod), log.TraceError(commonLogFields, err)...)
-		return c.Status(fiber.StatusInternalServerError).JSON(err)
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           c,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      favorites,
+		Page:          &page,
+		RequestID:     h.handlerContext.RequestID,
 	}
+	responseBuilder.BuildAPIResponse()
 
-	return c.Status(fiber.StatusOK).JSON(favorites)
+	return nil
 }
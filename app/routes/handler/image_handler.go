@@ -2,9 +2,11 @@ package handler
 
 import (
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
 	"github.com/chazool/serendib_asia_service/app/services"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 	"github.com/chazool/serendib_asia_service/pkg/web"
 	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
@@ -31,6 +33,8 @@ const (
 // @Param isPrimary formData bool false "Set as primary image"
 // @Success 200 {object} dto.ImageResponse
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties/{propertyId}/images [post]
 func HandleUploadImage(ctx *fiber.Ctx) error {
@@ -47,8 +51,13 @@ func HandleUploadImage(ctx *fiber.Ctx) error {
 		imageService = services.CreateImageService(requestID, nil)
 	)
 
+	userID, errResult := GetUserIDFromContext(ctx)
 	propertyID, err := ctx.ParamsInt("propertyId")
-	if err != nil || propertyID <= 0 {
+	if errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUploadImageMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
+		statusCode, errRes = HandleError(errorResult)
+	} else if err != nil || propertyID <= 0 {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUploadImageMethod), commonLogFields...)
 		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.InvalidRequestErrorMessage, "Invalid property ID")
 		errorResult = &errRes
@@ -70,7 +79,7 @@ func HandleUploadImage(ctx *fiber.Ctx) error {
 				IsPrimary: ctx.FormValue("isPrimary") == "true",
 			}
 
-			response, errorResult = imageService.Upload(uint(propertyID), request)
+			response, errorResult = imageService.Upload(uint(propertyID), userID, request)
 			if errorResult != nil {
 				logFields := log.TraceCustomError(commonLogFields, *errorResult)
 				log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.ImageServiceUploadMethod), logFields...)
@@ -100,6 +109,8 @@ func HandleUploadImage(ctx *fiber.Ctx) error {
 // @Param imageId path int true "Image ID"
 // @Success 200 {object} custom.ErrorResult
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
 // @Failure 404 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties/images/{imageId} [delete]
@@ -116,14 +127,19 @@ func HandleDeleteImage(ctx *fiber.Ctx) error {
 		imageService = services.CreateImageService(requestID, nil)
 	)
 
+	userID, errResult := GetUserIDFromContext(ctx)
 	imageID, err := ctx.ParamsInt("imageId")
-	if err != nil || imageID <= 0 {
+	if errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleDeleteImageMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
+		statusCode, errRes = HandleError(errorResult)
+	} else if err != nil || imageID <= 0 {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleDeleteImageMethod), commonLogFields...)
 		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.InvalidRequestErrorMessage, "Invalid image ID")
 		errorResult = &errRes
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		errorResult = imageService.Delete(uint(imageID))
+		errorResult = imageService.Delete(uint(imageID), userID)
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.ImageServiceDeleteMethod), logFields...)
@@ -151,6 +167,8 @@ func HandleDeleteImage(ctx *fiber.Ctx) error {
 // @Param imageId path int true "Image ID"
 // @Success 200 {object} custom.ErrorResult
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
 // @Failure 404 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties/images/{imageId}/primary [put]
@@ -167,14 +185,19 @@ func HandleSetPrimaryImage(ctx *fiber.Ctx) error {
 		imageService = services.CreateImageService(requestID, nil)
 	)
 
+	userID, errResult := GetUserIDFromContext(ctx)
 	imageID, err := ctx.ParamsInt("imageId")
-	if err != nil || imageID <= 0 {
+	if errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleSetPrimaryImageMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
+		statusCode, errRes = HandleError(errorResult)
+	} else if err != nil || imageID <= 0 {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleSetPrimaryImageMethod), commonLogFields...)
 		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.InvalidRequestErrorMessage, "Invalid image ID")
 		errorResult = &errRes
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		errorResult = imageService.SetPrimary(uint(imageID))
+		errorResult = imageService.SetPrimary(uint(imageID), userID)
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.ImageServiceSetPrimaryMethod), logFields...)
@@ -200,8 +223,12 @@ func HandleSetPrimaryImage(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param propertyId path int true "Property ID"
+// @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Param include_total query bool false "Include the total number of images"
 // @Success 200 {object} dto.ImageListResponse
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties/{propertyId}/images [get]
 func HandleListImages(ctx *fiber.Ctx) error {
@@ -215,6 +242,7 @@ func HandleListImages(ctx *fiber.Ctx) error {
 		errorResult  *custom.ErrorResult
 		errRes       custom.ErrorResult
 		response     *dto.ImageListResponse
+		page         pagination.Page
 		imageService = services.CreateImageService(requestID, nil)
 	)
 
@@ -225,7 +253,13 @@ func HandleListImages(ctx *fiber.Ctx) error {
 		errorResult = &errRes
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		response, errorResult = imageService.List(uint(propertyID))
+		var request dto.PageRequest
+		request, errorResult = validator.ValidatePageRequest(requestID, ctx)
+		if errorResult == nil {
+			// Signed in users may also see the images of their own listings that are not published
+			principal, _ := GetPrincipalFromContext(ctx)
+			response, page, errorResult = imageService.List(uint(propertyID), principal, request)
+		}
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.ImageServiceListMethod), logFields...)
@@ -238,6 +272,7 @@ func HandleListImages(ctx *fiber.Ctx) error {
 		HTTPStatus:    statusCode,
 		ErrorResponse: errRes,
 		Response:      response,
+		Page:          &page,
 		RequestID:     requestID,
 	}
 	responseBuilder.BuildAPIResponse()
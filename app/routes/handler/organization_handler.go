@@ -0,0 +1,631 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Organization handler methods
+	HandleCreateOrganizationMethod           = "HandleCreateOrganization"
+	HandleListMyOrganizationsMethod          = "HandleListMyOrganizations"
+	HandleUpdateOrganizationMethod           = "HandleUpdateOrganization"
+	HandleListOrganizationMembersMethod      = "HandleListOrganizationMembers"
+	HandleUpdateOrganizationMemberMethod     = "HandleUpdateOrganizationMember"
+	HandleRemoveOrganizationMemberMethod     = "HandleRemoveOrganizationMember"
+	HandleInviteOrganizationMemberMethod     = "HandleInviteOrganizationMember"
+	HandleListOrganizationInvitationsMethod  = "HandleListOrganizationInvitations"
+	HandleRevokeOrganizationInvitationMethod = "HandleRevokeOrganizationInvitation"
+	HandleAcceptOrganizationInvitationMethod = "HandleAcceptOrganizationInvitation"
+	HandleGetOrganizationProfileMethod       = "HandleGetOrganizationProfile"
+
+	memberIDParam     = "userId"
+	invitationIDParam = "invitationId"
+)
+
+// HandleCreateOrganization handles creating an organization
+// @Summary Create an organization
+// @Description Creates an organization with the current user as its owner
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param organization body dto.OrganizationRequest true "Organization details"
+// @Success 200 {object} dto.OrganizationResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations [post]
+func HandleCreateOrganization(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleCreateOrganizationMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleCreateOrganizationMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		request     dto.OrganizationRequest
+		response    *dto.OrganizationResponse
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.OrganizationRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = orgService.Create(userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleCreateOrganizationMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleListMyOrganizations handles listing the organizations of the current user
+// @Summary List my organizations
+// @Description Lists the organizations the current user belongs to, with their role in each
+// @Tags organizations
+// @Produce json
+// @Success 200 {object} []dto.OrganizationResponse
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations [get]
+func HandleListMyOrganizations(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListMyOrganizationsMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListMyOrganizationsMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		response    []dto.OrganizationResponse
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		response, errorResult = orgService.ListMine(userID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListMyOrganizationsMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleUpdateOrganization handles updating the profile of an organization
+// @Summary Update an organization
+// @Description Updates the profile of an organization, owners and managers only
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param organization body dto.OrganizationRequest true "Organization details"
+// @Success 200 {object} dto.OrganizationResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id} [put]
+func HandleUpdateOrganization(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleUpdateOrganizationMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleUpdateOrganizationMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		request     dto.OrganizationRequest
+		response    *dto.OrganizationResponse
+		orgID       uint
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.OrganizationRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = orgService.Update(orgID, userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUpdateOrganizationMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleListOrganizationMembers handles listing the members of an organization
+// @Summary List organization members
+// @Description Lists the members of an organization and their roles, members only
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} []dto.OrganizationMemberResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/members [get]
+func HandleListOrganizationMembers(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListOrganizationMembersMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListOrganizationMembersMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		response    []dto.OrganizationMemberResponse
+		orgID       uint
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = orgService.ListMembers(orgID, userID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListOrganizationMembersMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleUpdateOrganizationMember handles changing the role of an organization member
+// @Summary Change a member role
+// @Description Changes the role of a member, owners only. The last owner cannot step down
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID of the member"
+// @Param role body dto.UpdateMemberRoleRequest true "New role"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/members/{userId} [put]
+func HandleUpdateOrganizationMember(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleUpdateOrganizationMemberMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleUpdateOrganizationMemberMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		request     dto.UpdateMemberRoleRequest
+		orgID       uint
+		memberID    uint
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		memberID, errorResult = GetUintFromParams(ctx, memberIDParam, "user ID")
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.UpdateMemberRoleRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		errorResult = orgService.UpdateMemberRole(orgID, userID, memberID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUpdateOrganizationMemberMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleRemoveOrganizationMember handles removing a member from an organization
+// @Summary Remove a member
+// @Description Removes a member from an organization. Members may remove themselves to leave
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param userId path int true "User ID of the member"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/members/{userId} [delete]
+func HandleRemoveOrganizationMember(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleRemoveOrganizationMemberMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleRemoveOrganizationMemberMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		orgID       uint
+		memberID    uint
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		memberID, errorResult = GetUintFromParams(ctx, memberIDParam, "user ID")
+	}
+	if errorResult == nil {
+		errorResult = orgService.RemoveMember(orgID, userID, memberID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleRemoveOrganizationMemberMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleInviteOrganizationMember handles inviting someone to an organization
+// @Summary Invite a member
+// @Description Emails an invitation to join the organization, owners and managers only
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param invitation body dto.InviteMemberRequest true "Invitee and role"
+// @Success 200 {object} dto.InvitationResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/invitations [post]
+func HandleInviteOrganizationMember(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleInviteOrganizationMemberMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleInviteOrganizationMemberMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		request     dto.InviteMemberRequest
+		response    *dto.InvitationResponse
+		orgID       uint
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.InviteMemberRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = orgService.Invite(orgID, userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleInviteOrganizationMemberMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleListOrganizationInvitations handles listing the pending invitations of an organization
+// @Summary List invitations
+// @Description Lists the pending invitations of an organization, owners and managers only
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Success 200 {object} []dto.InvitationResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/invitations [get]
+func HandleListOrganizationInvitations(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListOrganizationInvitationsMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListOrganizationInvitationsMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		response    []dto.InvitationResponse
+		orgID       uint
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = orgService.ListInvitations(orgID, userID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListOrganizationInvitationsMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleRevokeOrganizationInvitation handles revoking a pending invitation
+// @Summary Revoke an invitation
+// @Description Revokes a pending invitation so its link stops working
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param invitationId path int true "Invitation ID"
+// @Success 200
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/invitations/{invitationId} [delete]
+func HandleRevokeOrganizationInvitation(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleRevokeOrganizationInvitationMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleRevokeOrganizationInvitationMethod), commonLogFields...)
+
+	var (
+		statusCode   int
+		errorResult  *custom.ErrorResult
+		errRes       custom.ErrorResult
+		orgID        uint
+		invitationID uint
+		orgService   = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		orgID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		invitationID, errorResult = GetUintFromParams(ctx, invitationIDParam, "invitation ID")
+	}
+	if errorResult == nil {
+		errorResult = orgService.RevokeInvitation(orgID, userID, invitationID)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleRevokeOrganizationInvitationMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleAcceptOrganizationInvitation handles accepting an emailed invitation
+// @Summary Accept an invitation
+// @Description Adds the current user to the organization of an invitation sent to their email address
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param invitation body dto.AcceptInvitationRequest true "Invitation token"
+// @Success 200 {object} dto.OrganizationResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/invitations/accept [post]
+func HandleAcceptOrganizationInvitation(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleAcceptOrganizationInvitationMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleAcceptOrganizationInvitationMethod), commonLogFields...)
+
+	var (
+		statusCode  int
+		errorResult *custom.ErrorResult
+		errRes      custom.ErrorResult
+		request     dto.AcceptInvitationRequest
+		response    *dto.OrganizationResponse
+		orgService  = services.CreateOrganizationService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.AcceptInvitationRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = orgService.AcceptInvitation(userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleAcceptOrganizationInvitationMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleGetOrganizationProfile handles the public agency page of an organization
+// @Summary Get an agency profile
+// @Description Returns the public profile of an organization with a page of its listings
+// @Tags organizations
+// @Produce json
+// @Param id path int true "Organization ID"
+// @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Success 200 {object} dto.OrganizationProfileResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/organizations/{id}/public [get]
+func HandleGetOrganizationProfile(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleGetOrganizationProfileMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleGetOrganizationProfileMethod), commonLogFields...)
+
+	var (
+		statusCode int
+		errRes     custom.ErrorResult
+		response   *dto.OrganizationProfileResponse
+		orgService = services.CreateOrganizationService(requestID, nil)
+	)
+
+	orgID, errorResult := GetIDFromParams(ctx)
+	if errorResult == nil {
+		var request dto.PageRequest
+		request, errorResult = validator.ValidatePageRequest(requestID, ctx)
+		if errorResult == nil {
+			response, errorResult = orgService.GetPublicProfile(orgID, request)
+		}
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleGetOrganizationProfileMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Property duplicate handler methods
+	HandleListDuplicatesMethod   = "HandleListDuplicates"
+	HandleMergeDuplicateMethod   = "HandleMergeDuplicate"
+	HandleDismissDuplicateMethod = "HandleDismissDuplicate"
+)
+
+// HandleListDuplicates handles listing the pairs of listings flagged as duplicates
+// @Summary List duplicate listings
+// @Description Lists the open pairs of listings flagged as likely the same property, most similar first. Requires the properties:moderate permission.
+// @Tags admin
+// @Produce json
+// @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Param include_total query bool false "Include the total number of flags"
+// @Success 200 {object} []dto.PropertyDuplicateResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/duplicates [get]
+func HandleListDuplicates(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListDuplicatesMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListDuplicatesMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PageRequest
+		response        []dto.PropertyDuplicateResponse
+		page            pagination.Page
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.ValidatePageRequest(requestID, ctx)
+	}
+	if errorResult == nil {
+		response, page, errorResult = propertyService.ListDuplicates(principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListDuplicatesMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+		Page:          &page,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleMergeDuplicate handles merging a pair of duplicate listings
+// @Summary Merge duplicate listings
+// @Description Keeps one listing of a flagged pair and archives the other. Requires the properties:moderate permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Duplicate flag ID"
+// @Param merge body dto.PropertyDuplicateMergeRequest true "Listing to keep and note"
+// @Success 200 {object} dto.PropertyDuplicateResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/duplicates/{id}/merge [post]
+func HandleMergeDuplicate(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleMergeDuplicateMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleMergeDuplicateMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PropertyDuplicateMergeRequest
+		response        dto.PropertyDuplicateResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var duplicateID uint
+	if errorResult == nil {
+		duplicateID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.PropertyDuplicateMergeRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.MergeDuplicate(duplicateID, principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleMergeDuplicateMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleDismissDuplicate handles dismissing a pair of listings flagged as duplicates
+// @Summary Dismiss a duplicate flag
+// @Description Marks a flagged pair as not duplicates, leaving both listings as they are. Requires the properties:moderate permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Duplicate flag ID"
+// @Param dismissal body dto.PropertyDuplicateDismissRequest false "Optional note"
+// @Success 200 {object} dto.PropertyDuplicateResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/duplicates/{id}/dismiss [post]
+func HandleDismissDuplicate(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleDismissDuplicateMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleDismissDuplicateMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PropertyDuplicateDismissRequest
+		response        dto.PropertyDuplicateResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var duplicateID uint
+	if errorResult == nil {
+		duplicateID, errorResult = GetIDFromParams(ctx)
+	}
+	// The note is optional, so an empty body is accepted
+	if errorResult == nil && len(ctx.Body()) > 0 {
+		request, errorResult = validator.GenericBaseValidator[dto.PropertyDuplicateDismissRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.DismissDuplicate(duplicateID, principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleDismissDuplicateMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
@@ -2,10 +2,11 @@ package handler
 
 import (
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
 	"github.com/chazool/serendib_asia_service/app/services"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
-	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/web"
 	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
 
@@ -20,6 +21,7 @@ const (
 	HandleDeletePropertyMethod       = "HandleDeleteProperty"
 	HandleListPropertiesMethod       = "HandleListProperties"
 	HandleListPropertiesByUserMethod = "HandleListPropertiesByUser"
+	HandlePropertyMapMethod          = "HandlePropertyMap"
 )
 
 // HandleCreateProperty handles the creation of a new property
@@ -31,6 +33,7 @@ const (
 // @Param property body dto.PropertyRequest true "Property details"
 // @Success 200 {object} dto.Property
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties [post]
 func HandleCreateProperty(ctx *fiber.Ctx) error {
@@ -48,14 +51,17 @@ func HandleCreateProperty(ctx *fiber.Ctx) error {
 		propertyService = services.CreatePropertyService(requestID, nil)
 	)
 
-	if err := ctx.BodyParser(&request); err != nil {
-		logFields := log.TraceError(commonLogFields, err)
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleCreatePropertyMethod), logFields...)
-		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.InvalidRequestErrorMessage, err.Error())
-		errorResult = &errRes
+	userID, errResult := GetUserIDFromContext(ctx)
+	if errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleCreatePropertyMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
+		statusCode, errRes = HandleError(errorResult)
+	} else if request, errResult = validator.GenericBaseValidator[dto.PropertyRequest](requestID, ctx); errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(validator.GenericBaseValidatorMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		response, errorResult = propertyService.Create(request)
+		response, errorResult = propertyService.Create(userID, request)
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceCreateMethod), logFields...)
@@ -77,7 +83,7 @@ func HandleCreateProperty(ctx *fiber.Ctx) error {
 
 // HandleGetProperty handles retrieving a property by ID
 // @Summary Get a property by ID
-// @Description Retrieves a property's details by its ID
+// @Description Retrieves a property's details by its ID. Listings that are not published are only returned to those who manage them.
 // @Tags properties
 // @Accept json
 // @Produce json
@@ -107,7 +113,9 @@ func HandleGetProperty(ctx *fiber.Ctx) error {
 		errorResult = err
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		response, errorResult = propertyService.GetByID(propertyID)
+		// Signed in users may also see their own listings that are not published
+		principal, _ := GetPrincipalFromContext(ctx)
+		response, errorResult = propertyService.GetByID(propertyID, principal)
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceGetByIDMethod), logFields...)
@@ -137,6 +145,8 @@ func HandleGetProperty(ctx *fiber.Ctx) error {
 // @Param property body dto.PropertyRequest true "Property details"
 // @Success 200 {object} dto.PropertyResponse
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
 // @Failure 404 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties [put]
@@ -155,20 +165,23 @@ func HandleUpdateProperty(ctx *fiber.Ctx) error {
 		propertyService = services.CreatePropertyService(requestID, nil)
 	)
 
+	userID, errResult := GetUserIDFromContext(ctx)
 	propertyID, err := GetIDFromParams(ctx)
-	if err != nil {
+	if errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUpdatePropertyMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
+		statusCode, errRes = HandleError(errorResult)
+	} else if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleGetPropertyMethod), commonLogFields...)
 		errorResult = err
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		if err := ctx.BodyParser(&request); err != nil {
-			logFields := log.TraceError(commonLogFields, err)
-			log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleUpdatePropertyMethod), logFields...)
-			errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.InvalidRequestErrorMessage, err.Error())
-			errorResult = &errRes
+		if request, errResult = validator.GenericBaseValidator[dto.PropertyRequest](requestID, ctx); errResult != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(validator.GenericBaseValidatorMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+			errorResult = errResult
 			statusCode, errRes = HandleError(errorResult)
 		} else {
-			response, errorResult = propertyService.Update(uint(propertyID), request)
+			response, errorResult = propertyService.Update(propertyID, userID, request)
 			if errorResult != nil {
 				logFields := log.TraceCustomError(commonLogFields, *errorResult)
 				log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceUpdateMethod), logFields...)
@@ -198,6 +211,8 @@ func HandleUpdateProperty(ctx *fiber.Ctx) error {
 // @Param id query int true "Property ID"
 // @Success 200 {object} dto.PropertyResponse
 // @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
 // @Failure 404 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
 // @Router /api/properties [delete]
@@ -215,13 +230,18 @@ func HandleDeleteProperty(ctx *fiber.Ctx) error {
 		propertyService = services.CreatePropertyService(requestID, nil)
 	)
 
+	userID, errResult := GetUserIDFromContext(ctx)
 	propertyID, err := GetIDFromParams(ctx)
-	if err != nil {
+	if errResult != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleDeletePropertyMethod), log.TraceCustomError(commonLogFields, *errResult)...)
+		errorResult = errResult
+		statusCode, errRes = HandleError(errorResult)
+	} else if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleGetPropertyMethod), commonLogFields...)
 		errorResult = err
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		response, errorResult = propertyService.Delete(uint(propertyID))
+		response, errorResult = propertyService.Delete(propertyID, userID)
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceDeleteMethod), logFields...)
@@ -243,12 +263,39 @@ func HandleDeleteProperty(ctx *fiber.Ctx) error {
 
 // HandleListProperties handles listing properties
 // @Summary List properties
-// @Description Lists properties with pagination
+// @Description Lists properties matching the given filters with pagination and sorting
 // @Tags properties
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Param include_total query bool false "Include the total number of matches"
+// @Param q query string false "Keywords searched in the title, description, city and address"
+// @Param purpose_id query int false "Purpose ID"
+// @Param property_type_id query int false "Property type ID"
+// @Param pricing_type query string false "Pricing type" Enums(sell, rent, stay)
+// @Param furniture_type_id query int false "Furniture type ID"
+// @Param condition_id query int false "Condition ID"
+// @Param city query string false "City"
+// @Param min_price query number false "Minimum price"
+// @Param max_price query number false "Maximum price"
+// @Param min_bedrooms query int false "Minimum bedrooms"
+// @Param min_bathrooms query int false "Minimum bathrooms"
+// @Param min_size query number false "Minimum size"
+// @Param max_size query number false "Maximum size"
+// @Param amenity_ids query []int false "Amenity IDs, all must be present" collectionFormat(multi)
+// @Param utility_ids query []int false "Utility IDs, all must be present" collectionFormat(multi)
+// @Param is_negotiable query bool false "Negotiable price"
+// @Param lat query number false "Latitude of the point distances are measured from"
+// @Param lng query number false "Longitude of the point distances are measured from"
+// @Param radius_km query number false "Only listings within this distance of lat/lng"
+// @Param min_lat query number false "Map viewport south edge"
+// @Param max_lat query number false "Map viewport north edge"
+// @Param min_lng query number false "Map viewport west edge"
+// @Param max_lng query number false "Map viewport east edge"
+// @Param polygon query string false "Polygon as lat,lng pairs separated by ;"
+// @Param sort_by query string false "Sort field, relevance first when q is given" Enums(price, date, size, distance)
+// @Param sort_order query string false "Sort order" Enums(asc, desc)
 // @Success 200 {object} []dto.PropertyResponse
 // @Failure 400 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
@@ -264,17 +311,77 @@ func HandleListProperties(ctx *fiber.Ctx) error {
 		errorResult     *custom.ErrorResult
 		errRes          custom.ErrorResult
 		response        []dto.Property
+		page            pagination.Page
 		propertyService = services.CreatePropertyService(requestID, nil)
 	)
 
-	page := ctx.QueryInt("page", 1)
-	pageSize := ctx.QueryInt("page_size", 10)
+	filter, errorResult := validator.ValidatePropertyFilterRequest(requestID, ctx)
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(validator.ValidatePropertyFilterRequestMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	} else {
+		response, page, errorResult = propertyService.List(filter)
+		if errorResult != nil {
+			logFields := log.TraceCustomError(commonLogFields, *errorResult)
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceListMethod), logFields...)
+			statusCode, errRes = HandleError(errorResult)
+		}
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+		Page:          &page,
+	}
+	responseBuilder.BuildAPIResponse()
 
-	response, errorResult = propertyService.List(page, pageSize)
+	return nil
+}
+
+// HandlePropertyMap handles the map view of properties
+// @Summary Map view of properties
+// @Description Groups the properties inside the viewport into clusters, or lists them individually from zoom 15. Accepts every filter of the list endpoint.
+// @Tags properties
+// @Accept json
+// @Produce json
+// @Param zoom query int false "Map zoom level (0-22)"
+// @Param min_lat query number true "Map viewport south edge"
+// @Param max_lat query number true "Map viewport north edge"
+// @Param min_lng query number true "Map viewport west edge"
+// @Param max_lng query number true "Map viewport east edge"
+// @Success 200 {object} dto.PropertyMapResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/properties/map [get]
+func HandlePropertyMap(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandlePropertyMapMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandlePropertyMapMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        dto.PropertyMapResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	request, errorResult := validator.ValidatePropertyMapRequest(requestID, ctx)
 	if errorResult != nil {
 		logFields := log.TraceCustomError(commonLogFields, *errorResult)
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceListMethod), logFields...)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(validator.ValidatePropertyMapRequestMethod), logFields...)
 		statusCode, errRes = HandleError(errorResult)
+	} else {
+		response, errorResult = propertyService.Map(request)
+		if errorResult != nil {
+			logFields := log.TraceCustomError(commonLogFields, *errorResult)
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceMapMethod), logFields...)
+			statusCode, errRes = HandleError(errorResult)
+		}
 	}
 
 	responseBuilder := responsebuilder.APIResponse{
@@ -295,8 +402,9 @@ func HandleListProperties(ctx *fiber.Ctx) error {
 // @Tags properties
 // @Accept json
 // @Produce json
-// @Param page query int false "Page number"
 // @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Param include_total query bool false "Include the total number of listings"
 // @Success 200 {object} []dto.PropertyResponse
 // @Failure 400 {object} custom.ErrorResult
 // @Failure 500 {object} custom.ErrorResult
@@ -312,6 +420,7 @@ func HandleListPropertiesByUser(ctx *fiber.Ctx) error {
 		errorResult     *custom.ErrorResult
 		errRes          custom.ErrorResult
 		response        []dto.Property
+		page            pagination.Page
 		propertyService = services.CreatePropertyService(requestID, nil)
 	)
 
@@ -322,11 +431,11 @@ func HandleListPropertiesByUser(ctx *fiber.Ctx) error {
 		errorResult = err
 		statusCode, errRes = HandleError(errorResult)
 	} else {
-		page := ctx.QueryInt("page", 1)
-		pageSize := ctx.QueryInt("limit", 10)
-		offset := (page - 1) * pageSize
-
-		response, errorResult = propertyService.ListByUserID(userID, offset, pageSize)
+		var request dto.PageRequest
+		request, errorResult = validator.ValidatePageRequest(requestID, ctx)
+		if errorResult == nil {
+			response, page, errorResult = propertyService.ListByUserID(userID, request)
+		}
 		if errorResult != nil {
 			logFields := log.TraceCustomError(commonLogFields, *errorResult)
 			log.Logger.Error(log.TraceMsgErrorOccurredFrom(services.PropertyServiceListByUserIDMethod), logFields...)
@@ -340,6 +449,7 @@ func HandleListPropertiesByUser(ctx *fiber.Ctx) error {
 		ErrorResponse: errRes,
 		Response:      response,
 		RequestID:     requestID,
+		Page:          &page,
 	}
 	responseBuilder.BuildAPIResponse()
 
@@ -0,0 +1,299 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Property moderation handler methods
+	HandleListModerationQueueMethod       = "HandleListModerationQueue"
+	HandleApprovePropertyMethod           = "HandleApproveProperty"
+	HandleRejectPropertyMethod            = "HandleRejectProperty"
+	HandlePropertyModerationHistoryMethod = "HandlePropertyModerationHistory"
+	HandlePropertyScreeningHistoryMethod  = "HandlePropertyScreeningHistory"
+)
+
+// HandleListModerationQueue handles listing the properties waiting for moderation
+// @Summary List the moderation queue
+// @Description Lists the listings under review, longest waiting first. Requires the properties:moderate permission.
+// @Tags admin
+// @Produce json
+// @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Param include_total query bool false "Include the total number of listings"
+// @Success 200 {object} []dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/properties [get]
+func HandleListModerationQueue(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListModerationQueueMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListModerationQueueMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PageRequest
+		response        []dto.Property
+		page            pagination.Page
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.ValidatePageRequest(requestID, ctx)
+	}
+	if errorResult == nil {
+		response, page, errorResult = propertyService.ListPendingReview(principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListModerationQueueMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+		Page:          &page,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleApproveProperty handles approving a listing under review
+// @Summary Approve a listing
+// @Description Publishes a listing under review and records the decision. Requires the properties:moderate permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Property ID"
+// @Param approval body dto.PropertyApprovalRequest false "Optional note"
+// @Success 200 {object} dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/properties/{id}/approve [post]
+func HandleApproveProperty(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleApprovePropertyMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleApprovePropertyMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PropertyApprovalRequest
+		response        dto.Property
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	// The note is optional, so an empty body is accepted
+	if errorResult == nil && len(ctx.Body()) > 0 {
+		request, errorResult = validator.GenericBaseValidator[dto.PropertyApprovalRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.Approve(propertyID, principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleApprovePropertyMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleRejectProperty handles rejecting a listing under review
+// @Summary Reject a listing
+// @Description Rejects a listing under review with a reason code and note, which are emailed to the owner. Requires the properties:moderate permission.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "Property ID"
+// @Param rejection body dto.PropertyRejectionRequest true "Reason code and note"
+// @Success 200 {object} dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/properties/{id}/reject [post]
+func HandleRejectProperty(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleRejectPropertyMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleRejectPropertyMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PropertyRejectionRequest
+		response        dto.Property
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.PropertyRejectionRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.Reject(propertyID, principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleRejectPropertyMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandlePropertyModerationHistory handles listing the moderation decisions on a property
+// @Summary Moderation history of a property
+// @Description Lists every approval and rejection of a property, oldest first. Requires the properties:moderate permission.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Property ID"
+// @Success 200 {object} []dto.PropertyModerationDecisionResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/properties/{id}/history [get]
+func HandlePropertyModerationHistory(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandlePropertyModerationHistoryMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandlePropertyModerationHistoryMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        []dto.PropertyModerationDecisionResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.ModerationHistory(propertyID, principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandlePropertyModerationHistoryMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandlePropertyScreeningHistory handles listing the content screenings that flagged a property
+// @Summary Content screening history of a property
+// @Description Lists the risk score and matched rules of every screening that flagged the text of a property, oldest first. Requires the properties:moderate permission.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Property ID"
+// @Success 200 {object} []dto.PropertyScreeningResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/admin/moderation/properties/{id}/screenings [get]
+func HandlePropertyScreeningHistory(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandlePropertyScreeningHistoryMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandlePropertyScreeningHistoryMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        []dto.PropertyScreeningResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.ScreeningHistory(propertyID, principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandlePropertyScreeningHistoryMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
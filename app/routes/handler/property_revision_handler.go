@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Property revision handler methods
+	HandleListPropertyRevisionsMethod   = "HandleListPropertyRevisions"
+	HandleDiffPropertyRevisionsMethod   = "HandleDiffPropertyRevisions"
+	HandleRestorePropertyRevisionMethod = "HandleRestorePropertyRevision"
+
+	versionParam = "version"
+)
+
+// HandleListPropertyRevisions handles listing the revisions of a property
+// @Summary Revisions of a property
+// @Description Lists every stored revision of a property with its content, oldest first
+// @Tags properties
+// @Produce json
+// @Param id path int true "Property ID"
+// @Success 200 {object} []dto.PropertyRevisionResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/{id}/revisions [get]
+func HandleListPropertyRevisions(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListPropertyRevisionsMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListPropertyRevisionsMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        []dto.PropertyRevisionResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.ListRevisions(propertyID, principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListPropertyRevisionsMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleDiffPropertyRevisions handles comparing two revisions of a property
+// @Summary Compare two revisions of a property
+// @Description Lists the fields whose values differ between two revisions of a property
+// @Tags properties
+// @Produce json
+// @Param id path int true "Property ID"
+// @Param from query int true "Revision to compare from"
+// @Param to query int true "Revision to compare to"
+// @Success 200 {object} dto.PropertyRevisionDiffResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/{id}/revisions/diff [get]
+func HandleDiffPropertyRevisions(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleDiffPropertyRevisionsMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleDiffPropertyRevisionsMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PropertyRevisionDiffRequest
+		response        dto.PropertyRevisionDiffResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.ValidatePropertyRevisionDiffRequest(requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.DiffRevisions(propertyID, principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleDiffPropertyRevisionsMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleRestorePropertyRevision handles restoring an earlier revision of a property
+// @Summary Restore a revision of a property
+// @Description Updates a property back to the content of an earlier revision, stored as a new revision
+// @Tags properties
+// @Produce json
+// @Param id path int true "Property ID"
+// @Param version path int true "Revision to restore"
+// @Success 200 {object} dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/{id}/revisions/{version}/restore [post]
+func HandleRestorePropertyRevision(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleRestorePropertyRevisionMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleRestorePropertyRevisionMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        dto.Property
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID, version uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		version, errorResult = GetUintFromParams(ctx, versionParam, "version")
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.RestoreRevision(propertyID, int(version), principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleRestorePropertyRevisionMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+	"github.com/chazool/serendib_asia_service/pkg/web/responsebuilder"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// Property status handler methods
+	HandleChangePropertyStatusMethod  = "HandleChangePropertyStatus"
+	HandlePropertyStatusHistoryMethod = "HandlePropertyStatusHistory"
+	HandleListMyPropertiesMethod      = "HandleListMyProperties"
+	HandleRenewPropertyMethod         = "HandleRenewProperty"
+)
+
+// HandleChangePropertyStatus handles moving a property to another status
+// @Summary Change the status of a property
+// @Description Moves a property along its lifecycle, e.g. publishes a draft or marks a listing sold. Approving a listing under review takes a moderator.
+// @Tags properties
+// @Accept json
+// @Produce json
+// @Param id path int true "Property ID"
+// @Param status body dto.PropertyStatusRequest true "New status"
+// @Success 200 {object} dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/{id}/status [put]
+func HandleChangePropertyStatus(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleChangePropertyStatusMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleChangePropertyStatusMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.PropertyStatusRequest
+		response        dto.Property
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		request, errorResult = validator.GenericBaseValidator[dto.PropertyStatusRequest](requestID, ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.ChangeStatus(propertyID, principal, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleChangePropertyStatusMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleRenewProperty handles extending the listing period of a property
+// @Summary Renew a property listing
+// @Description Extends the listing period of a published, reserved or expired property by the period of its pricing type; an expired listing is published again. Renewals per user are limited.
+// @Tags properties
+// @Produce json
+// @Param id path int true "Property ID"
+// @Success 200 {object} dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/{id}/renew [post]
+func HandleRenewProperty(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleRenewPropertyMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleRenewPropertyMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        dto.Property
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.Renew(propertyID, principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleRenewPropertyMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandlePropertyStatusHistory handles listing the status history of a property
+// @Summary Status history of a property
+// @Description Lists every status transition of a property with its time, oldest first
+// @Tags properties
+// @Produce json
+// @Param id path int true "Property ID"
+// @Success 200 {object} []dto.PropertyStatusChangeResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 403 {object} custom.ErrorResult
+// @Failure 404 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/{id}/status/history [get]
+func HandlePropertyStatusHistory(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandlePropertyStatusHistoryMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandlePropertyStatusHistoryMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		response        []dto.PropertyStatusChangeResponse
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	principal, errorResult := GetPrincipalFromContext(ctx)
+	var propertyID uint
+	if errorResult == nil {
+		propertyID, errorResult = GetIDFromParams(ctx)
+	}
+	if errorResult == nil {
+		response, errorResult = propertyService.StatusHistory(propertyID, principal)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandlePropertyStatusHistoryMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
+
+// HandleListMyProperties handles listing the properties of the signed in user in every status
+// @Summary List my properties
+// @Description Lists the properties of the signed in user whatever their status, newest first
+// @Tags properties
+// @Produce json
+// @Param status query string false "Only listings in this status" Enums(draft, pending_review, rejected, published, reserved, sold, rented, expired, archived)
+// @Param page_size query int false "Page size"
+// @Param cursor query string false "Page token from a previous response"
+// @Param include_total query bool false "Include the total number of listings"
+// @Success 200 {object} []dto.PropertyResponse
+// @Failure 400 {object} custom.ErrorResult
+// @Failure 401 {object} custom.ErrorResult
+// @Failure 500 {object} custom.ErrorResult
+// @Router /api/v1/properties/mine [get]
+func HandleListMyProperties(ctx *fiber.Ctx) error {
+	requestID := web.GetRequestID(ctx)
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Info(log.TraceMsgFuncStart(HandleListMyPropertiesMethod), commonLogFields...)
+	defer log.Logger.Info(log.TraceMsgFuncEnd(HandleListMyPropertiesMethod), commonLogFields...)
+
+	var (
+		statusCode      int
+		errRes          custom.ErrorResult
+		request         dto.MyPropertiesRequest
+		response        []dto.Property
+		page            pagination.Page
+		propertyService = services.CreatePropertyService(requestID, nil)
+	)
+
+	userID, errorResult := GetUserIDFromContext(ctx)
+	if errorResult == nil {
+		request, errorResult = validator.ValidateMyPropertiesRequest(requestID, ctx)
+	}
+	if errorResult == nil {
+		response, page, errorResult = propertyService.ListMine(userID, request)
+	}
+	if errorResult != nil {
+		logFields := log.TraceCustomError(commonLogFields, *errorResult)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(HandleListMyPropertiesMethod), logFields...)
+		statusCode, errRes = HandleError(errorResult)
+	}
+
+	responseBuilder := responsebuilder.APIResponse{
+		Ctx:           ctx,
+		HTTPStatus:    statusCode,
+		ErrorResponse: errRes,
+		Response:      response,
+		RequestID:     requestID,
+		Page:          &page,
+	}
+	responseBuilder.BuildAPIResponse()
+
+	return nil
+}
@@ -1,7 +1,13 @@
 package handler
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
 	"github.com/chazool/serendib_asia_service/app/services"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
@@ -17,6 +23,27 @@ const (
 	UserHandlerGetProfileMethod     = "UserHandlerGetProfile"
 	UserHandlerUpdateProfileMethod  = "UserHandlerUpdateProfile"
 	UserHandlerUpdatePasswordMethod = "UserHandlerUpdatePassword"
+	UserHandlerRefreshTokenMethod   = "UserHandlerRefreshToken"
+	UserHandlerLogoutMethod         = "UserHandlerLogout"
+	UserHandlerForgotPasswordMethod = "UserHandlerForgotPassword"
+	UserHandlerResetPasswordMethod  = "UserHandlerResetPassword"
+	UserHandlerVerifyEmailMethod    = "UserHandlerVerifyEmail"
+	UserHandlerResendVerifyMethod   = "UserHandlerResendVerification"
+	UserHandlerUnlockAccountMethod  = "UserHandlerUnlockAccount"
+	UserHandlerListSessionsMethod   = "UserHandlerListSessions"
+	UserHandlerRevokeSessionMethod  = "UserHandlerRevokeSession"
+	UserHandlerRevokeAllMethod      = "UserHandlerRevokeAllSessions"
+	UserHandlerExportDataMethod     = "UserHandlerExportData"
+	UserHandlerDeleteAccountMethod  = "UserHandlerDeleteAccount"
+	UserHandlerSendPhoneCodeMethod  = "UserHandlerSendPhoneVerification"
+	UserHandlerVerifyPhoneMethod    = "UserHandlerVerifyPhone"
+	UserHandlerPublicProfileMethod  = "UserHandlerGetPublicProfile"
+)
+
+// personal data export formats
+const (
+	exportFormatJSON = "json"
+	exportFormatZIP  = "zip"
 )
 
 type UserHandler struct {
@@ -48,7 +75,7 @@ func (h *UserHandler) Register(c *fiber.Ctx) error {
 	}
 
 	// Register user
-	response, err := h.userSvc.Register(&request)
+	response, err := h.userSvc.Register(&request, clientInfo(c))
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRegisterMethod), log.TraceError(commonLogFields, err)...)
 		return c.Status(fiber.StatusInternalServerError).JSON(err)
@@ -72,10 +99,10 @@ func (h *UserHandler) Login(c *fiber.Ctx) error {
 	}
 
 	// Login user
-	response, err := h.userSvc.Login(&request)
+	response, err := h.userSvc.Login(&request, clientInfo(c))
 	if err != nil {
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerLoginMethod), log.TraceError(commonLogFields, err)...)
-		return c.Status(fiber.StatusUnauthorized).JSON(err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerLoginMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
 	}
 
 	return c.Status(fiber.StatusOK).JSON(response)
@@ -104,6 +131,36 @@ func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusOK).JSON(response)
 }
 
+// GetPublicProfile returns the public profile of a seller with their published listings
+func (h *UserHandler) GetPublicProfile(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerPublicProfileMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerPublicProfileMethod), commonLogFields...)
+
+	// Get user ID from params
+	userID, err := GetIDFromParams(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerPublicProfileMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	// Get the requested page of listings
+	request, err := validator.ValidatePageRequest(h.handlerContext.RequestID, c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerPublicProfileMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	// Get public profile
+	response, err := h.userSvc.GetPublicProfile(userID, request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerPublicProfileMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
 // UpdateProfile updates user profile
 func (h *UserHandler) UpdateProfile(c *fiber.Ctx) error {
 	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
@@ -141,8 +198,8 @@ func (h *UserHandler) UpdatePassword(c *fiber.Ctx) error {
 	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerUpdatePasswordMethod), commonLogFields...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerUpdatePasswordMethod), commonLogFields...)
 
-	// Get user ID from context
-	userID, err := GetUserIDFromContext(c)
+	// Get principal from context
+	principal, err := GetPrincipalFromContext(c)
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerUpdatePasswordMethod), log.TraceError(commonLogFields, err)...)
 		return c.Status(fiber.StatusUnauthorized).JSON(err)
@@ -157,7 +214,7 @@ func (h *UserHandler) UpdatePassword(c *fiber.Ctx) error {
 	}
 
 	// Update password
-	err = h.userSvc.UpdatePassword(userID, &request)
+	err = h.userSvc.UpdatePassword(principal.ID, principal.SessionID, &request)
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerUpdatePasswordMethod), log.TraceError(commonLogFields, err)...)
 		return c.Status(fiber.StatusInternalServerError).JSON(err)
@@ -165,3 +222,413 @@ func (h *UserHandler) UpdatePassword(c *fiber.Ctx) error {
 
 	return c.SendStatus(fiber.StatusOK)
 }
+
+// RefreshToken exchanges a refresh token for a new access and refresh token pair
+func (h *UserHandler) RefreshToken(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerRefreshTokenMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerRefreshTokenMethod), commonLogFields...)
+
+	// Parse request
+	var request dto.RefreshTokenRequest
+	if err := c.BodyParser(&request); err != nil || request.RefreshToken == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRefreshTokenMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Rotate refresh token
+	response, err := h.userSvc.RefreshToken(&request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRefreshTokenMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// Logout revokes the given refresh token
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerLogoutMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerLogoutMethod), commonLogFields...)
+
+	// Parse request
+	var request dto.LogoutRequest
+	if err := c.BodyParser(&request); err != nil || request.RefreshToken == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerLogoutMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Revoke refresh token
+	err := h.userSvc.Logout(&request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerLogoutMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ForgotPassword emails a password reset link. It always answers 202 so the
+// response does not reveal whether the email is registered.
+func (h *UserHandler) ForgotPassword(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerForgotPasswordMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerForgotPasswordMethod), commonLogFields...)
+
+	// Parse request
+	var request dto.ForgotPasswordRequest
+	if err := c.BodyParser(&request); err != nil || request.Email == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerForgotPasswordMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Send reset link
+	h.userSvc.ForgotPassword(&request)
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ResetPassword sets a new password with a password reset token
+func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerResetPasswordMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerResetPasswordMethod), commonLogFields...)
+
+	// Parse request
+	var request dto.ResetPasswordRequest
+	if err := c.BodyParser(&request); err != nil || request.Token == constant.Empty || len(request.NewPassword) < constant.MinPasswordLength {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerResetPasswordMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Reset password
+	err := h.userSvc.ResetPassword(&request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerResetPasswordMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// VerifyEmail confirms an email address with a verification token
+func (h *UserHandler) VerifyEmail(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerVerifyEmailMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerVerifyEmailMethod), commonLogFields...)
+
+	// Parse request
+	var request dto.VerifyEmailRequest
+	if err := c.BodyParser(&request); err != nil || request.Token == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerVerifyEmailMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Verify email
+	err := h.userSvc.VerifyEmail(&request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerVerifyEmailMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendVerification emails a new verification link to the authenticated user
+func (h *UserHandler) ResendVerification(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerResendVerifyMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerResendVerifyMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerResendVerifyMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// Resend verification email
+	err = h.userSvc.ResendVerification(userID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerResendVerifyMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// SendPhoneVerification texts a verification code to the authenticated user's phone number
+func (h *UserHandler) SendPhoneVerification(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerSendPhoneCodeMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerSendPhoneCodeMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerSendPhoneCodeMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// Send verification code
+	err = h.userSvc.SendPhoneVerification(userID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerSendPhoneCodeMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// VerifyPhone confirms the authenticated user's phone number with an SMS code
+func (h *UserHandler) VerifyPhone(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerVerifyPhoneMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerVerifyPhoneMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerVerifyPhoneMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// Parse request
+	var request dto.VerifyPhoneRequest
+	if err := c.BodyParser(&request); err != nil || request.Code == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerVerifyPhoneMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Verify phone number
+	err = h.userSvc.VerifyPhone(userID, &request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerVerifyPhoneMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UnlockAccount lifts a login lockout with the token of an unlock email
+func (h *UserHandler) UnlockAccount(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerUnlockAccountMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerUnlockAccountMethod), commonLogFields...)
+
+	// Parse request
+	var request dto.UnlockAccountRequest
+	if err := c.BodyParser(&request); err != nil || request.Token == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerUnlockAccountMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Unlock account
+	err := h.userSvc.UnlockAccount(&request, c.IP())
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerUnlockAccountMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListSessions lists the devices the authenticated user is signed in on
+func (h *UserHandler) ListSessions(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerListSessionsMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerListSessionsMethod), commonLogFields...)
+
+	// Get principal from context
+	principal, err := GetPrincipalFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerListSessionsMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// List sessions
+	response, err := h.userSvc.ListSessions(principal.ID, principal.SessionID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerListSessionsMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(response)
+}
+
+// RevokeSession signs the authenticated user out of one session
+func (h *UserHandler) RevokeSession(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerRevokeSessionMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerRevokeSessionMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRevokeSessionMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// Get session ID from params
+	sessionID, err := GetIDFromParams(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRevokeSessionMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	// Revoke session
+	err = h.userSvc.RevokeSession(userID, sessionID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRevokeSessionMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RevokeAllSessions signs the authenticated user out everywhere
+func (h *UserHandler) RevokeAllSessions(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerRevokeAllMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerRevokeAllMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRevokeAllMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// Revoke all sessions
+	err = h.userSvc.RevokeAllSessions(userID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerRevokeAllMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExportData downloads the personal data of the authenticated user as a JSON file,
+// or as a ZIP archive with one JSON file per section when format=zip
+func (h *UserHandler) ExportData(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerExportDataMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerExportDataMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerExportDataMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	format := c.Query("format", exportFormatJSON)
+	if format != exportFormatJSON && format != exportFormatZIP {
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.ErrInvalidExportFormatMsg, "format")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Collect user data
+	response, err := h.userSvc.ExportData(userID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerExportDataMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	fileName := fmt.Sprintf("user-%d-export.%s", userID, format)
+	if format == exportFormatJSON {
+		c.Attachment(fileName)
+		return c.Status(fiber.StatusOK).JSON(response)
+	}
+
+	archive, archiveErr := buildExportArchive(response)
+	if archiveErr != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerExportDataMethod), log.TraceError(commonLogFields, archiveErr)...)
+		errRes := custom.BuildInternalServerErrResult(constant.ErrDataMarshalCode, constant.UnexpectedWhenMarshalError, "Export")
+		return c.Status(fiber.StatusInternalServerError).JSON(errRes)
+	}
+
+	c.Attachment(fileName)
+	return c.Status(fiber.StatusOK).Send(archive)
+}
+
+// DeleteAccount anonymises the account of the authenticated user
+func (h *UserHandler) DeleteAccount(c *fiber.Ctx) error {
+	commonLogFields := log.CommonLogField(h.handlerContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserHandlerDeleteAccountMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserHandlerDeleteAccountMethod), commonLogFields...)
+
+	// Get user ID from context
+	userID, err := GetUserIDFromContext(c)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerDeleteAccountMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(fiber.StatusUnauthorized).JSON(err)
+	}
+
+	// Parse request
+	var request dto.DeleteAccountRequest
+	if err := c.BodyParser(&request); err != nil || request.Password == constant.Empty {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerDeleteAccountMethod), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.BindingErrorMessage, "Request")
+		return c.Status(fiber.StatusBadRequest).JSON(errRes)
+	}
+
+	// Delete account
+	err = h.userSvc.DeleteAccount(userID, &request)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserHandlerDeleteAccountMethod), log.TraceCustomError(commonLogFields, *err)...)
+		return c.Status(err.StatusCode).JSON(err)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// buildExportArchive zips a personal data export, one JSON file per section
+func buildExportArchive(export *dto.UserDataExport) ([]byte, error) {
+	sections := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"roles.json", export.Roles},
+		{"properties.json", export.Properties},
+		{"favorites.json", export.Favorites},
+		{"sessions.json", export.Sessions},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, section := range sections {
+		file, err := archive.CreateHeader(&zip.FileHeader{Name: section.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err = encoder.Encode(section.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// clientInfo collects the client details recorded on a new session
+func clientInfo(c *fiber.Ctx) dto.ClientInfo {
+	return dto.ClientInfo{
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
@@ -137,3 +137,18 @@ func ValidateDateTrendRequest(requestID string, ctx *fiber.Ctx) (dto.DateTrendRe
 
 	return request, nil
 }
+
+// ValidatePageRequest used to bind and validate the page size and cursor of a paged list
+func ValidatePageRequest(requestID string, ctx *fiber.Ctx) (dto.PageRequest, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ValidatePageRequestMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ValidatePageRequestMethod), commonLogFields...)
+
+	request, errRes := GenericQueryValidator[dto.PageRequest](requestID, ctx)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(GenericQueryValidatorMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return request, errRes
+	}
+
+	return request, nil
+}
@@ -75,6 +75,10 @@ func RegisterCustomValidation(validate *validator.Validate) {
 	validate.RegisterValidation(oneOfSortOrders, func(f1 validator.FieldLevel) bool {
 		return validateOneOf(f1.Field().String(), validSortOrders)
 	})
+
+	validate.RegisterValidation(singleLine, func(fl validator.FieldLevel) bool {
+		return containsOnly(fl.Field().String(), controlCharacterRegex)
+	})
 }
 
 // RegisterCustomTranslation use add custom validator translation
@@ -169,4 +173,11 @@ func RegisterCustomTranslation(validate *validator.Validate, trans ut.Translator
 		t, _ := ut.T(oneOfSortOrders, fe.Field())
 		return t
 	})
+
+	validate.RegisterTranslation(singleLine, trans, func(ut ut.Translator) error {
+		return ut.Add(singleLine, "{0} must not contain line breaks or control characters", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T(singleLine, fe.Field())
+		return t
+	})
 }
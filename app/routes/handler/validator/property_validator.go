@@ -0,0 +1,173 @@
+package validator
+
+import (
+	"fmt"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/geo"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ValidatePropertyFilterRequest used to bind and validate the property list query
+func ValidatePropertyFilterRequest(requestID string, ctx *fiber.Ctx) (dto.PropertyFilterRequest, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ValidatePropertyFilterRequestMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ValidatePropertyFilterRequestMethod), commonLogFields...)
+
+	request, errRes := GenericQueryValidator[dto.PropertyFilterRequest](requestID, ctx, ValidatePropertyFilterRanges, ValidatePropertyFilterLocation)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(GenericQueryValidatorMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return request, errRes
+	}
+
+	return request, nil
+}
+
+// ValidatePropertyMapRequest used to bind and validate the map view query
+func ValidatePropertyMapRequest(requestID string, ctx *fiber.Ctx) (dto.PropertyMapRequest, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ValidatePropertyMapRequestMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ValidatePropertyMapRequestMethod), commonLogFields...)
+
+	request, errRes := GenericQueryValidator[dto.PropertyMapRequest](requestID, ctx, ValidatePropertyFilterRanges, ValidatePropertyFilterLocation, ValidatePropertyMapViewport)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(GenericQueryValidatorMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return request, errRes
+	}
+
+	return request, nil
+}
+
+// ValidatePropertyMapViewport used to validate that the map viewport is given
+func ValidatePropertyMapViewport(commonLogFields []zap.Field, request any) *custom.ErrorResult {
+	r, errRes := utils.StructCastor[dto.PropertyMapRequest](commonLogFields, request)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(StructCasterMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return errRes
+	}
+
+	if r.MinLat == nil && r.MaxLat == nil && r.MinLng == nil && r.MaxLng == nil {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidBoundsCode, constant.ViewportRequiredMessage, constant.Empty)
+		return &errRes
+	}
+
+	return nil
+}
+
+// ValidatePropertyFilterRanges used to validate that the price and size minimums do not exceed their maximums
+func ValidatePropertyFilterRanges(commonLogFields []zap.Field, request any) *custom.ErrorResult {
+	r, errRes := utils.StructCastor[dto.PropertyFilterRequest](commonLogFields, request)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(StructCasterMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return errRes
+	}
+
+	errResult := &custom.ErrorResult{}
+	if r.MinPrice != nil && r.MaxPrice != nil && *r.MinPrice > *r.MaxPrice {
+		errResult.ErrorList = append(errResult.ErrorList, invalidRangeErr("price").ErrorList...)
+	}
+	if r.MinSize != nil && r.MaxSize != nil && *r.MinSize > *r.MaxSize {
+		errResult.ErrorList = append(errResult.ErrorList, invalidRangeErr("size").ErrorList...)
+	}
+
+	if len(errResult.ErrorList) > 0 {
+		errRes := custom.BuildBadReqErrResultWithList(errResult.ErrorList...)
+		return &errRes
+	}
+
+	return nil
+}
+
+// ValidatePropertyFilterLocation used to validate the point, viewport and polygon of a location search
+func ValidatePropertyFilterLocation(commonLogFields []zap.Field, request any) *custom.ErrorResult {
+	r, errRes := utils.StructCastor[dto.PropertyFilterRequest](commonLogFields, request)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(StructCasterMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return errRes
+	}
+
+	var errList []custom.ErrorInfo
+	hasPoint := r.Latitude != nil && r.Longitude != nil
+	switch {
+	case (r.Latitude == nil) != (r.Longitude == nil):
+		errRes := custom.BuildBadReqErrResult(constant.InvalidLocationCode, constant.IncompletePointMessage, constant.Empty)
+		errList = append(errList, errRes.ErrorList...)
+	case !hasPoint && r.RadiusKm != nil:
+		errList = append(errList, locationRequiredErr("radius_km").ErrorList...)
+	case !hasPoint && r.SortBy == constant.PropertySortDistance:
+		errList = append(errList, locationRequiredErr("sort_by=distance").ErrorList...)
+	}
+
+	bounds := []*float64{r.MinLat, r.MaxLat, r.MinLng, r.MaxLng}
+	given := 0
+	for _, bound := range bounds {
+		if bound != nil {
+			given++
+		}
+	}
+	if given != 0 && given != len(bounds) {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidBoundsCode, constant.IncompleteBoundsMessage, constant.Empty)
+		errList = append(errList, errRes.ErrorList...)
+	} else if given == len(bounds) && *r.MinLat > *r.MaxLat {
+		// min_lng may exceed max_lng for a viewport crossing the antimeridian
+		errList = append(errList, invalidRangeErr("lat").ErrorList...)
+	}
+
+	if r.Polygon != "" {
+		if _, err := geo.ParsePolygon(r.Polygon); err != nil {
+			errRes := custom.BuildBadReqErrResult(constant.InvalidPolygonCode, fmt.Sprintf(constant.InvalidPolygonMessage, geo.MinPolygonPoints, geo.MaxPolygonPoints), err.Error())
+			errList = append(errList, errRes.ErrorList...)
+		}
+	}
+
+	if len(errList) > 0 {
+		errRes := custom.BuildBadReqErrResultWithList(errList...)
+		return &errRes
+	}
+
+	return nil
+}
+
+func locationRequiredErr(field string) custom.ErrorResult {
+	return custom.BuildBadReqErrResult(constant.InvalidLocationCode, fmt.Sprintf(constant.LocationRequiredMessage, field), constant.Empty)
+}
+
+func invalidRangeErr(field string) custom.ErrorResult {
+	return custom.BuildBadReqErrResult(constant.InvalidRangeCode, fmt.Sprintf(constant.InvalidRangeMessage, field, field), constant.Empty)
+}
+
+// ValidateMyPropertiesRequest used to bind and validate the query listing the properties of the signed in user
+func ValidateMyPropertiesRequest(requestID string, ctx *fiber.Ctx) (dto.MyPropertiesRequest, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ValidateMyPropertiesRequestMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ValidateMyPropertiesRequestMethod), commonLogFields...)
+
+	request, errRes := GenericQueryValidator[dto.MyPropertiesRequest](requestID, ctx)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(GenericQueryValidatorMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return request, errRes
+	}
+
+	return request, nil
+}
+
+// ValidatePropertyRevisionDiffRequest used to bind and validate the query naming the two revisions to compare
+func ValidatePropertyRevisionDiffRequest(requestID string, ctx *fiber.Ctx) (dto.PropertyRevisionDiffRequest, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(requestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(ValidatePropertyRevisionDiffRequestMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(ValidatePropertyRevisionDiffRequestMethod), commonLogFields...)
+
+	request, errRes := GenericQueryValidator[dto.PropertyRevisionDiffRequest](requestID, ctx)
+	if errRes != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(GenericQueryValidatorMethod), log.TraceCustomError(commonLogFields, *errRes)...)
+		return request, errRes
+	}
+
+	return request, nil
+}
@@ -81,6 +81,49 @@ func GenericValidator[T any](requestID string, ctx *fiber.Ctx, validators ...fun
 	return request, nil
 }
 
+// GenericQueryValidator is a generic function that binds the query string into a request and validates it with custome validators.
+func GenericQueryValidator[T any](requestID string, ctx *fiber.Ctx, validators ...func([]zap.Field, any) *custom.ErrorResult) (T, *custom.ErrorResult) {
+	commonLogFields := []zap.Field{zap.String(constant.TraceMsgReqID, requestID)}
+	log.Logger.Debug(log.TraceMsgFuncStart(GenericQueryValidatorMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(GenericQueryValidatorMethod), commonLogFields...)
+
+	var (
+		request T
+		query   = string(ctx.Request().URI().QueryString())
+		err     error
+	)
+
+	err = ctx.QueryParser(&request)
+	if err != nil {
+		log.Logger.Error(constant.InvalidInputAndPassErr, append(commonLogFields, []zap.Field{zap.String(constant.ErrorRequestBody, query), zap.Error(err)}...)...)
+		errorResult := custom.BuildBadReqErrResult(constant.BindingErrorCode, constant.InvalidRequestErrorMessage, err.Error())
+		return request, &errorResult
+	}
+
+	err = validate.Struct(&request)
+	if err != nil {
+		log.Logger.Error(constant.InvalidInputAndPassErr, append(commonLogFields, []zap.Field{zap.String(constant.ErrorRequestBody, query), zap.Error(err)}...)...)
+		return request, BuildValidationErrorResponse(requestID, err)
+	}
+
+	errResult := &custom.ErrorResult{}
+	for _, validator := range validators {
+		errRes := validator(commonLogFields, request)
+		if errRes != nil {
+			logFields := log.TraceCustomError(commonLogFields, *errRes)
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(customValidators), logFields...)
+			errResult.ErrorList = append(errResult.ErrorList, errRes.ErrorList...)
+		}
+	}
+
+	if len(errResult.ErrorList) > 0 {
+		errRes := custom.BuildBadReqErrResultWithList(errResult.ErrorList...)
+		return request, &errRes
+	}
+
+	return request, nil
+}
+
 // ValidateDateRange is a function that validates the date range.
 func ValidateDateRange(commonLogFields []zapcore.Field, startDate, endDate string) *custom.ErrorResult {
 	log.Logger.Debug(log.TraceMsgFuncStart(ValidateDataRangeMethod), commonLogFields...)
@@ -11,6 +11,7 @@ const (
 	yearRegex                                = `^(19\d\d|20\d\d)$`
 	timestampRegex                           = `^(\d{4})-(0[1-9]|1[0-2])-(0[1-9]|[1-2][0-9]|3[0-1])T([01][0-9]|2[0-3]):([0-5][0-9]):([0-5][0-9])Z`
 	dateRegex                                = `^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])$`
+	controlCharacterRegex                    = `[\x00-\x1f\x7f]`
 )
 
 // Validator keys
@@ -37,6 +38,7 @@ const (
 	omitEmpty                   = "omitEmpty"
 	oneOfSortFields             = "oneOfSortFields"
 	oneOfSortOrders             = "oneOfSortOrders"
+	singleLine                  = "singleLine"
 )
 
 // Methods
@@ -44,6 +46,12 @@ const (
 	CustomValidators                           = "CustomValidators"
 	GenericBaseValidatorMethod                 = "GenericBaseValidator"
 	GenericValidatorMethod                     = "GenericValidator"
+	GenericQueryValidatorMethod                = "GenericQueryValidator"
+	ValidatePropertyFilterRequestMethod        = "ValidatePropertyFilterRequest"
+	ValidatePropertyMapRequestMethod           = "ValidatePropertyMapRequest"
+	ValidatePageRequestMethod                  = "ValidatePageRequest"
+	ValidateMyPropertiesRequestMethod          = "ValidateMyPropertiesRequest"
+	ValidatePropertyRevisionDiffRequestMethod  = "ValidatePropertyRevisionDiffRequest"
 	BuildValidationErrorResponseMethod         = "BuildValidationErrorResponse"
 	ValidateCommonRequestMethod                = "ValidateCommonRequest"
 	validateStartAndEndDateMethod              = "validateStartAndEndDate"
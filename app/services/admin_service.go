@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"runtime/debug"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Admin service methods
+	AdminServiceListRolesMethod     = "AdminServiceListRoles"
+	AdminServiceAssignRolesMethod   = "AdminServiceAssignRoles"
+	AdminServiceSuspendUserMethod   = "AdminServiceSuspendUser"
+	AdminServiceUnsuspendUserMethod = "AdminServiceUnsuspendUser"
+	AdminServiceCreateLookupMethod  = "AdminServiceCreateLookup"
+	AdminServiceUpdateLookupMethod  = "AdminServiceUpdateLookup"
+	AdminServiceDeleteLookupMethod  = "AdminServiceDeleteLookup"
+)
+
+// AdminService handles administrative operations. Every method checks the
+// permission of the calling principal, independent of the route it is called from.
+type AdminService struct {
+	_              struct{}
+	serviceContext ServiceContext
+	transaction    *gorm.DB
+	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	sessionRepo    repository.SessionRepository
+	lookupRepo     repository.LookupRepository
+}
+
+// CreateAdminService creates a new instance of AdminService
+func CreateAdminService(requestID string, transactionDB *gorm.DB) *AdminService {
+	return &AdminService{
+		serviceContext: CreateServiceContext(requestID),
+		transaction:    transactionDB,
+	}
+}
+
+// ListRoles lists all roles with their permissions
+func (service *AdminService) ListRoles(principal *auth.Principal) (response []internaldto.Role, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceListRolesMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceListRolesMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceListRolesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageUsers); errResult != nil {
+		return nil, errResult
+	}
+
+	service.roleRepo = createRoleRepository(service.serviceContext.RequestID)
+
+	response, err := service.roleRepo.List()
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.RoleRepositoryListMethod), logFields...)
+		return nil, buildSelectErrFromRepo("roles", err)
+	}
+
+	return response, nil
+}
+
+// AssignRoles replaces the roles of a user
+func (service *AdminService) AssignRoles(principal *auth.Principal, userID uint, request dto.AssignRolesRequest) (response []internaldto.Role, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceAssignRolesMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceAssignRolesMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceAssignRolesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageUsers); errResult != nil {
+		return nil, errResult
+	}
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.roleRepo = createRoleRepository(service.serviceContext.RequestID)
+
+	if errResult = service.checkUserExists(userID); errResult != nil {
+		return nil, errResult
+	}
+
+	roles, err := service.roleRepo.GetByNames(request.Roles)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.RoleRepositoryGetByNamesMethod), logFields...)
+		return nil, buildSelectErrFromRepo("roles", err)
+	}
+
+	// Every requested role must exist
+	roleIDs := make([]uint, 0, len(roles))
+	known := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+		known[role.Name] = true
+	}
+	for _, name := range request.Roles {
+		if !known[name] {
+			errRes := custom.BuildBadReqErrResult(constant.RoleNotFoundCode, constant.RoleNotFoundMessage, name)
+			return nil, &errRes
+		}
+	}
+
+	err = service.roleRepo.SetUserRoles(userID, roleIDs)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.RoleRepositorySetUserRolesMethod), logFields...)
+		return nil, buildUpdateErrFromRepo("user roles", err)
+	}
+
+	return roles, nil
+}
+
+// SuspendUser suspends a user and revokes all of their sessions
+func (service *AdminService) SuspendUser(principal *auth.Principal, userID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceSuspendUserMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceSuspendUserMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceSuspendUserMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageUsers); errResult != nil {
+		return errResult
+	}
+	if principal.ID == userID {
+		errRes := custom.BuildBadReqErrResult(constant.ErrCodeInvalidInput, "administrators cannot suspend themselves", "User")
+		return &errRes
+	}
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	now := time.Now()
+	if errResult = service.setSuspendedAt(userID, &now); errResult != nil {
+		return errResult
+	}
+
+	err := service.sessionRepo.RevokeAllForUser(userID, 0)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryRevokeAllForUserMethod), logFields...)
+		return buildUpdateErrFromRepo("user sessions", err)
+	}
+
+	return nil
+}
+
+// UnsuspendUser lifts the suspension of a user
+func (service *AdminService) UnsuspendUser(principal *auth.Principal, userID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceUnsuspendUserMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceUnsuspendUserMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceUnsuspendUserMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageUsers); errResult != nil {
+		return errResult
+	}
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	return service.setSuspendedAt(userID, nil)
+}
+
+// CreateLookup adds a value to a lookup table
+func (service *AdminService) CreateLookup(principal *auth.Principal, lookupType string, request dto.LookupRequest) (response *dto.LookupResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceCreateLookupMethod), log.TraceMethodInputs(commonLogFields, lookupType, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceCreateLookupMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceCreateLookupMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageLookups); errResult != nil {
+		return nil, errResult
+	}
+
+	table, errResult := getLookupTable(lookupType)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
+
+	response, err := service.lookupRepo.Create(table, request.Name)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.LookupRepositoryCreateMethod), logFields...)
+		return nil, buildInsertErrFromRepo(lookupType, err)
+	}
+
+	return response, nil
+}
+
+// UpdateLookup renames a value of a lookup table
+func (service *AdminService) UpdateLookup(principal *auth.Principal, lookupType string, id uint, request dto.LookupRequest) (response *dto.LookupResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceUpdateLookupMethod), log.TraceMethodInputs(commonLogFields, lookupType, id, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceUpdateLookupMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceUpdateLookupMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageLookups); errResult != nil {
+		return nil, errResult
+	}
+
+	table, errResult := getLookupTable(lookupType)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
+
+	response, err := service.lookupRepo.Update(table, id, request.Name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.LookupNotFoundCode, constant.LookupNotFoundMessage, lookupType)
+			return nil, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.LookupRepositoryUpdateMethod), logFields...)
+		return nil, buildUpdateErrFromRepo(lookupType, err)
+	}
+
+	return response, nil
+}
+
+// DeleteLookup removes a value from a lookup table
+func (service *AdminService) DeleteLookup(principal *auth.Principal, lookupType string, id uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AdminServiceDeleteLookupMethod), log.TraceMethodInputs(commonLogFields, lookupType, id)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AdminServiceDeleteLookupMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AdminServiceDeleteLookupMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionManageLookups); errResult != nil {
+		return errResult
+	}
+
+	table, errResult := getLookupTable(lookupType)
+	if errResult != nil {
+		return errResult
+	}
+
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
+
+	err := service.lookupRepo.Delete(table, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.LookupNotFoundCode, constant.LookupNotFoundMessage, lookupType)
+			return &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.LookupRepositoryDeleteMethod), logFields...)
+		return buildDeleteErrFromRepo(lookupType, err)
+	}
+
+	return nil
+}
+
+func (service *AdminService) checkUserExists(userID uint) *custom.ErrorResult {
+	_, err := service.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.UserNotFoundCode, constant.UserNotFoundMessage, "User")
+			return &errRes
+		}
+		return buildSelectErrFromRepo("user", err)
+	}
+
+	return nil
+}
+
+func (service *AdminService) setSuspendedAt(userID uint, suspendedAt *time.Time) *custom.ErrorResult {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+
+	err := service.userRepo.SetSuspendedAt(userID, suspendedAt)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.UserNotFoundCode, constant.UserNotFoundMessage, "User")
+			return &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.UserRepositorySetSuspendedAtMethod), logFields...)
+		return buildUpdateErrFromRepo("user", err)
+	}
+
+	return nil
+}
+
+func getLookupTable(lookupType string) (string, *custom.ErrorResult) {
+	table, ok := repository.LookupTables[lookupType]
+	if !ok {
+		errRes := custom.BuildNotFoundErrResult(constant.LookupNotFoundCode, constant.LookupNotFoundMessage, lookupType)
+		return constant.Empty, &errRes
+	}
+
+	return table, nil
+}
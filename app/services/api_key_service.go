@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// API key service methods
+	APIKeyServiceCreateMethod = "APIKeyServiceCreate"
+	APIKeyServiceListMethod   = "APIKeyServiceList"
+	APIKeyServiceRevokeMethod = "APIKeyServiceRevoke"
+
+	// apiKeyScopeSeparator joins the scopes of a key in the api_keys table
+	apiKeyScopeSeparator = ","
+	// apiKeyDisplayLength is how much of a key is kept to tell keys apart
+	apiKeyDisplayLength = 12
+)
+
+// APIKeyService manages the API keys partner integrations authenticate with
+type APIKeyService struct {
+	_              struct{}
+	serviceContext ServiceContext
+	transaction    *gorm.DB
+	apiKeyRepo     repository.APIKeyRepository
+}
+
+// CreateAPIKeyService creates a new instance of APIKeyService
+func CreateAPIKeyService(requestID string, transactionDB *gorm.DB) *APIKeyService {
+	return &APIKeyService{
+		serviceContext: CreateServiceContext(requestID),
+		transaction:    transactionDB,
+	}
+}
+
+// Create issues a new API key for the user. The returned key cannot be retrieved again.
+func (service *APIKeyService) Create(userID uint, request dto.CreateAPIKeyRequest) (response *dto.CreateAPIKeyResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyServiceCreateMethod), log.TraceMethodInputs(commonLogFields, userID, request.Name, request.Scopes)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(APIKeyServiceCreateMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyServiceCreateMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.apiKeyRepo = createAPIKeyRepository(service.serviceContext.RequestID)
+
+	var scopes []string
+	for _, scope := range request.Scopes {
+		if !slices.Contains(auth.APIKeyScopes, scope) {
+			errRes := custom.BuildBadReqErrResult(constant.InvalidScopeCode, fmt.Sprintf(constant.InvalidScopeMessage, scope), "Scopes")
+			return nil, &errRes
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidExpiryCode, constant.InvalidExpiryMessage, "ExpiresAt")
+		return nil, &errRes
+	}
+
+	key, keyHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhen("generating api key"), log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.UnexpectedErrorCode, fmt.Sprintf(constant.UnexpectedErrorMessage, APIKeyServiceCreateMethod), err.Error())
+		return nil, &errRes
+	}
+
+	apiKey := &internaldto.APIKey{
+		UserID:    userID,
+		Name:      request.Name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   keyHash,
+		Scopes:    strings.Join(scopes, apiKeyScopeSeparator),
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err = service.apiKeyRepo.Create(apiKey); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.APIKeyRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildInsertErrFromRepo("api key", err)
+	}
+
+	response = &dto.CreateAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(apiKey),
+		Key:            key,
+	}
+
+	return response, nil
+}
+
+// List lists the active API keys of the user
+func (service *APIKeyService) List(userID uint) (response []dto.APIKeyResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyServiceListMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(APIKeyServiceListMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyServiceListMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.apiKeyRepo = createAPIKeyRepository(service.serviceContext.RequestID)
+
+	apiKeys, err := service.apiKeyRepo.ListByUserID(userID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.APIKeyRepositoryListMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildSelectErrFromRepo("api keys", err)
+	}
+
+	response = make([]dto.APIKeyResponse, 0, len(apiKeys))
+	for i := range apiKeys {
+		response = append(response, toAPIKeyResponse(&apiKeys[i]))
+	}
+
+	return response, nil
+}
+
+// Revoke revokes an API key of the user
+func (service *APIKeyService) Revoke(userID, apiKeyID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(APIKeyServiceRevokeMethod), log.TraceMethodInputs(commonLogFields, userID, apiKeyID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(APIKeyServiceRevokeMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(APIKeyServiceRevokeMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.apiKeyRepo = createAPIKeyRepository(service.serviceContext.RequestID)
+
+	err := service.apiKeyRepo.Revoke(userID, apiKeyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.APIKeyNotFoundCode, constant.APIKeyNotFoundMessage, "APIKey")
+			return &errRes
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.APIKeyRepositoryRevokeMethod), log.TraceError(commonLogFields, err)...)
+		return buildUpdateErrFromRepo("api key", err)
+	}
+
+	return nil
+}
+
+// toAPIKeyResponse maps a stored API key to its response, without the secret
+func toAPIKeyResponse(apiKey *internaldto.APIKey) dto.APIKeyResponse {
+	return dto.APIKeyResponse{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     strings.Split(apiKey.Scopes, apiKeyScopeSeparator),
+		LastUsedAt: apiKey.LastUsedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
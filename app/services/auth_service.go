@@ -0,0 +1,228 @@
+package services
+
+import (
+	"errors"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Auth service methods
+	AuthServiceResolvePrincipalMethod = "AuthServiceResolvePrincipal"
+	AuthServiceResolveAPIKeyMethod    = "AuthServiceResolveAPIKey"
+	buildPrincipalMethod              = "buildPrincipal"
+)
+
+// AuthService maps verified token identities to users
+type AuthService struct {
+	_              struct{}
+	serviceContext ServiceContext
+	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	sessionRepo    repository.SessionRepository
+	apiKeyRepo     repository.APIKeyRepository
+}
+
+// CreateAuthService creates a new instance of AuthService
+func CreateAuthService(requestID string) *AuthService {
+	return &AuthService{
+		serviceContext: CreateServiceContext(requestID),
+	}
+}
+
+// ResolvePrincipal is an auth.PrincipalResolver backed by the users table
+func ResolvePrincipal(requestID string, identity *auth.Identity) (*auth.Principal, *custom.ErrorResult) {
+	return CreateAuthService(requestID).ResolvePrincipal(identity)
+}
+
+// ResolveAPIKey is an auth.APIKeyResolver backed by the api_keys table
+func ResolveAPIKey(requestID, key string) (*auth.Principal, *custom.ErrorResult) {
+	return CreateAuthService(requestID).ResolveAPIKey(key)
+}
+
+// ResolvePrincipal looks up the user of a verified identity. Tokens issued by this
+// service carry the users.id, external providers are matched on the verified email.
+func (service *AuthService) ResolvePrincipal(identity *auth.Identity) (principal *auth.Principal, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AuthServiceResolvePrincipalMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AuthServiceResolvePrincipalMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AuthServiceResolvePrincipalMethod), log.TraceMethodOutputs(commonLogFields, principal, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.roleRepo = createRoleRepository(service.serviceContext.RequestID)
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	var (
+		user *internaldto.User
+		err  error
+	)
+	switch {
+	case identity.UserID != 0:
+		user, err = service.userRepo.GetUserByID(identity.UserID)
+	case identity.Email != constant.Empty:
+		user, err = service.userRepo.GetUserByEmail(identity.Email)
+	default:
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildUnauthorizedErrResult(constant.UserNotFoundCode, constant.UserNotFoundMessage, "Authorization")
+			return nil, &errRes
+		}
+		return nil, checkRepoError(commonLogFields, AuthServiceResolvePrincipalMethod, err)
+	}
+
+	// A password reset logs out every access token issued before it
+	if user.PasswordChangedAt != nil && !identity.IssuedAt.IsZero() &&
+		identity.IssuedAt.Before(user.PasswordChangedAt.Truncate(time.Second)) {
+		errRes := custom.BuildUnauthorizedErrResult(constant.ErrInvalidTokenCode, constant.ErrTokenBeforeResetMsg, "Authorization")
+		return nil, &errRes
+	}
+
+	// Access tokens of a signed out session stop working before they expire
+	if identity.SessionID != 0 {
+		session, err := service.sessionRepo.GetByID(identity.SessionID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, checkRepoError(commonLogFields, repository.SessionRepositoryGetByIDMethod, err)
+		}
+		if session == nil || session.UserID != user.ID || session.RevokedAt != nil || session.ExpiresAt.Before(time.Now()) {
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrSessionRevokedCode, constant.ErrSessionRevokedMsg, "Authorization")
+			return nil, &errRes
+		}
+		if err = service.sessionRepo.Touch(session.ID, nil); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryTouchMethod), log.TraceError(commonLogFields, err)...)
+		}
+	}
+
+	principal, errResult = service.buildPrincipal(user)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	// Firebase only reports an email once the provider has verified it
+	principal.SessionID = identity.SessionID
+	principal.EmailVerified = principal.EmailVerified || (identity.Provider == auth.ProviderFirebase && identity.Email != constant.Empty)
+
+	return principal, nil
+}
+
+// ResolveAPIKey looks up the owner of an API key. The principal carries the
+// permissions of the owner, narrowed by the scopes of the key.
+func (service *AuthService) ResolveAPIKey(key string) (principal *auth.Principal, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(AuthServiceResolveAPIKeyMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(AuthServiceResolveAPIKeyMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(AuthServiceResolveAPIKeyMethod), log.TraceMethodOutputs(commonLogFields, principal, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.roleRepo = createRoleRepository(service.serviceContext.RequestID)
+	service.apiKeyRepo = createAPIKeyRepository(service.serviceContext.RequestID)
+
+	invalidKeyErr := custom.BuildUnauthorizedErrResult(constant.ErrInvalidAPIKeyCode, constant.ErrInvalidAPIKeyMsg, constant.APIKeyHeader)
+
+	apiKey, err := service.apiKeyRepo.GetByHash(auth.HashToken(key))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &invalidKeyErr
+		}
+		return nil, checkRepoError(commonLogFields, repository.APIKeyRepositoryGetByHashMethod, err)
+	}
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && apiKey.ExpiresAt.Before(time.Now())) {
+		return nil, &invalidKeyErr
+	}
+
+	user, err := service.userRepo.GetUserByID(apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &invalidKeyErr
+		}
+		return nil, checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+
+	principal, errResult = service.buildPrincipal(user)
+	if errResult != nil {
+		return nil, errResult
+	}
+	principal.APIKeyID = apiKey.ID
+	principal.Scopes = strings.Split(apiKey.Scopes, apiKeyScopeSeparator)
+
+	if err = service.apiKeyRepo.Touch(apiKey.ID); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.APIKeyRepositoryTouchMethod), log.TraceError(commonLogFields, err)...)
+	}
+
+	return principal, nil
+}
+
+// buildPrincipal turns an active user into a principal with the permissions of their
+// roles. Deleted and suspended users are rejected.
+func (service *AuthService) buildPrincipal(user *internaldto.User) (*auth.Principal, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(buildPrincipalMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(buildPrincipalMethod), commonLogFields...)
+
+	if errResult := checkAccountActive(user); errResult != nil {
+		return nil, errResult
+	}
+
+	// Users without an assigned role are members
+	roles, err := service.roleRepo.GetUserRoles(user.ID)
+	if err == nil && len(roles) == 0 {
+		roles, err = service.roleRepo.GetByNames([]string{auth.RoleMember})
+	}
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.RoleRepositoryGetUserRolesMethod, err)
+	}
+
+	principal := &auth.Principal{
+		ID:            user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+	}
+	for _, role := range roles {
+		principal.Roles = append(principal.Roles, role.Name)
+		for _, permission := range role.Permissions {
+			if !principal.HasPermission(permission.Code) {
+				principal.Permissions = append(principal.Permissions, permission.Code)
+			}
+		}
+	}
+
+	return principal, nil
+}
+
+// checkAccountActive rejects deleted and suspended users
+func checkAccountActive(user *internaldto.User) *custom.ErrorResult {
+	if user.DeletedAt != nil {
+		errRes := custom.BuildUnauthorizedErrResult(constant.UserNotFoundCode, constant.UserNotFoundMessage, "Authorization")
+		return &errRes
+	}
+
+	if user.SuspendedAt != nil {
+		errRes := custom.BuildForbiddenErrResult(constant.ErrAccountSuspendedCode, constant.ErrAccountSuspendedMsg, "Authorization")
+		return &errRes
+	}
+
+	return nil
+}
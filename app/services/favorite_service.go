@@ -5,6 +5,7 @@ import (
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 )
 
@@ -18,7 +19,7 @@ const (
 type FavoriteService interface {
 	AddFavorite(userID, propertyID uint) *custom.ErrorResult
 	RemoveFavorite(userID, propertyID uint) *custom.ErrorResult
-	ListFavorites(userID uint) ([]dto.FavoriteResponse, *custom.ErrorResult)
+	ListFavorites(userID uint, request dto.PageRequest) ([]dto.FavoriteResponse, pagination.Page, *custom.ErrorResult)
 }
 
 type favoriteService struct {
@@ -32,8 +33,8 @@ type favoriteService struct {
 func CreateFavoriteService(requestID string) FavoriteService {
 	return &favoriteService{
 		serviceContext: CreateServiceContext(requestID),
-		favoriteRepo:   repository.CreateFavoriteRepository(requestID),
-		propertyRepo:   repository.CreatePropertyRepository(requestID),
+		favoriteRepo:   createFavoriteRepository(requestID),
+		propertyRepo:   createPropertyRepository(requestID),
 	}
 }
 
@@ -78,17 +79,17 @@ func (s *favoriteService) RemoveFavorite(userID, propertyID uint) *custom.ErrorR
 	return nil
 }
 
-func (s *favoriteService) ListFavorites(userID uint) ([]dto.FavoriteResponse, *custom.ErrorResult) {
+func (s *favoriteService) ListFavorites(userID uint, request dto.PageRequest) ([]dto.FavoriteResponse, pagination.Page, *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(s.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(FavoriteServiceListMethod), commonLogFields...)
 	defer log.Logger.Debug(log.TraceMsgFuncEnd(FavoriteServiceListMethod), commonLogFields...)
 
 	// Get favorites
-	favorites, _, err := s.favoriteRepo.List(userID, 1, 100) // TODO: Add pagination
+	favorites, page, err := s.favoriteRepo.List(userID, request)
 	if err != nil {
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(FavoriteServiceListMethod), log.TraceError(commonLogFields, err)...)
-		return nil, buildSelectErrFromRepo("Favorites", err)
+		return nil, page, buildListErrFromRepo("Favorites", err)
 	}
 
-	return favorites, nil
+	return favorites, page, nil
 }
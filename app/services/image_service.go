@@ -1,12 +1,15 @@
 package services
 
 import (
+	"errors"
 	"runtime/debug"
 
 	"github.com/chazool/serendib_asia_service/app/repository"
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
 	"gorm.io/gorm"
@@ -18,6 +21,7 @@ const (
 	ImageServiceDeleteMethod     = "ImageServiceDelete"
 	ImageServiceSetPrimaryMethod = "ImageServiceSetPrimary"
 	ImageServiceListMethod       = "ImageServiceList"
+	authorizeImageOwnerMethod    = "authorizeImageOwner"
 )
 
 // ImageService defines the interface for image service methods
@@ -36,8 +40,8 @@ func CreateImageService(requestID string, transactionDB *gorm.DB) *ImageService
 	}
 }
 
-// Upload uploads a property image
-func (service *ImageService) Upload(propertyID uint, request *dto.UploadImageRequest) (response *dto.ImageResponse, errResult *custom.ErrorResult) {
+// Upload uploads an image to a property of the given user
+func (service *ImageService) Upload(propertyID, userID uint, request *dto.UploadImageRequest) (response *dto.ImageResponse, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(ImageServiceUploadMethod), commonLogFields...)
 
@@ -49,14 +53,23 @@ func (service *ImageService) Upload(propertyID uint, request *dto.UploadImageReq
 		log.Logger.Debug(log.TraceMsgFuncEnd(ImageServiceUploadMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.imageRepo = repository.CreateImageRepository(service.serviceContext.RequestID)
+	service.imageRepo = createImageRepository(service.serviceContext.RequestID)
 
-	image, err := service.imageRepo.Upload(propertyID, request.URL, request.IsPrimary)
+	// Only the owner may add images to the property
+	propertyService := CreatePropertyService(service.serviceContext.RequestID, service.transaction)
+	if _, _, errResult = propertyService.authorizePropertyOwner(propertyID, userID); errResult != nil {
+		return nil, errResult
+	}
+
+	// A new image is a material edit of a published listing
+	image, err := service.imageRepo.Upload(propertyID, request.URL, request.IsPrimary, userID, editReviewNote())
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.ImageRepositoryUploadMethod), logFields...)
 		return nil, buildInsertErrFromRepo("image", err)
 	}
+	// The new image may be one another listing already shows
+	propertyService.detectDuplicates(propertyID)
 
 	response = &dto.ImageResponse{
 		ID:         image.ID,
@@ -68,8 +81,8 @@ func (service *ImageService) Upload(propertyID uint, request *dto.UploadImageReq
 	return response, nil
 }
 
-// Delete deletes a property image
-func (service *ImageService) Delete(imageID uint) (errResult *custom.ErrorResult) {
+// Delete deletes an image of a property of the given user
+func (service *ImageService) Delete(imageID, userID uint) (errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(ImageServiceDeleteMethod), commonLogFields...)
 
@@ -81,9 +94,13 @@ func (service *ImageService) Delete(imageID uint) (errResult *custom.ErrorResult
 		log.Logger.Debug(log.TraceMsgFuncEnd(ImageServiceDeleteMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
 	}()
 
-	service.imageRepo = repository.CreateImageRepository(service.serviceContext.RequestID)
+	service.imageRepo = createImageRepository(service.serviceContext.RequestID)
 
-	err := service.imageRepo.Delete(imageID)
+	if errResult = service.authorizeImageOwner(imageID, userID); errResult != nil {
+		return errResult
+	}
+
+	err := service.imageRepo.Delete(imageID, userID, editReviewNote())
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.ImageRepositoryDeleteMethod), logFields...)
@@ -93,8 +110,8 @@ func (service *ImageService) Delete(imageID uint) (errResult *custom.ErrorResult
 	return nil
 }
 
-// SetPrimary sets an image as primary
-func (service *ImageService) SetPrimary(imageID uint) (errResult *custom.ErrorResult) {
+// SetPrimary sets an image of a property of the given user as primary
+func (service *ImageService) SetPrimary(imageID, userID uint) (errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(ImageServiceSetPrimaryMethod), commonLogFields...)
 
@@ -106,9 +123,13 @@ func (service *ImageService) SetPrimary(imageID uint) (errResult *custom.ErrorRe
 		log.Logger.Debug(log.TraceMsgFuncEnd(ImageServiceSetPrimaryMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
 	}()
 
-	service.imageRepo = repository.CreateImageRepository(service.serviceContext.RequestID)
+	service.imageRepo = createImageRepository(service.serviceContext.RequestID)
 
-	err := service.imageRepo.SetPrimary(imageID)
+	if errResult = service.authorizeImageOwner(imageID, userID); errResult != nil {
+		return errResult
+	}
+
+	err := service.imageRepo.SetPrimary(imageID, userID, editReviewNote())
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.ImageRepositorySetPrimaryMethod), logFields...)
@@ -118,8 +139,9 @@ func (service *ImageService) SetPrimary(imageID uint) (errResult *custom.ErrorRe
 	return nil
 }
 
-// List lists all images for a property
-func (service *ImageService) List(propertyID uint) (response *dto.ImageListResponse, errResult *custom.ErrorResult) {
+// List lists one page of the images of a property. The images of a listing that is not
+// published are shown only to those who may see the listing itself.
+func (service *ImageService) List(propertyID uint, principal *auth.Principal, request dto.PageRequest) (response *dto.ImageListResponse, page pagination.Page, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(ImageServiceListMethod), commonLogFields...)
 
@@ -131,18 +153,45 @@ func (service *ImageService) List(propertyID uint) (response *dto.ImageListRespo
 		log.Logger.Debug(log.TraceMsgFuncEnd(ImageServiceListMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.imageRepo = repository.CreateImageRepository(service.serviceContext.RequestID)
+	service.imageRepo = createImageRepository(service.serviceContext.RequestID)
+
+	propertyService := CreatePropertyService(service.serviceContext.RequestID, service.transaction)
+	if _, errResult = propertyService.getVisibleProperty(propertyID, principal); errResult != nil {
+		return nil, page, errResult
+	}
 
-	images, err := service.imageRepo.List(propertyID)
+	images, page, err := service.imageRepo.List(propertyID, request)
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.ImageRepositoryListMethod), logFields...)
-		return nil, buildSelectErrFromRepo("images", err)
+		return nil, page, buildListErrFromRepo("images", err)
 	}
 
 	response = &dto.ImageListResponse{
 		Items: images,
 	}
 
-	return response, nil
+	return response, page, nil
+}
+
+// authorizeImageOwner resolves the parent property of an image and checks the given user may manage it
+func (service *ImageService) authorizeImageOwner(imageID, userID uint) *custom.ErrorResult {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(authorizeImageOwnerMethod), log.TraceMethodInputs(commonLogFields, imageID, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(authorizeImageOwnerMethod), commonLogFields...)
+
+	propertyID, err := service.imageRepo.GetPropertyID(imageID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.ImageNotFoundCode, constant.ImageNotFoundMessage, "Image")
+			return &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.ImageRepositoryGetPropertyIDMethod), logFields...)
+		return buildSelectErrFromRepo("image", err)
+	}
+
+	propertyService := CreatePropertyService(service.serviceContext.RequestID, service.transaction)
+	_, _, errResult := propertyService.authorizePropertyOwner(propertyID, userID)
+	return errResult
 }
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config/listingconfig"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/duplicate"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+// primaryImageID returns the id of the image a listing was stored with
+func primaryImageID(t *testing.T, store *testStore, propertyID uint) uint {
+	t.Helper()
+	property, err := (&testPropertyRepository{store: store}).GetByID(propertyID)
+	if err != nil || len(property.PropertyImages) == 0 {
+		t.Fatalf("listing %d has no image", propertyID)
+	}
+	return property.PropertyImages[0].ID
+}
+
+func TestImageMutationsRequireOwner(t *testing.T) {
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+	stranger := store.addUser("stranger@example.com", "password")
+	property := store.addProperty(owner.ID, nil, constant.PropertyStatusPublished)
+	imageID := primaryImageID(t, store, property.ID)
+
+	_, errResult := CreateImageService("test", nil).Upload(property.ID, stranger.ID, &dto.UploadImageRequest{URL: "https://cdn.example.com/other.jpg"})
+	assertErrorStatus(t, errResult, http.StatusForbidden)
+	assertErrorCode(t, errResult, constant.NotPropertyOwnerCode)
+
+	errResult = CreateImageService("test", nil).SetPrimary(imageID, stranger.ID)
+	assertErrorStatus(t, errResult, http.StatusForbidden)
+
+	errResult = CreateImageService("test", nil).Delete(imageID, stranger.ID)
+	assertErrorStatus(t, errResult, http.StatusForbidden)
+
+	if count := store.imageCount(property.ID); count != 1 {
+		t.Errorf("images after rejected changes = %d, want 1", count)
+	}
+}
+
+func TestImageMutationsUnknownImage(t *testing.T) {
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+
+	errResult := CreateImageService("test", nil).Delete(404, owner.ID)
+	assertErrorStatus(t, errResult, http.StatusNotFound)
+	assertErrorCode(t, errResult, constant.ImageNotFoundCode)
+}
+
+func TestImageChangesSendListingToReview(t *testing.T) {
+	changes := map[string]func(service *ImageService, propertyID, imageID, userID uint) *custom.ErrorResult{
+		"upload": func(service *ImageService, propertyID, _, userID uint) *custom.ErrorResult {
+			_, errResult := service.Upload(propertyID, userID, &dto.UploadImageRequest{URL: "https://cdn.example.com/side.jpg"})
+			return errResult
+		},
+		"delete": func(service *ImageService, _, imageID, userID uint) *custom.ErrorResult {
+			return service.Delete(imageID, userID)
+		},
+		"set primary": func(service *ImageService, _, imageID, userID uint) *custom.ErrorResult {
+			return service.SetPrimary(imageID, userID)
+		},
+	}
+
+	tests := []struct {
+		name        string
+		status      string
+		reviewEdits string
+		wantStatus  string
+	}{
+		{name: "published listing", status: constant.PropertyStatusPublished, reviewEdits: "true", wantStatus: constant.PropertyStatusPendingReview},
+		{name: "published listing without edit review", status: constant.PropertyStatusPublished, reviewEdits: "false", wantStatus: constant.PropertyStatusPublished},
+		{name: "draft", status: constant.PropertyStatusDraft, reviewEdits: "true", wantStatus: constant.PropertyStatusDraft},
+	}
+
+	for _, tt := range tests {
+		for change, apply := range changes {
+			t.Run(tt.name+"/"+change, func(t *testing.T) {
+				t.Setenv(listingconfig.ListingReviewEdits, tt.reviewEdits)
+				store := setupServiceTest(t)
+				owner := store.addUser("owner@example.com", "password")
+				property := store.addProperty(owner.ID, nil, tt.status)
+
+				if errResult := apply(CreateImageService("test", nil), property.ID, primaryImageID(t, store, property.ID), owner.ID); errResult != nil {
+					t.Fatalf("%s error = %v", change, errResult.ErrorList)
+				}
+				if status := store.property(property.ID).Status; status != tt.wantStatus {
+					t.Errorf("status after %s = %q, want %q", change, status, tt.wantStatus)
+				}
+			})
+		}
+	}
+}
+
+func TestUploadFlagsDuplicateImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           func(original *dto.Property) string
+		wantDuplicate bool
+	}{
+		{name: "image of another listing", url: func(original *dto.Property) string {
+			return fmt.Sprintf("https://cdn.example.com/%d/front.jpg", original.ID)
+		}, wantDuplicate: true},
+		{name: "new image", url: func(*dto.Property) string { return "https://cdn.example.com/garden.jpg" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupServiceTest(t)
+			owner := store.addUser("owner@example.com", "password")
+			other := store.addUser("other@example.com", "password")
+			original := store.addProperty(other.ID, nil, constant.PropertyStatusPublished)
+			listing := store.addProperty(owner.ID, nil, constant.PropertyStatusDraft)
+			listing.Title = "Beach villa in Galle"
+			listing.Address = "5 Lighthouse Street"
+			listing.City = "Galle"
+			listing.Price = 90000000
+
+			_, errResult := CreateImageService("test", nil).Upload(listing.ID, owner.ID, &dto.UploadImageRequest{URL: tt.url(original)})
+			if errResult != nil {
+				t.Fatalf("Upload() error = %v", errResult.ErrorList)
+			}
+
+			duplicates := store.duplicatesOf(listing.ID)
+			if !tt.wantDuplicate {
+				if len(duplicates) != 0 {
+					t.Errorf("Upload() flagged %v, want no duplicates", duplicates)
+				}
+				return
+			}
+			if len(duplicates) != 1 || duplicates[0].PropertyID != listing.ID || duplicates[0].DuplicateOfID != original.ID {
+				t.Fatalf("Upload() flagged %v, want listing %d as a duplicate of %d", duplicates, listing.ID, original.ID)
+			}
+			if duplicates[0].Reasons != duplicate.ReasonImages {
+				t.Errorf("duplicate reasons = %q, want %q", duplicates[0].Reasons, duplicate.ReasonImages)
+			}
+		})
+	}
+}
+
+func TestListImagesVisibility(t *testing.T) {
+	moderator := &auth.Principal{ID: 900, Permissions: []string{auth.PermissionModerateProperties}}
+
+	tests := []struct {
+		name        string
+		status      string
+		viewer      string
+		wantVisible bool
+	}{
+		{name: "published to anyone", status: constant.PropertyStatusPublished, viewer: "anonymous", wantVisible: true},
+		{name: "draft to anyone", status: constant.PropertyStatusDraft, viewer: "anonymous"},
+		{name: "draft to another user", status: constant.PropertyStatusDraft, viewer: "stranger"},
+		{name: "draft to its owner", status: constant.PropertyStatusDraft, viewer: "owner", wantVisible: true},
+		{name: "pending review to a moderator", status: constant.PropertyStatusPendingReview, viewer: "moderator", wantVisible: true},
+		{name: "rejected to another user", status: constant.PropertyStatusRejected, viewer: "stranger"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupServiceTest(t)
+			owner := store.addUser("owner@example.com", "password")
+			stranger := store.addUser("stranger@example.com", "password")
+			property := store.addProperty(owner.ID, nil, tt.status)
+
+			var principal *auth.Principal
+			switch tt.viewer {
+			case "owner":
+				principal = &auth.Principal{ID: owner.ID}
+			case "stranger":
+				principal = &auth.Principal{ID: stranger.ID}
+			case "moderator":
+				principal = moderator
+			}
+
+			response, _, errResult := CreateImageService("test", nil).List(property.ID, principal, dto.PageRequest{})
+			if !tt.wantVisible {
+				assertErrorStatus(t, errResult, http.StatusNotFound)
+				assertErrorCode(t, errResult, constant.PropertyNotFoundCode)
+				return
+			}
+			if errResult != nil {
+				t.Fatalf("List() error = %v", errResult.ErrorList)
+			}
+			if len(response.Items) != 1 {
+				t.Errorf("List() returned %d images, want 1", len(response.Items))
+			}
+		})
+	}
+}
+
+func TestListImagesUnknownProperty(t *testing.T) {
+	setupServiceTest(t)
+
+	_, _, errResult := CreateImageService("test", nil).List(404, nil, dto.PageRequest{})
+	assertErrorStatus(t, errResult, http.StatusNotFound)
+	assertErrorCode(t, errResult, constant.PropertyNotFoundCode)
+}
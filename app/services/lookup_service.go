@@ -51,7 +51,7 @@ func (service *LookupService) GetPurposeTypes() (response []dto.LookupResponse,
 		log.Logger.Debug(log.TraceMsgFuncEnd(LookupServiceGetPurposeTypesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.lookupRepo = repository.CreateLookupRepository(service.serviceContext.RequestID)
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
 	response, err := service.lookupRepo.GetPurposeTypes()
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -75,7 +75,7 @@ func (service *LookupService) GetPropertyTypes() (response []dto.LookupResponse,
 		log.Logger.Debug(log.TraceMsgFuncEnd(LookupServiceGetPropertyTypesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.lookupRepo = repository.CreateLookupRepository(service.serviceContext.RequestID)
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
 	response, err := service.lookupRepo.GetPropertyTypes()
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -99,7 +99,7 @@ func (service *LookupService) GetFurnitureTypes() (response []dto.LookupResponse
 		log.Logger.Debug(log.TraceMsgFuncEnd(LookupServiceGetFurnitureTypesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.lookupRepo = repository.CreateLookupRepository(service.serviceContext.RequestID)
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
 	response, err := service.lookupRepo.GetFurnitureTypes()
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -123,7 +123,7 @@ func (service *LookupService) GetConditions() (response []dto.LookupResponse, er
 		log.Logger.Debug(log.TraceMsgFuncEnd(LookupServiceGetConditionsMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.lookupRepo = repository.CreateLookupRepository(service.serviceContext.RequestID)
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
 	response, err := service.lookupRepo.GetConditions()
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -147,7 +147,7 @@ func (service *LookupService) GetUtilities() (response []dto.LookupResponse, err
 		log.Logger.Debug(log.TraceMsgFuncEnd(LookupServiceGetUtilitiesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.lookupRepo = repository.CreateLookupRepository(service.serviceContext.RequestID)
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
 	response, err := service.lookupRepo.GetUtilities()
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -171,7 +171,7 @@ func (service *LookupService) GetAmenities() (response []dto.LookupResponse, err
 		log.Logger.Debug(log.TraceMsgFuncEnd(LookupServiceGetAmenitiesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.lookupRepo = repository.CreateLookupRepository(service.serviceContext.RequestID)
+	service.lookupRepo = createLookupRepository(service.serviceContext.RequestID)
 	response, err := service.lookupRepo.GetAmenities()
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -0,0 +1,658 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/mail"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// Organization service methods
+	OrganizationServiceCreateMethod           = "OrganizationServiceCreate"
+	OrganizationServiceListMineMethod         = "OrganizationServiceListMine"
+	OrganizationServiceUpdateMethod           = "OrganizationServiceUpdate"
+	OrganizationServicePublicProfileMethod    = "OrganizationServiceGetPublicProfile"
+	OrganizationServiceListMembersMethod      = "OrganizationServiceListMembers"
+	OrganizationServiceUpdateMemberRoleMethod = "OrganizationServiceUpdateMemberRole"
+	OrganizationServiceRemoveMemberMethod     = "OrganizationServiceRemoveMember"
+	OrganizationServiceInviteMethod           = "OrganizationServiceInvite"
+	OrganizationServiceListInvitationsMethod  = "OrganizationServiceListInvitations"
+	OrganizationServiceRevokeInvitationMethod = "OrganizationServiceRevokeInvitation"
+	OrganizationServiceAcceptInvitationMethod = "OrganizationServiceAcceptInvitation"
+	requireOrganizationRoleMethod             = "requireOrganizationRole"
+	sendInvitationEmailMethod                 = "sendInvitationEmail"
+)
+
+// organizationAdminRoles may edit the organization, invite members and manage every listing
+var organizationAdminRoles = []string{auth.OrganizationRoleOwner, auth.OrganizationRoleManager}
+
+// OrganizationService manages agencies, their members and invitations
+type OrganizationService struct {
+	_              struct{}
+	serviceContext ServiceContext
+	transaction    *gorm.DB
+	orgRepo        repository.OrganizationRepository
+	userRepo       repository.UserRepository
+	propertyRepo   repository.PropertyRepository
+	mailer         mail.Mailer
+}
+
+// CreateOrganizationService creates a new instance of OrganizationService
+func CreateOrganizationService(requestID string, transactionDB *gorm.DB) *OrganizationService {
+	return &OrganizationService{
+		serviceContext: CreateServiceContext(requestID),
+		transaction:    transactionDB,
+	}
+}
+
+// Create creates an organization with the given user as its first owner
+func (service *OrganizationService) Create(userID uint, request dto.OrganizationRequest) (response *dto.OrganizationResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceCreateMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceCreateMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceCreateMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	organization := &internaldto.Organization{CreatedBy: userID}
+	if errResult = applyOrganizationRequest(organization, request); errResult != nil {
+		return nil, errResult
+	}
+
+	if err := service.orgRepo.Create(organization); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildInsertErrFromRepo("organization", err)
+	}
+
+	return toOrganizationResponse(organization, auth.OrganizationRoleOwner), nil
+}
+
+// ListMine lists the organizations the user belongs to
+func (service *OrganizationService) ListMine(userID uint) (response []dto.OrganizationResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceListMineMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceListMineMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceListMineMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	response, err := service.orgRepo.ListByMember(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryListByMemberMethod, err)
+	}
+
+	return response, nil
+}
+
+// Update updates the profile of an organization, owners and managers only
+func (service *OrganizationService) Update(organizationID, userID uint, request dto.OrganizationRequest) (response *dto.OrganizationResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceUpdateMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceUpdateMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceUpdateMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	member, errResult := service.requireOrganizationRole(organizationID, userID, organizationAdminRoles...)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	organization, err := service.orgRepo.GetByID(organizationID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetByIDMethod, err)
+	}
+	if errResult = applyOrganizationRequest(organization, request); errResult != nil {
+		return nil, errResult
+	}
+
+	if err = service.orgRepo.Update(organization); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryUpdateMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildUpdateErrFromRepo("organization", err)
+	}
+
+	return toOrganizationResponse(organization, member.Role), nil
+}
+
+// GetPublicProfile returns the public agency page of an organization with a page of its listings
+func (service *OrganizationService) GetPublicProfile(organizationID uint, request dto.PageRequest) (response *dto.OrganizationProfileResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServicePublicProfileMethod), log.TraceMethodInputs(commonLogFields, organizationID, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServicePublicProfileMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServicePublicProfileMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	organization, err := service.orgRepo.GetByID(organizationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.OrganizationNotFoundCode, constant.OrganizationNotFoundMessage, "Organization")
+			return nil, &errRes
+		}
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetByIDMethod, err)
+	}
+
+	memberCount, err := service.orgRepo.CountMembers(organizationID, constant.Empty)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryCountMembersMethod, err)
+	}
+
+	count, err := service.propertyRepo.CountPublicByOrganizationID(organizationID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.PropertyRepositoryCountPublicByOrgMethod, err)
+	}
+
+	// The listing count is always part of the profile
+	request.IncludeTotal = false
+	listings, page, err := service.propertyRepo.ListPublicByOrganizationID(organizationID, request)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.PropertyRepositoryListPublicByOrgMethod, err)
+	}
+
+	response = &dto.OrganizationProfileResponse{
+		ID:                 organization.ID,
+		Name:               organization.Name,
+		Description:        organization.Description,
+		Email:              organization.Email,
+		PhoneNumber:        organization.PhoneNumber,
+		Website:            organization.Website,
+		LogoURL:            organization.LogoURL,
+		City:               organization.City,
+		MemberSince:        organization.CreatedAt,
+		MemberCount:        memberCount,
+		ActiveListingCount: count,
+		Listings:           listings,
+		Pagination:         page,
+	}
+
+	return response, nil
+}
+
+// ListMembers lists the members of an organization to any of its members
+func (service *OrganizationService) ListMembers(organizationID, userID uint) (response []dto.OrganizationMemberResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceListMembersMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceListMembersMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceListMembersMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.requireOrganizationRole(organizationID, userID, auth.OrganizationRoles...); errResult != nil {
+		return nil, errResult
+	}
+
+	response, err := service.orgRepo.ListMembers(organizationID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryListMembersMethod, err)
+	}
+
+	return response, nil
+}
+
+// UpdateMemberRole changes the role of a member, owners only. The last owner cannot step down.
+func (service *OrganizationService) UpdateMemberRole(organizationID, userID, memberID uint, request dto.UpdateMemberRoleRequest) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceUpdateMemberRoleMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, memberID, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceUpdateMemberRoleMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceUpdateMemberRoleMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.requireOrganizationRole(organizationID, userID, auth.OrganizationRoleOwner); errResult != nil {
+		return errResult
+	}
+
+	member, errResult := service.getMember(organizationID, memberID)
+	if errResult != nil {
+		return errResult
+	}
+	if member.Role == auth.OrganizationRoleOwner && request.Role != auth.OrganizationRoleOwner {
+		if errResult = service.checkNotLastOwner(organizationID); errResult != nil {
+			return errResult
+		}
+	}
+
+	err := service.orgRepo.UpdateMemberRole(organizationID, memberID, request.Role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.MemberNotFoundCode, constant.MemberNotFoundMessage, "Member")
+			return &errRes
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryUpdateMemberMethod), log.TraceError(commonLogFields, err)...)
+		return buildUpdateErrFromRepo("organization member", err)
+	}
+
+	return nil
+}
+
+// RemoveMember removes a member from an organization. Members may always leave, owners
+// may remove anyone and managers may remove agents. The last owner cannot leave.
+func (service *OrganizationService) RemoveMember(organizationID, userID, memberID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceRemoveMemberMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, memberID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceRemoveMemberMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceRemoveMemberMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	actor, errResult := service.requireOrganizationRole(organizationID, userID, auth.OrganizationRoles...)
+	if errResult != nil {
+		return errResult
+	}
+
+	member, errResult := service.getMember(organizationID, memberID)
+	if errResult != nil {
+		return errResult
+	}
+
+	allowed := memberID == userID ||
+		actor.Role == auth.OrganizationRoleOwner ||
+		(actor.Role == auth.OrganizationRoleManager && member.Role == auth.OrganizationRoleAgent)
+	if !allowed {
+		errRes := custom.BuildForbiddenErrResult(constant.OrganizationRoleCode, constant.OrganizationRoleMessage, "Member")
+		return &errRes
+	}
+	if member.Role == auth.OrganizationRoleOwner {
+		if errResult = service.checkNotLastOwner(organizationID); errResult != nil {
+			return errResult
+		}
+	}
+
+	if err := service.orgRepo.RemoveMember(organizationID, memberID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.MemberNotFoundCode, constant.MemberNotFoundMessage, "Member")
+			return &errRes
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryRemoveMemberMethod), log.TraceError(commonLogFields, err)...)
+		return buildDeleteErrFromRepo("organization member", err)
+	}
+
+	return nil
+}
+
+// Invite emails an invitation to join the organization. Owners and managers may invite,
+// only owners may invite other owners.
+func (service *OrganizationService) Invite(organizationID, userID uint, request dto.InviteMemberRequest) (response *dto.InvitationResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceInviteMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, request.Role)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceInviteMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceInviteMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	actor, errResult := service.requireOrganizationRole(organizationID, userID, organizationAdminRoles...)
+	if errResult != nil {
+		return nil, errResult
+	}
+	if request.Role == auth.OrganizationRoleOwner && actor.Role != auth.OrganizationRoleOwner {
+		errRes := custom.BuildForbiddenErrResult(constant.OrganizationRoleCode, constant.OrganizationRoleMessage, "Role")
+		return nil, &errRes
+	}
+
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+
+	// Inviting an existing member again would only fail on acceptance
+	invitee, err := service.userRepo.GetUserByEmail(email)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, checkRepoError(commonLogFields, repository.UserRepositoryGetUserByEmailMethod, err)
+	}
+	if invitee != nil {
+		if _, err = service.orgRepo.GetMember(organizationID, invitee.ID); err == nil {
+			errRes := custom.BuildBadReqErrResult(constant.AlreadyMemberCode, constant.AlreadyMemberMessage, "Email")
+			return nil, &errRes
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetMemberMethod, err)
+		}
+	}
+
+	organization, err := service.orgRepo.GetByID(organizationID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetByIDMethod, err)
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhen("generating invitation token"), log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.UnexpectedErrorCode, fmt.Sprintf(constant.UnexpectedErrorMessage, OrganizationServiceInviteMethod), err.Error())
+		return nil, &errRes
+	}
+
+	authConfig := config.GetConfig().AuthConfig
+	invitation := &internaldto.OrganizationInvitation{
+		OrganizationID: organizationID,
+		Email:          email,
+		Role:           request.Role,
+		TokenHash:      tokenHash,
+		InvitedBy:      userID,
+		ExpiresAt:      time.Now().Add(authConfig.OrganizationInvitationTTL),
+	}
+	if err = service.orgRepo.CreateInvitation(invitation); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryCreateInviteMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildInsertErrFromRepo("organization invitation", err)
+	}
+
+	if err = service.sendInvitationEmail(invitation, organization.Name, token); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(sendInvitationEmailMethod), log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.UnexpectedErrorCode, constant.ErrOccurredWhenSendingEmail, err.Error())
+		return nil, &errRes
+	}
+
+	return toInvitationResponse(invitation), nil
+}
+
+// ListInvitations lists the pending invitations of an organization, owners and managers only
+func (service *OrganizationService) ListInvitations(organizationID, userID uint) (response []dto.InvitationResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceListInvitationsMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceListInvitationsMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceListInvitationsMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.requireOrganizationRole(organizationID, userID, organizationAdminRoles...); errResult != nil {
+		return nil, errResult
+	}
+
+	invitations, err := service.orgRepo.ListInvitations(organizationID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryListInvitesMethod, err)
+	}
+
+	response = make([]dto.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		response = append(response, *toInvitationResponse(&invitations[i]))
+	}
+
+	return response, nil
+}
+
+// RevokeInvitation revokes a pending invitation, owners and managers only
+func (service *OrganizationService) RevokeInvitation(organizationID, userID, invitationID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceRevokeInvitationMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, invitationID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceRevokeInvitationMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceRevokeInvitationMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.requireOrganizationRole(organizationID, userID, organizationAdminRoles...); errResult != nil {
+		return errResult
+	}
+
+	if err := service.orgRepo.RevokeInvitation(organizationID, invitationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.InvitationNotFoundCode, constant.InvitationNotFoundMessage, "Invitation")
+			return &errRes
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryRevokeInviteMethod), log.TraceError(commonLogFields, err)...)
+		return buildUpdateErrFromRepo("organization invitation", err)
+	}
+
+	return nil
+}
+
+// AcceptInvitation adds the user to the organization of an emailed invitation. The
+// invitation must have been sent to the email address of the user.
+func (service *OrganizationService) AcceptInvitation(userID uint, request dto.AcceptInvitationRequest) (response *dto.OrganizationResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(OrganizationServiceAcceptInvitationMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(OrganizationServiceAcceptInvitationMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(OrganizationServiceAcceptInvitationMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	invalidInvitationErr := custom.BuildBadReqErrResult(constant.InvalidInvitationCode, constant.InvalidInvitationMessage, "Token")
+
+	invitation, err := service.orgRepo.GetInvitationByHash(auth.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &invalidInvitationErr
+		}
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetInviteByHashMethod, err)
+	}
+	if invitation.AcceptedAt != nil || invitation.RevokedAt != nil || invitation.ExpiresAt.Before(time.Now()) {
+		return nil, &invalidInvitationErr
+	}
+
+	user, err := service.userRepo.GetUserByID(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		errRes := custom.BuildForbiddenErrResult(constant.InvitationEmailCode, constant.InvitationEmailMessage, "Token")
+		return nil, &errRes
+	}
+
+	if _, err = service.orgRepo.GetMember(invitation.OrganizationID, userID); err == nil {
+		errRes := custom.BuildBadReqErrResult(constant.AlreadyMemberCode, constant.AlreadyMemberMessage, "Token")
+		return nil, &errRes
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetMemberMethod, err)
+	}
+
+	if err = service.orgRepo.AcceptInvitation(invitation, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &invalidInvitationErr
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.OrganizationRepositoryAcceptInvitationMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildInsertErrFromRepo("organization member", err)
+	}
+
+	organization, err := service.orgRepo.GetByID(invitation.OrganizationID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetByIDMethod, err)
+	}
+
+	return toOrganizationResponse(organization, invitation.Role), nil
+}
+
+// requireOrganizationRole returns the membership of the user in the organization,
+// rejecting users who hold none of the given roles
+func (service *OrganizationService) requireOrganizationRole(organizationID, userID uint, roles ...string) (*internaldto.OrganizationMember, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(requireOrganizationRoleMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID, roles)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(requireOrganizationRoleMethod), commonLogFields...)
+
+	member, errResult := getOrganizationMember(service.orgRepo, commonLogFields, organizationID, userID)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	if !slices.Contains(roles, member.Role) {
+		log.Logger.Warn(constant.OrganizationRoleMessage, log.TraceMethodInputs(commonLogFields, organizationID, userID, member.Role)...)
+		errRes := custom.BuildForbiddenErrResult(constant.OrganizationRoleCode, constant.OrganizationRoleMessage, "Organization")
+		return nil, &errRes
+	}
+
+	return member, nil
+}
+
+// getMember returns the membership of another user, not found when they are not a member
+func (service *OrganizationService) getMember(organizationID, memberID uint) (*internaldto.OrganizationMember, *custom.ErrorResult) {
+	member, err := service.orgRepo.GetMember(organizationID, memberID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.MemberNotFoundCode, constant.MemberNotFoundMessage, "Member")
+			return nil, &errRes
+		}
+		return nil, checkRepoError(log.CommonLogField(service.serviceContext.RequestID), repository.OrganizationRepositoryGetMemberMethod, err)
+	}
+
+	return member, nil
+}
+
+// checkNotLastOwner rejects a change that would leave the organization without an owner
+func (service *OrganizationService) checkNotLastOwner(organizationID uint) *custom.ErrorResult {
+	owners, err := service.orgRepo.CountMembers(organizationID, auth.OrganizationRoleOwner)
+	if err != nil {
+		return checkRepoError(log.CommonLogField(service.serviceContext.RequestID), repository.OrganizationRepositoryCountMembersMethod, err)
+	}
+	if owners <= 1 {
+		errRes := custom.BuildBadReqErrResult(constant.LastOwnerCode, constant.LastOwnerMessage, "Role")
+		return &errRes
+	}
+
+	return nil
+}
+
+// sendInvitationEmail emails the invitation link to the invitee
+func (service *OrganizationService) sendInvitationEmail(invitation *internaldto.OrganizationInvitation, organizationName, token string) (err error) {
+	link := config.GetConfig().AuthConfig.OrganizationInvitationURL + "?token=" + url.QueryEscape(token)
+
+	service.mailer, err = mail.NewMailer()
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(mail.OrganizationInvitationMessage(invitation.Email, organizationName, invitation.Role, link))
+}
+
+// getOrganizationMember returns the membership of the user in the organization, not found
+// for an unknown organization and forbidden for users outside of it
+func getOrganizationMember(orgRepo repository.OrganizationRepository, commonLogFields []zap.Field, organizationID, userID uint) (*internaldto.OrganizationMember, *custom.ErrorResult) {
+	member, err := orgRepo.GetMember(organizationID, userID)
+	if err == nil {
+		return member, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetMemberMethod, err)
+	}
+
+	if _, err = orgRepo.GetByID(organizationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.OrganizationNotFoundCode, constant.OrganizationNotFoundMessage, "Organization")
+			return nil, &errRes
+		}
+		return nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetByIDMethod, err)
+	}
+
+	errRes := custom.BuildForbiddenErrResult(constant.NotOrganizationMemberCode, constant.NotOrganizationMemberMessage, "Organization")
+	return nil, &errRes
+}
+
+// applyOrganizationRequest copies the profile fields of the request onto the organization
+func applyOrganizationRequest(organization *internaldto.Organization, request dto.OrganizationRequest) *custom.ErrorResult {
+	organization.Name = strings.TrimSpace(request.Name)
+	organization.Description = request.Description
+	organization.Email = strings.ToLower(strings.TrimSpace(request.Email))
+	organization.PhoneNumber = request.PhoneNumber
+	organization.Website = request.Website
+	organization.LogoURL = request.LogoURL
+	organization.City = request.City
+
+	return normalizePhoneNumber(&organization.PhoneNumber)
+}
+
+// toOrganizationResponse maps an organization to its response for a member holding role
+func toOrganizationResponse(organization *internaldto.Organization, role string) *dto.OrganizationResponse {
+	return &dto.OrganizationResponse{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Description: organization.Description,
+		Email:       organization.Email,
+		PhoneNumber: organization.PhoneNumber,
+		Website:     organization.Website,
+		LogoURL:     organization.LogoURL,
+		City:        organization.City,
+		Role:        role,
+		CreatedAt:   organization.CreatedAt,
+	}
+}
+
+// toInvitationResponse maps an invitation to its response, without the token
+func toInvitationResponse(invitation *internaldto.OrganizationInvitation) *dto.InvitationResponse {
+	return &dto.InvitationResponse{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		InvitedBy: invitation.InvitedBy,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+func TestUpdateOrganizationRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "owner", role: auth.OrganizationRoleOwner},
+		{name: "manager", role: auth.OrganizationRoleManager},
+		{name: "agent", role: auth.OrganizationRoleAgent, wantStatus: http.StatusForbidden, wantCode: constant.OrganizationRoleCode},
+		{name: "not a member", wantStatus: http.StatusForbidden, wantCode: constant.NotOrganizationMemberCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupServiceTest(t)
+			owner := store.addUser("owner@example.com", "password")
+			organization := store.addOrganization(owner.ID)
+			actor := owner
+			if tt.role != auth.OrganizationRoleOwner {
+				actor = store.addUser("actor@example.com", "password")
+				if tt.role != "" {
+					store.addMember(organization.ID, actor.ID, tt.role)
+				}
+			}
+
+			_, errResult := CreateOrganizationService("test", nil).Update(organization.ID, actor.ID, dto.OrganizationRequest{Name: "Lanka Homes and Villas"})
+			if tt.wantStatus == 0 {
+				if errResult != nil {
+					t.Fatalf("Update() error = %v", errResult.ErrorList)
+				}
+				return
+			}
+			assertErrorStatus(t, errResult, tt.wantStatus)
+			assertErrorCode(t, errResult, tt.wantCode)
+			if name := store.organizations[organization.ID].Name; name != "Lanka Homes" {
+				t.Errorf("rejected Update() changed the name to %q", name)
+			}
+		})
+	}
+}
+
+func TestUpdateOrganizationUnknown(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+
+	_, errResult := CreateOrganizationService("test", nil).Update(404, user.ID, dto.OrganizationRequest{Name: "Lanka Homes"})
+	assertErrorStatus(t, errResult, http.StatusNotFound)
+	assertErrorCode(t, errResult, constant.OrganizationNotFoundCode)
+}
+
+func TestRemoveMemberRoles(t *testing.T) {
+	tests := []struct {
+		name       string
+		actorRole  string
+		memberRole string
+		wantStatus int
+	}{
+		{name: "manager removes agent", actorRole: auth.OrganizationRoleManager, memberRole: auth.OrganizationRoleAgent},
+		{name: "manager removes manager", actorRole: auth.OrganizationRoleManager, memberRole: auth.OrganizationRoleManager, wantStatus: http.StatusForbidden},
+		{name: "agent removes agent", actorRole: auth.OrganizationRoleAgent, memberRole: auth.OrganizationRoleAgent, wantStatus: http.StatusForbidden},
+		{name: "owner removes manager", actorRole: auth.OrganizationRoleOwner, memberRole: auth.OrganizationRoleManager},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupServiceTest(t)
+			owner := store.addUser("owner@example.com", "password")
+			organization := store.addOrganization(owner.ID)
+			actor := owner
+			if tt.actorRole != auth.OrganizationRoleOwner {
+				actor = store.addUser("actor@example.com", "password")
+				store.addMember(organization.ID, actor.ID, tt.actorRole)
+			}
+			member := store.addUser("member@example.com", "password")
+			store.addMember(organization.ID, member.ID, tt.memberRole)
+
+			errResult := CreateOrganizationService("test", nil).RemoveMember(organization.ID, actor.ID, member.ID)
+			if tt.wantStatus == 0 {
+				if errResult != nil {
+					t.Fatalf("RemoveMember() error = %v", errResult.ErrorList)
+				}
+				return
+			}
+			assertErrorStatus(t, errResult, tt.wantStatus)
+			assertErrorCode(t, errResult, constant.OrganizationRoleCode)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/duplicate"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// Property duplicate service methods
+	PropertyServiceListDuplicatesMethod   = "PropertyServiceListDuplicates"
+	PropertyServiceMergeDuplicateMethod   = "PropertyServiceMergeDuplicate"
+	PropertyServiceDismissDuplicateMethod = "PropertyServiceDismissDuplicate"
+	detectDuplicatesMethod                = "detectDuplicates"
+
+	// reasonSeparator joins the reasons of a duplicate flag
+	reasonSeparator = ","
+)
+
+// ListDuplicates lists one page of the open duplicate flags, most similar first
+func (service *PropertyService) ListDuplicates(principal *auth.Principal, request dto.PageRequest) (response []dto.PropertyDuplicateResponse, page pagination.Page, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListDuplicatesMethod), log.TraceMethodInputs(commonLogFields, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceListDuplicatesMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceListDuplicatesMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return nil, page, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	duplicates, page, err := service.propertyRepo.ListDuplicates(request)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListDuplicatesMethod), logFields...)
+		return nil, page, buildListErrFromRepo("property duplicates", err)
+	}
+
+	response = make([]dto.PropertyDuplicateResponse, 0, len(duplicates))
+	for _, flag := range duplicates {
+		response = append(response, buildDuplicateResponse(flag))
+	}
+
+	return response, page, nil
+}
+
+// MergeDuplicate resolves a duplicate flag by keeping one listing of the pair and archiving the other
+func (service *PropertyService) MergeDuplicate(duplicateID uint, principal *auth.Principal, request dto.PropertyDuplicateMergeRequest) (response dto.PropertyDuplicateResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceMergeDuplicateMethod), log.TraceMethodInputs(commonLogFields, duplicateID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceMergeDuplicateMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceMergeDuplicateMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return response, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	flag, errResult := service.getOpenDuplicate(commonLogFields, duplicateID)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	var archiveID uint
+	switch request.KeepPropertyID {
+	case flag.PropertyID:
+		archiveID = flag.DuplicateOfID
+	case flag.DuplicateOfID:
+		archiveID = flag.PropertyID
+	default:
+		errRes := custom.BuildBadReqErrResult(constant.InvalidMergeCode, fmt.Sprintf(constant.InvalidMergeMsg, flag.DuplicateOfID, flag.PropertyID), "keep_property_id")
+		return response, &errRes
+	}
+
+	archive, errResult := service.getProperty(archiveID)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	err := service.propertyRepo.MergeDuplicate(duplicateID, archive, principal.ID, request.Note, fmt.Sprintf(constant.ListingMergedNote, request.KeepPropertyID))
+	if err != nil {
+		return response, buildResolveDuplicateErr(commonLogFields, repository.PropertyRepositoryMergeDuplicateMethod, err)
+	}
+
+	return service.getDuplicate(duplicateID)
+}
+
+// DismissDuplicate resolves a duplicate flag as not a duplicate, leaving both listings as they are.
+// The pair is not flagged again.
+func (service *PropertyService) DismissDuplicate(duplicateID uint, principal *auth.Principal, request dto.PropertyDuplicateDismissRequest) (response dto.PropertyDuplicateResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceDismissDuplicateMethod), log.TraceMethodInputs(commonLogFields, duplicateID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceDismissDuplicateMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceDismissDuplicateMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return response, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.getOpenDuplicate(commonLogFields, duplicateID); errResult != nil {
+		return response, errResult
+	}
+
+	err := service.propertyRepo.DismissDuplicate(duplicateID, principal.ID, request.Note)
+	if err != nil {
+		return response, buildResolveDuplicateErr(commonLogFields, repository.PropertyRepositoryDismissDuplicateMethod, err)
+	}
+
+	return service.getDuplicate(duplicateID)
+}
+
+// detectDuplicates compares a listing with the existing listings that may be the same property and
+// flags the likely duplicates. The listing change stands whatever the outcome, so failures are only logged.
+func (service *PropertyService) detectDuplicates(propertyID uint) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(detectDuplicatesMethod), log.TraceMethodInputs(commonLogFields, propertyID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(detectDuplicatesMethod), commonLogFields...)
+
+	duplicateConfig := config.GetConfig().DuplicateConfig
+	if !duplicateConfig.Enabled {
+		return
+	}
+
+	property, err := service.propertyRepo.GetByID(propertyID)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetByIDMethod), log.TraceError(commonLogFields, err)...)
+		return
+	}
+
+	candidates, err := service.propertyRepo.ListDuplicateCandidates(property, duplicateConfig.RadiusMeters/1000, duplicateConfig.PriceTolerance, duplicateConfig.CandidateLimit)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListDuplicateCandidatesMethod), log.TraceError(commonLogFields, err)...)
+		return
+	}
+
+	detector := duplicate.NewDetector(duplicateConfig)
+	listing := duplicateListing(property)
+	for _, candidate := range candidates {
+		result := detector.Compare(listing, duplicateListing(candidate))
+		if !detector.IsDuplicate(result) {
+			continue
+		}
+
+		// A pair is kept once, with the newer listing as the duplicate of the older one
+		flag := internaldto.PropertyDuplicate{
+			PropertyID:    max(property.ID, candidate.ID),
+			DuplicateOfID: min(property.ID, candidate.ID),
+			Score:         result.Score,
+			Reasons:       strings.Join(result.Reasons, reasonSeparator),
+		}
+		if err = service.propertyRepo.SaveDuplicate(flag); err != nil {
+			logFields := log.TraceError(commonLogFields, err)
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositorySaveDuplicateMethod), append(logFields, zap.Uint("candidateID", candidate.ID))...)
+		}
+	}
+}
+
+// getOpenDuplicate reads a duplicate flag a moderator is about to resolve, rejecting one already resolved
+func (service *PropertyService) getOpenDuplicate(commonLogFields []zap.Field, duplicateID uint) (internaldto.PropertyDuplicate, *custom.ErrorResult) {
+	flag, err := service.propertyRepo.GetDuplicateByID(duplicateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.DuplicateNotFoundCode, constant.DuplicateNotFoundMsg, "PropertyDuplicate")
+			return flag, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetDuplicateByIDMethod), logFields...)
+		return flag, buildSelectErrFromRepo("property flag", err)
+	}
+
+	if flag.Status != constant.PropertyDuplicateStatusOpen {
+		errRes := custom.BuildBadReqErrResult(constant.DuplicateResolvedCode, fmt.Sprintf(constant.DuplicateResolvedMsg, flag.Status), "status")
+		return flag, &errRes
+	}
+
+	return flag, nil
+}
+
+// getDuplicate reads a duplicate flag for the response
+func (service *PropertyService) getDuplicate(duplicateID uint) (dto.PropertyDuplicateResponse, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+
+	flag, err := service.propertyRepo.GetDuplicateByID(duplicateID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetDuplicateByIDMethod), logFields...)
+		return dto.PropertyDuplicateResponse{}, buildSelectErrFromRepo("property flag", err)
+	}
+
+	return buildDuplicateResponse(flag), nil
+}
+
+// buildResolveDuplicateErr turns an error resolving a duplicate flag into an error result. The flag was
+// resolved, or the listing to archive changed status, meanwhile when nothing was updated.
+func buildResolveDuplicateErr(commonLogFields []zap.Field, method string, err error) *custom.ErrorResult {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		errRes := custom.BuildBadReqErrResult(constant.StatusChangedCode, constant.StatusChangedMessage, "status")
+		return &errRes
+	}
+	log.Logger.Error(log.TraceMsgErrorOccurredFrom(method), log.TraceError(commonLogFields, err)...)
+	return buildSelectErrFromRepo("property duplicate", err)
+}
+
+// buildDuplicateResponse builds the response for a duplicate flag
+func buildDuplicateResponse(flag internaldto.PropertyDuplicate) dto.PropertyDuplicateResponse {
+	return dto.PropertyDuplicateResponse{
+		ID:            flag.ID,
+		PropertyID:    flag.PropertyID,
+		DuplicateOfID: flag.DuplicateOfID,
+		Score:         flag.Score,
+		Reasons:       strings.Split(flag.Reasons, reasonSeparator),
+		Status:        flag.Status,
+		ResolvedBy:    flag.ResolvedBy,
+		Note:          flag.Note,
+		ResolvedAt:    flag.ResolvedAt,
+		CreatedAt:     flag.CreatedAt,
+	}
+}
+
+// duplicateListing returns what the duplicate detection compares of a property
+func duplicateListing(property dto.Property) duplicate.Listing {
+	images := make([]string, 0, len(property.PropertyImages))
+	for _, image := range property.PropertyImages {
+		images = append(images, image.URL)
+	}
+
+	return duplicate.Listing{
+		ID:        property.ID,
+		Title:     property.Title,
+		Address:   property.Address,
+		City:      property.City,
+		Latitude:  property.Latitude,
+		Longitude: property.Longitude,
+		Price:     property.Price,
+		Images:    images,
+	}
+}
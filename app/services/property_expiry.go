@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/mail"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// Property expiry service methods
+	PropertyServiceRenewMethod               = "PropertyServiceRenew"
+	PropertyServiceExpireListingsMethod      = "PropertyServiceExpireListings"
+	PropertyServiceSendExpiryRemindersMethod = "PropertyServiceSendExpiryReminders"
+	checkRenewalLimitMethod                  = "checkRenewalLimit"
+	sendExpiryReminderMethod                 = "sendExpiryReminder"
+)
+
+// renewableStatuses are the statuses a listing may be renewed in, an expired listing is published again.
+// A draft or rented listing past its period keeps its status and can be published once renewed.
+var renewableStatuses = []string{
+	constant.PropertyStatusDraft,
+	constant.PropertyStatusPublished,
+	constant.PropertyStatusReserved,
+	constant.PropertyStatusRented,
+	constant.PropertyStatusExpired,
+}
+
+// RunListingExpiryJob expires overdue listings and reminds owners of listings about to expire,
+// once at start up and then every configured interval until the context is cancelled
+func RunListingExpiryJob(ctx context.Context) {
+	interval := config.GetConfig().ListingConfig.ExpiryInterval
+	if interval <= 0 {
+		log.Logger.Info(constant.ListingExpiryJobDisabled)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		service := CreatePropertyService(fiberutils.UUIDv4(), nil)
+		now := time.Now()
+		service.ExpireListings(now)
+		service.SendExpiryReminders(now)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Renew extends the listing period of a property by the period of its pricing type, counted from
+// its current expiry while that is still ahead. An expired listing is published again. Only the
+// owner, or a manager of its organization, may renew it, within the renewal limit of the user.
+func (service *PropertyService) Renew(propertyID uint, principal *auth.Principal) (response dto.Property, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceRenewMethod), log.TraceMethodInputs(commonLogFields, propertyID, principal.ID)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceRenewMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceRenewMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if _, _, errResult = service.authorizePropertyOwner(propertyID, principal.ID); errResult != nil {
+		return response, errResult
+	}
+
+	property, errResult := service.getProperty(propertyID)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	if !slices.Contains(renewableStatuses, property.Status) {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidRenewalCode, fmt.Sprintf(constant.InvalidRenewalMsg, property.Status), "status")
+		return response, &errRes
+	}
+
+	if errResult = service.checkRenewalLimit(principal.ID); errResult != nil {
+		return response, errResult
+	}
+
+	err := service.propertyRepo.Renew(property, *listingExpiry(property.PricingType, property.ExpiresAt), principal.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildBadReqErrResult(constant.StatusChangedCode, constant.StatusChangedMessage, "status")
+			return response, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryRenewMethod), logFields...)
+		return response, buildSelectErrFromRepo("property", err)
+	}
+
+	return service.getProperty(propertyID)
+}
+
+// ExpireListings moves the published listings whose period ended by now to expired, batch
+// by batch until none is left, and returns how many were expired
+func (service *PropertyService) ExpireListings(now time.Time) (expired int, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceExpireListingsMethod), log.TraceMethodInputs(commonLogFields, now)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceExpireListingsMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceExpireListingsMethod), log.TraceMethodOutputs(commonLogFields, expired, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	batchSize := config.GetConfig().ListingConfig.ExpiryBatchSize
+	for {
+		properties, err := service.propertyRepo.ListExpired(now, batchSize)
+		if err != nil {
+			return expired, checkRepoError(commonLogFields, repository.PropertyRepositoryListExpiredMethod, err)
+		}
+
+		batchExpired := 0
+		for _, property := range properties {
+			// Changes are made by the service itself, so no user is recorded against them
+			err = service.propertyRepo.ChangeStatus(property.ID, constant.PropertyStatusPublished, constant.PropertyStatusExpired, 0, constant.ListingExpiredNote, nil)
+			if err != nil {
+				// A listing changed meanwhile is left to the next run
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryChangeStatusMethod), log.TraceError(commonLogFields, err)...)
+				}
+				continue
+			}
+			batchExpired++
+		}
+		expired += batchExpired
+
+		// A short batch was the last one; a batch that expired nothing would only be read again
+		if len(properties) < batchSize || batchExpired == 0 {
+			return expired, nil
+		}
+	}
+}
+
+// SendExpiryReminders emails the owners of published listings expiring within the reminder
+// period, once per listing period and one batch per call, and returns how many were sent
+func (service *PropertyService) SendExpiryReminders(now time.Time) (sent int, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceSendExpiryRemindersMethod), log.TraceMethodInputs(commonLogFields, now)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceSendExpiryRemindersMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceSendExpiryRemindersMethod), log.TraceMethodOutputs(commonLogFields, sent, errResult)...)
+	}()
+
+	listingConfig := config.GetConfig().ListingConfig
+	if listingConfig.ExpiryReminder <= 0 {
+		return 0, nil
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	properties, err := service.propertyRepo.ListExpiring(now, now.Add(listingConfig.ExpiryReminder), listingConfig.ExpiryBatchSize)
+	if err != nil {
+		return 0, checkRepoError(commonLogFields, repository.PropertyRepositoryListExpiringMethod, err)
+	}
+
+	for _, property := range properties {
+		// Marking first keeps a reminder from going out twice, at the cost of losing one that fails to send
+		err = service.propertyRepo.MarkExpiryReminded(property.ID, now)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryMarkExpiryRemindedMethod), log.TraceError(commonLogFields, err)...)
+			}
+			continue
+		}
+
+		if err = service.sendExpiryReminder(property, listingConfig.ManageURL); err != nil {
+			logFields := log.TraceError(commonLogFields, err)
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(sendExpiryReminderMethod), append(logFields, zap.Uint("propertyID", property.ID))...)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// checkRenewalLimit rejects a renewal once the user has made the configured number of renewals in the window
+func (service *PropertyService) checkRenewalLimit(userID uint) *custom.ErrorResult {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(checkRenewalLimitMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(checkRenewalLimitMethod), commonLogFields...)
+
+	listingConfig := config.GetConfig().ListingConfig
+	if listingConfig.MaxRenewals <= 0 {
+		return nil
+	}
+
+	count, err := service.propertyRepo.CountRenewalsByUserID(userID, time.Now().Add(-listingConfig.RenewalWindow))
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryCountRenewalsMethod), logFields...)
+		return buildSelectErrFromRepo("property renewal", err)
+	}
+	if count >= int64(listingConfig.MaxRenewals) {
+		errRes := custom.BuildForbiddenErrResult(constant.RenewalLimitCode, fmt.Sprintf(constant.RenewalLimitReachedMsg, listingConfig.MaxRenewals, int(listingConfig.RenewalWindow.Hours()/24)), "Property")
+		return &errRes
+	}
+
+	return nil
+}
+
+// sendExpiryReminder emails the owner of the property that its listing period is about to end
+func (service *PropertyService) sendExpiryReminder(property dto.Property, link string) error {
+	owner, err := service.userRepo.GetUserByID(property.UserID)
+	if err != nil {
+		return err
+	}
+
+	service.mailer, err = mail.NewMailer()
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(mail.ListingExpiryReminderMessage(owner.Email, property.Title, *property.ExpiresAt, link))
+}
+
+// listingExpiry returns the end of a listing period of the pricing type, starting from the current
+// expiry while that is still ahead and from now otherwise
+func listingExpiry(pricingType string, current *time.Time) *time.Time {
+	start := time.Now()
+	if current != nil && current.After(start) {
+		start = *current
+	}
+
+	expiresAt := start.Add(config.GetConfig().ListingConfig.TTL(pricingType))
+	return &expiresAt
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config/listingconfig"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+func TestExpireListingsRunsEveryBatch(t *testing.T) {
+	t.Setenv(listingconfig.ListingExpiryBatchSize, "2")
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+
+	now := time.Now()
+	var overdue []uint
+	for i := 0; i < 5; i++ {
+		property := store.addProperty(owner.ID, nil, constant.PropertyStatusPublished)
+		store.setExpiresAt(property.ID, now.Add(-time.Hour))
+		overdue = append(overdue, property.ID)
+	}
+	current := store.addProperty(owner.ID, nil, constant.PropertyStatusPublished)
+	store.setExpiresAt(current.ID, now.Add(time.Hour))
+
+	expired, errResult := CreatePropertyService("test", nil).ExpireListings(now)
+	if errResult != nil {
+		t.Fatalf("ExpireListings() error = %v", errResult.ErrorList)
+	}
+	if expired != len(overdue) {
+		t.Errorf("ExpireListings() = %d, want %d", expired, len(overdue))
+	}
+	for _, id := range overdue {
+		if status := store.property(id).Status; status != constant.PropertyStatusExpired {
+			t.Errorf("overdue listing %d status = %s, want %s", id, status, constant.PropertyStatusExpired)
+		}
+	}
+	if status := store.property(current.ID).Status; status != constant.PropertyStatusPublished {
+		t.Errorf("current listing status = %s, want %s", status, constant.PropertyStatusPublished)
+	}
+}
+
+func TestGetByIDHidesOverdueListing(t *testing.T) {
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+	stranger := store.addUser("stranger@example.com", "password")
+	property := store.addProperty(owner.ID, nil, constant.PropertyStatusPublished)
+	store.setExpiresAt(property.ID, time.Now().Add(-time.Minute))
+
+	for _, principal := range []*auth.Principal{nil, {ID: stranger.ID}} {
+		_, errResult := CreatePropertyService("test", nil).GetByID(property.ID, principal)
+		assertErrorStatus(t, errResult, http.StatusNotFound)
+		assertErrorCode(t, errResult, constant.PropertyNotFoundCode)
+	}
+
+	if _, errResult := CreatePropertyService("test", nil).GetByID(property.ID, &auth.Principal{ID: owner.ID}); errResult != nil {
+		t.Errorf("GetByID() by the owner error = %v", errResult.ErrorList)
+	}
+}
+
+func TestRenewOverdueListingThenPublish(t *testing.T) {
+	t.Setenv(listingconfig.ListingReviewNew, "false")
+
+	for _, status := range []string{constant.PropertyStatusDraft, constant.PropertyStatusRented} {
+		t.Run(status, func(t *testing.T) {
+			store := setupServiceTest(t)
+			owner := store.addUser("owner@example.com", "password")
+			property := store.addProperty(owner.ID, nil, status)
+			property.PricingType = constant.PricingTypeRent
+			store.setExpiresAt(property.ID, time.Now().Add(-time.Hour))
+			principal := &auth.Principal{ID: owner.ID}
+			publish := dto.PropertyStatusRequest{Status: constant.PropertyStatusPublished}
+
+			_, errResult := CreatePropertyService("test", nil).ChangeStatus(property.ID, principal, publish)
+			assertErrorCode(t, errResult, constant.ListingExpiredCode)
+
+			renewed, errResult := CreatePropertyService("test", nil).Renew(property.ID, principal)
+			if errResult != nil {
+				t.Fatalf("Renew() error = %v", errResult.ErrorList)
+			}
+			if renewed.Status != status {
+				t.Errorf("Renew() status = %s, want %s", renewed.Status, status)
+			}
+			if renewed.ExpiresAt == nil || !renewed.ExpiresAt.After(time.Now()) {
+				t.Errorf("Renew() expires at %v, want a new listing period", renewed.ExpiresAt)
+			}
+
+			published, errResult := CreatePropertyService("test", nil).ChangeStatus(property.ID, principal, publish)
+			if errResult != nil {
+				t.Fatalf("ChangeStatus() after renewing error = %v", errResult.ErrorList)
+			}
+			if published.Status != constant.PropertyStatusPublished {
+				t.Errorf("ChangeStatus() status = %s, want %s", published.Status, constant.PropertyStatusPublished)
+			}
+		})
+	}
+}
+
+func TestApproveOverdueListingStartsNewPeriod(t *testing.T) {
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+	property := store.addProperty(owner.ID, nil, constant.PropertyStatusPendingReview)
+	store.setExpiresAt(property.ID, time.Now().Add(-time.Hour))
+	moderator := &auth.Principal{ID: 900, Permissions: []string{auth.PermissionModerateProperties}}
+
+	approved, errResult := CreatePropertyService("test", nil).Approve(property.ID, moderator, dto.PropertyApprovalRequest{})
+	if errResult != nil {
+		t.Fatalf("Approve() error = %v", errResult.ErrorList)
+	}
+	if approved.Status != constant.PropertyStatusPublished {
+		t.Errorf("Approve() status = %s, want %s", approved.Status, constant.PropertyStatusPublished)
+	}
+	if approved.ExpiresAt == nil || !approved.ExpiresAt.After(time.Now()) {
+		t.Errorf("Approve() expires at %v, want a new listing period", approved.ExpiresAt)
+	}
+}
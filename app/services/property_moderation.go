@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/mail"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// Property moderation service methods
+	PropertyServiceListPendingReviewMethod = "PropertyServiceListPendingReview"
+	PropertyServiceApproveMethod           = "PropertyServiceApprove"
+	PropertyServiceRejectMethod            = "PropertyServiceReject"
+	PropertyServiceModerationHistoryMethod = "PropertyServiceModerationHistory"
+	sendRejectionEmailMethod               = "sendRejectionEmail"
+)
+
+// ListPendingReview lists one page of the moderation queue, longest waiting first
+func (service *PropertyService) ListPendingReview(principal *auth.Principal, request dto.PageRequest) (response []dto.Property, page pagination.Page, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListPendingReviewMethod), log.TraceMethodInputs(commonLogFields, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceListPendingReviewMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceListPendingReviewMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return nil, page, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	properties, page, err := service.propertyRepo.ListPendingReview(request)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListPendingReviewMethod), logFields...)
+		return nil, page, buildListErrFromRepo("properties", err)
+	}
+
+	return properties, page, nil
+}
+
+// Approve publishes a listing under review
+func (service *PropertyService) Approve(propertyID uint, principal *auth.Principal, request dto.PropertyApprovalRequest) (response dto.Property, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceApproveMethod), log.TraceMethodInputs(commonLogFields, propertyID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceApproveMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceApproveMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return response, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	property, errResult := service.getPendingReview(commonLogFields, propertyID, constant.PropertyStatusPublished)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	return service.moderate(property, principal, constant.PropertyStatusPublished, internaldto.PropertyModerationDecision{
+		Decision: constant.ModerationDecisionApproved,
+		Note:     request.Note,
+	})
+}
+
+// Reject sends a listing under review back to its owner with the reason code and note,
+// which are also emailed to the owner
+func (service *PropertyService) Reject(propertyID uint, principal *auth.Principal, request dto.PropertyRejectionRequest) (response dto.Property, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceRejectMethod), log.TraceMethodInputs(commonLogFields, propertyID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceRejectMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceRejectMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return response, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	property, errResult := service.getPendingReview(commonLogFields, propertyID, constant.PropertyStatusRejected)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	response, errResult = service.moderate(property, principal, constant.PropertyStatusRejected, internaldto.PropertyModerationDecision{
+		Decision:   constant.ModerationDecisionRejected,
+		ReasonCode: request.ReasonCode,
+		Note:       request.Note,
+	})
+	if errResult != nil {
+		return response, errResult
+	}
+
+	// The decision stands even when the owner could not be told about it
+	if err := service.sendRejectionEmail(property, request); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(sendRejectionEmailMethod), log.TraceError(commonLogFields, err)...)
+	}
+
+	return response, nil
+}
+
+// ModerationHistory lists every moderation decision on a property, oldest first
+func (service *PropertyService) ModerationHistory(propertyID uint, principal *auth.Principal) (response []dto.PropertyModerationDecisionResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceModerationHistoryMethod), log.TraceMethodInputs(commonLogFields, propertyID)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceModerationHistoryMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceModerationHistoryMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return nil, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.getProperty(propertyID); errResult != nil {
+		return nil, errResult
+	}
+
+	decisions, err := service.propertyRepo.ListModerationDecisions(propertyID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListModerationDecisionsMethod), logFields...)
+		return nil, buildSelectErrFromRepo("property moderation history", err)
+	}
+
+	response = make([]dto.PropertyModerationDecisionResponse, 0, len(decisions))
+	for _, decision := range decisions {
+		response = append(response, dto.PropertyModerationDecisionResponse{
+			Decision:    decision.Decision,
+			ReasonCode:  decision.ReasonCode,
+			Note:        decision.Note,
+			ModeratorID: decision.ModeratorID,
+			CreatedAt:   decision.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// moderate records the decision of the moderator on a listing under review and moves it to the given
+// status. An approved listing that was never published, or whose period ended meanwhile, starts a new
+// listing period.
+func (service *PropertyService) moderate(property dto.Property, principal *auth.Principal, status string, decision internaldto.PropertyModerationDecision) (dto.Property, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+
+	var expiresAt *time.Time
+	if status == constant.PropertyStatusPublished && (property.ExpiresAt == nil || !property.ExpiresAt.After(time.Now())) {
+		expiresAt = listingExpiry(property.PricingType, nil)
+	}
+
+	decision.ModeratorID = principal.ID
+	err := service.propertyRepo.Moderate(property.ID, status, decision, expiresAt)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildBadReqErrResult(constant.StatusChangedCode, constant.StatusChangedMessage, "status")
+			return property, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryModerateMethod), logFields...)
+		return property, buildSelectErrFromRepo("property", err)
+	}
+
+	return service.getProperty(property.ID)
+}
+
+// getPendingReview reads a property that is to be moved to the given status by a moderation decision,
+// rejecting one that is not under review
+func (service *PropertyService) getPendingReview(commonLogFields []zap.Field, propertyID uint, status string) (dto.Property, *custom.ErrorResult) {
+	property, errResult := service.getProperty(propertyID)
+	if errResult != nil {
+		return property, errResult
+	}
+
+	if property.Status != constant.PropertyStatusPendingReview {
+		log.Logger.Warn(fmt.Sprintf(constant.InvalidTransitionMsg, property.Status, status), commonLogFields...)
+		errRes := custom.BuildBadReqErrResult(constant.InvalidTransitionCode, fmt.Sprintf(constant.InvalidTransitionMsg, property.Status, status), "status")
+		return property, &errRes
+	}
+
+	return property, nil
+}
+
+// sendRejectionEmail emails the owner of the property why it was rejected
+func (service *PropertyService) sendRejectionEmail(property dto.Property, request dto.PropertyRejectionRequest) error {
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	owner, err := service.userRepo.GetUserByID(property.UserID)
+	if err != nil {
+		return err
+	}
+
+	service.mailer, err = mail.NewMailer()
+	if err != nil {
+		return err
+	}
+
+	reason := strings.ReplaceAll(request.ReasonCode, "_", " ")
+	link := config.GetConfig().ListingConfig.ManageURL
+	return service.mailer.Send(mail.ListingRejectedMessage(owner.Email, property.Title, reason, request.Note, link))
+}
+
+// editReviewNote is the note a material edit of a published listing is sent back to review
+// with, or empty when edits are not reviewed
+func editReviewNote() string {
+	if !config.GetConfig().ListingConfig.ReviewEdits {
+		return constant.Empty
+	}
+	return constant.ListingEditReviewNote
+}
+
+// isMaterialEdit reports whether the request changes what a moderator approved: the title,
+// description, price or images of the property
+func isMaterialEdit(property dto.Property, request dto.PropertyRequest) bool {
+	if property.Title != request.Title || property.Description != request.Description || property.Price != request.Price {
+		return true
+	}
+
+	images := make([]string, 0, len(property.PropertyImages))
+	for _, image := range property.PropertyImages {
+		images = append(images, image.URL)
+	}
+	requested := slices.Clone(request.Images)
+	slices.Sort(images)
+	slices.Sort(requested)
+
+	return !slices.Equal(images, slices.Compact(requested))
+}
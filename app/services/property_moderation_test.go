@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+)
+
+func TestIsMaterialEdit(t *testing.T) {
+	property := dto.Property{
+		Title:       "Two bedroom apartment in Colombo",
+		Description: "Sea view, close to the station",
+		Price:       25000000,
+		City:        "Colombo",
+		PropertyImages: []dto.PropertyImage{
+			{URL: "https://cdn.example.com/front.jpg"},
+			{URL: "https://cdn.example.com/kitchen.jpg"},
+		},
+	}
+	unchanged := func() dto.PropertyRequest {
+		return dto.PropertyRequest{
+			Title:       property.Title,
+			Description: property.Description,
+			Price:       property.Price,
+			City:        property.City,
+			Images:      []string{"https://cdn.example.com/front.jpg", "https://cdn.example.com/kitchen.jpg"},
+		}
+	}
+
+	tests := []struct {
+		name string
+		edit func(request *dto.PropertyRequest)
+		want bool
+	}{
+		{name: "nothing changed", edit: func(*dto.PropertyRequest) {}},
+		{name: "other field", edit: func(request *dto.PropertyRequest) { request.City = "Kandy" }},
+		{name: "images reordered", edit: func(request *dto.PropertyRequest) {
+			request.Images = []string{"https://cdn.example.com/kitchen.jpg", "https://cdn.example.com/front.jpg"}
+		}},
+		{name: "image repeated", edit: func(request *dto.PropertyRequest) {
+			request.Images = append(request.Images, "https://cdn.example.com/front.jpg")
+		}},
+		{name: "title", edit: func(request *dto.PropertyRequest) { request.Title = "Three bedroom apartment" }, want: true},
+		{name: "description", edit: func(request *dto.PropertyRequest) { request.Description = "" }, want: true},
+		{name: "price", edit: func(request *dto.PropertyRequest) { request.Price = 1 }, want: true},
+		{name: "image added", edit: func(request *dto.PropertyRequest) {
+			request.Images = append(request.Images, "https://cdn.example.com/garden.jpg")
+		}, want: true},
+		{name: "image removed", edit: func(request *dto.PropertyRequest) { request.Images = request.Images[:1] }, want: true},
+		{name: "images cleared", edit: func(request *dto.PropertyRequest) { request.Images = nil }, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := unchanged()
+			tt.edit(&request)
+			if got := isMaterialEdit(property, request); got != tt.want {
+				t.Errorf("isMaterialEdit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
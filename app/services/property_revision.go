@@ -0,0 +1,234 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// Property revision service methods
+	PropertyServiceListRevisionsMethod   = "PropertyServiceListRevisions"
+	PropertyServiceDiffRevisionsMethod   = "PropertyServiceDiffRevisions"
+	PropertyServiceRestoreRevisionMethod = "PropertyServiceRestoreRevision"
+)
+
+// ListRevisions lists every stored revision of a property, oldest first, to those who may manage it
+func (service *PropertyService) ListRevisions(propertyID uint, principal *auth.Principal) (response []dto.PropertyRevisionResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListRevisionsMethod), log.TraceMethodInputs(commonLogFields, propertyID, principal.ID)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceListRevisionsMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceListRevisionsMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if errResult = service.authorizeRevisions(propertyID, principal); errResult != nil {
+		return nil, errResult
+	}
+
+	revisions, err := service.propertyRepo.ListRevisions(propertyID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListRevisionsMethod), logFields...)
+		return nil, buildSelectErrFromRepo("property revisions", err)
+	}
+
+	response = make([]dto.PropertyRevisionResponse, 0, len(revisions))
+	for _, revision := range revisions {
+		var snapshot dto.PropertySnapshot
+		if err = json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+			logFields := log.TraceError(commonLogFields, err)
+			log.Logger.Error(constant.UnexpectedWhenUnmarshalError, append(logFields, zap.Int("version", revision.Version))...)
+		}
+		response = append(response, dto.PropertyRevisionResponse{
+			Version:   revision.Version,
+			ChangedBy: revision.ChangedBy,
+			Note:      revision.Note,
+			CreatedAt: revision.CreatedAt,
+			Snapshot:  snapshot,
+		})
+	}
+
+	return response, nil
+}
+
+// DiffRevisions lists the fields whose values differ between two revisions of a property
+func (service *PropertyService) DiffRevisions(propertyID uint, principal *auth.Principal, request dto.PropertyRevisionDiffRequest) (response dto.PropertyRevisionDiffResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceDiffRevisionsMethod), log.TraceMethodInputs(commonLogFields, propertyID, principal.ID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceDiffRevisionsMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceDiffRevisionsMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if errResult = service.authorizeRevisions(propertyID, principal); errResult != nil {
+		return response, errResult
+	}
+
+	from, errResult := service.getRevisionSnapshot(commonLogFields, propertyID, request.From)
+	if errResult != nil {
+		return response, errResult
+	}
+	to, errResult := service.getRevisionSnapshot(commonLogFields, propertyID, request.To)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	return dto.PropertyRevisionDiffResponse{
+		From:    request.From,
+		To:      request.To,
+		Changes: diffSnapshots(from, to),
+	}, nil
+}
+
+// RestoreRevision brings a property back to the content of an earlier revision. The restore is an
+// ordinary update, so it is reviewed and screened like one and stored as the next revision.
+func (service *PropertyService) RestoreRevision(propertyID uint, version int, principal *auth.Principal) (response dto.Property, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceRestoreRevisionMethod), log.TraceMethodInputs(commonLogFields, propertyID, version, principal.ID)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceRestoreRevisionMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceRestoreRevisionMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	// Only the owner, or a manager of its organization, may restore the property
+	if _, _, errResult = service.authorizePropertyOwner(propertyID, principal.ID); errResult != nil {
+		return response, errResult
+	}
+
+	snapshot, errResult := service.getRevisionSnapshot(commonLogFields, propertyID, version)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	request := snapshotRequest(snapshot)
+	request.RevisionNote = fmt.Sprintf(constant.ListingRestoredNote, version)
+	return service.Update(propertyID, principal.ID, request)
+}
+
+// authorizeRevisions lets moderators, and the owner or a manager of its organization, see the revisions of a property
+func (service *PropertyService) authorizeRevisions(propertyID uint, principal *auth.Principal) *custom.ErrorResult {
+	if principal.HasPermission(auth.PermissionModerateProperties) {
+		_, errResult := service.getProperty(propertyID)
+		return errResult
+	}
+
+	_, _, errResult := service.authorizePropertyOwner(propertyID, principal.ID)
+	return errResult
+}
+
+// getRevisionSnapshot reads the content stored in a revision of a property
+func (service *PropertyService) getRevisionSnapshot(commonLogFields []zap.Field, propertyID uint, version int) (dto.PropertySnapshot, *custom.ErrorResult) {
+	var snapshot dto.PropertySnapshot
+
+	revision, err := service.propertyRepo.GetRevision(propertyID, version)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.RevisionNotFoundCode, fmt.Sprintf(constant.RevisionNotFoundMsg, version), "version")
+			return snapshot, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetRevisionMethod), logFields...)
+		return snapshot, buildSelectErrFromRepo("property revision", err)
+	}
+
+	if err = json.Unmarshal([]byte(revision.Snapshot), &snapshot); err != nil {
+		log.Logger.Error(constant.UnexpectedWhenUnmarshalError, log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.ErrDataUnmarshalCode, constant.UnexpectedWhenUnmarshalError, err.Error())
+		return snapshot, &errRes
+	}
+
+	return snapshot, nil
+}
+
+// diffSnapshots lists the fields that differ between two snapshots, in the order of the snapshot fields,
+// named as in the JSON of the snapshot
+func diffSnapshots(from, to dto.PropertySnapshot) []dto.PropertyFieldChange {
+	changes := make([]dto.PropertyFieldChange, 0)
+
+	fromValue, toValue := reflect.ValueOf(from), reflect.ValueOf(to)
+	snapshotType := fromValue.Type()
+	for i := 0; i < snapshotType.NumField(); i++ {
+		before, after := fromValue.Field(i).Interface(), toValue.Field(i).Interface()
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		field, _, _ := strings.Cut(snapshotType.Field(i).Tag.Get("json"), ",")
+		changes = append(changes, dto.PropertyFieldChange{Field: field, From: before, To: after})
+	}
+
+	return changes
+}
+
+// snapshotRequest builds the update request that brings the content of a property back to a
+// snapshot, leaving out the organization so that the update keeps the current one
+func snapshotRequest(snapshot dto.PropertySnapshot) dto.PropertyRequest {
+	request := dto.PropertyRequest{
+		Title:           snapshot.Title,
+		Description:     snapshot.Description,
+		PurposeID:       int(snapshot.PurposeID),
+		PropertyTypeID:  int(snapshot.PropertyTypeID),
+		FurnitureTypeID: int(snapshot.FurnitureTypeID),
+		ConditionID:     int(snapshot.ConditionID),
+		Bedrooms:        snapshot.Bedrooms,
+		Bathrooms:       snapshot.Bathrooms,
+		Size:            snapshot.Size,
+		SizeUnit:        snapshot.SizeUnit,
+		City:            snapshot.City,
+		Address:         snapshot.Address,
+		PostalCode:      snapshot.PostalCode,
+		Latitude:        snapshot.Latitude,
+		Longitude:       snapshot.Longitude,
+		Price:           snapshot.Price,
+		PriceUnit:       snapshot.PriceUnit,
+		IsNegotiable:    snapshot.IsNegotiable,
+		RentalPeriod:    snapshot.RentalPeriod,
+		IsRefundable:    snapshot.IsRefundable,
+		PricingType:     snapshot.PricingType,
+		AmenityIDs:      make([]int, 0, len(snapshot.AmenityIDs)),
+		UtilityIDs:      make([]int, 0, len(snapshot.UtilityIDs)),
+		Images:          snapshot.Images,
+	}
+	for _, id := range snapshot.AmenityIDs {
+		request.AmenityIDs = append(request.AmenityIDs, int(id))
+	}
+	for _, id := range snapshot.UtilityIDs {
+		request.UtilityIDs = append(request.UtilityIDs, int(id))
+	}
+
+	return request
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+)
+
+// revisionSnapshot is the content of a listing as a revision stores it
+func revisionSnapshot() dto.PropertySnapshot {
+	organizationID := uint(4)
+	return dto.PropertySnapshot{
+		OrganizationID:  &organizationID,
+		Title:           "Two bedroom apartment in Colombo",
+		Description:     "Sea view, close to the station",
+		PurposeID:       1,
+		PropertyTypeID:  2,
+		FurnitureTypeID: 3,
+		ConditionID:     1,
+		Bedrooms:        2,
+		Bathrooms:       1,
+		Size:            950,
+		SizeUnit:        "sqft",
+		City:            "Colombo",
+		Address:         "12 Galle Road",
+		PostalCode:      "00300",
+		Latitude:        6.9271,
+		Longitude:       79.8612,
+		Price:           25000000,
+		PriceUnit:       "LKR",
+		IsNegotiable:    true,
+		RentalPeriod:    "",
+		IsRefundable:    false,
+		PricingType:     "sell",
+		AmenityIDs:      []uint{1, 5},
+		UtilityIDs:      []uint{2},
+		Images:          []string{"https://cdn.example.com/front.jpg", "https://cdn.example.com/kitchen.jpg"},
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(snapshot *dto.PropertySnapshot)
+		want []dto.PropertyFieldChange
+	}{
+		{name: "nothing changed", edit: func(*dto.PropertySnapshot) {}, want: []dto.PropertyFieldChange{}},
+		{name: "fields in snapshot order", edit: func(snapshot *dto.PropertySnapshot) {
+			snapshot.IsNegotiable = false
+			snapshot.Title = "Three bedroom apartment in Colombo"
+		}, want: []dto.PropertyFieldChange{
+			{Field: "title", From: "Two bedroom apartment in Colombo", To: "Three bedroom apartment in Colombo"},
+			{Field: "is_negotiable", From: true, To: false},
+		}},
+		{name: "organization cleared", edit: func(snapshot *dto.PropertySnapshot) { snapshot.OrganizationID = nil },
+			want: []dto.PropertyFieldChange{{Field: "organization_id", From: revisionSnapshot().OrganizationID, To: (*uint)(nil)}}},
+		{name: "description cleared", edit: func(snapshot *dto.PropertySnapshot) { snapshot.Description = "" },
+			want: []dto.PropertyFieldChange{{Field: "description", From: "Sea view, close to the station", To: ""}}},
+		{name: "primary image changed", edit: func(snapshot *dto.PropertySnapshot) {
+			snapshot.Images = []string{"https://cdn.example.com/kitchen.jpg", "https://cdn.example.com/front.jpg"}
+		}, want: []dto.PropertyFieldChange{{
+			Field: "images",
+			From:  []string{"https://cdn.example.com/front.jpg", "https://cdn.example.com/kitchen.jpg"},
+			To:    []string{"https://cdn.example.com/kitchen.jpg", "https://cdn.example.com/front.jpg"},
+		}}},
+		{name: "amenity added", edit: func(snapshot *dto.PropertySnapshot) { snapshot.AmenityIDs = []uint{1, 5, 7} },
+			want: []dto.PropertyFieldChange{{Field: "amenity_ids", From: []uint{1, 5}, To: []uint{1, 5, 7}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := revisionSnapshot()
+			tt.edit(&to)
+
+			if got := diffSnapshots(revisionSnapshot(), to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffSnapshots() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotRequest(t *testing.T) {
+	snapshot := revisionSnapshot()
+
+	// The organization is left out, a restore keeps the current one
+	want := dto.PropertyRequest{
+		Title:           snapshot.Title,
+		Description:     snapshot.Description,
+		PurposeID:       1,
+		PropertyTypeID:  2,
+		FurnitureTypeID: 3,
+		ConditionID:     1,
+		Bedrooms:        2,
+		Bathrooms:       1,
+		Size:            950,
+		SizeUnit:        "sqft",
+		City:            "Colombo",
+		Address:         "12 Galle Road",
+		PostalCode:      "00300",
+		Latitude:        6.9271,
+		Longitude:       79.8612,
+		Price:           25000000,
+		PriceUnit:       "LKR",
+		IsNegotiable:    true,
+		PricingType:     "sell",
+		AmenityIDs:      []int{1, 5},
+		UtilityIDs:      []int{2},
+		Images:          snapshot.Images,
+	}
+	if got := snapshotRequest(snapshot); !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshotRequest() = %+v\nwant %+v", got, want)
+	}
+
+	// A snapshot without amenities or utilities restores them as cleared, not as left alone
+	snapshot.AmenityIDs, snapshot.UtilityIDs = nil, nil
+	got := snapshotRequest(snapshot)
+	if got.AmenityIDs == nil || len(got.AmenityIDs) != 0 || got.UtilityIDs == nil || len(got.UtilityIDs) != 0 {
+		t.Errorf("snapshotRequest() amenities = %#v, utilities = %#v, want empty lists", got.AmenityIDs, got.UtilityIDs)
+	}
+}
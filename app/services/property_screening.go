@@ -0,0 +1,131 @@
+package services
+
+import (
+	"encoding/json"
+	"runtime/debug"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/screening"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Property screening service methods
+	PropertyServiceScreeningHistoryMethod = "PropertyServiceScreeningHistory"
+	screenListingMethod                   = "screenListing"
+	saveScreeningMethod                   = "saveScreening"
+)
+
+// screener holds the content screening rules, compiled once at start up by InitScreener
+var screener *screening.Screener
+
+// InitScreener compiles the content screening rules of the config; a rule that does not compile stops the start
+func InitScreener() {
+	var err error
+	screener, err = screening.NewScreener(config.GetConfig().ScreeningConfig)
+	if err != nil {
+		log.Logger.Fatal(constant.ScreeningInitFailError, zap.Error(err))
+	}
+}
+
+// ScreeningHistory lists every content screening that flagged a property, oldest first
+func (service *PropertyService) ScreeningHistory(propertyID uint, principal *auth.Principal) (response []dto.PropertyScreeningResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceScreeningHistoryMethod), log.TraceMethodInputs(commonLogFields, propertyID)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceScreeningHistoryMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceScreeningHistoryMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	if errResult = requirePermission(principal, auth.PermissionModerateProperties); errResult != nil {
+		return nil, errResult
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if _, errResult = service.getProperty(propertyID); errResult != nil {
+		return nil, errResult
+	}
+
+	screenings, err := service.propertyRepo.ListScreenings(propertyID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListScreeningsMethod), logFields...)
+		return nil, buildSelectErrFromRepo("property screening history", err)
+	}
+
+	response = make([]dto.PropertyScreeningResponse, 0, len(screenings))
+	for _, screening := range screenings {
+		var matches []dto.ScreeningMatch
+		if err = json.Unmarshal([]byte(screening.Matches), &matches); err != nil {
+			logFields := log.TraceError(commonLogFields, err)
+			log.Logger.Error(constant.UnexpectedWhenUnmarshalError, append(logFields, zap.Uint("screeningID", screening.ID))...)
+		}
+		response = append(response, dto.PropertyScreeningResponse{
+			Score:     screening.Score,
+			Matches:   matches,
+			CreatedAt: screening.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// screenListing runs the content screening over the text of a listing. It reports whether the
+// risk score is high enough to send the listing to manual review; with screening off nothing matches.
+func (service *PropertyService) screenListing(listing screening.Listing) (screening.Result, bool) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(screenListingMethod), log.TraceMethodInputs(commonLogFields, listing)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(screenListingMethod), commonLogFields...)
+
+	screeningConfig := config.GetConfig().ScreeningConfig
+	if !screeningConfig.Enabled {
+		return screening.Result{}, false
+	}
+
+	result := screener.Screen(listing)
+	return result, screeningConfig.ReviewScore > 0 && result.Score >= screeningConfig.ReviewScore
+}
+
+// saveScreening records the rules a listing matched for the moderators. The listing change stands
+// even when the record could not be saved, so a failure is only logged.
+func (service *PropertyService) saveScreening(propertyID uint, result screening.Result) {
+	if len(result.Matches) == 0 {
+		return
+	}
+
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+
+	matches, err := json.Marshal(result.Matches)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(saveScreeningMethod), log.TraceError(commonLogFields, err)...)
+		return
+	}
+
+	err = service.propertyRepo.CreateScreening(&internaldto.PropertyScreening{
+		PropertyID: propertyID,
+		Score:      result.Score,
+		Matches:    string(matches),
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryCreateScreeningMethod), log.TraceError(commonLogFields, err)...)
+	}
+}
+
+// screeningListing returns the screened text and price of a property request
+func screeningListing(request dto.PropertyRequest) screening.Listing {
+	return screening.Listing{Title: request.Title, Description: request.Description, Price: request.Price}
+}
@@ -1,12 +1,20 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"runtime/debug"
 
 	"github.com/chazool/serendib_asia_service/app/repository"
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/mail"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/screening"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
 	"gorm.io/gorm"
@@ -15,11 +23,15 @@ import (
 const (
 	// Property service methods
 	PropertyServiceCreateMethod       = "PropertyServiceCreate"
+	checkListingLimitMethod           = "checkListingLimit"
 	PropertyServiceGetByIDMethod      = "PropertyServiceGetByID"
 	PropertyServiceUpdateMethod       = "PropertyServiceUpdate"
 	PropertyServiceDeleteMethod       = "PropertyServiceDelete"
 	PropertyServiceListMethod         = "PropertyServiceList"
 	PropertyServiceListByUserIDMethod = "PropertyServiceListByUserID"
+	PropertyServiceMapMethod          = "PropertyServiceMap"
+	authorizePropertyOwnerMethod      = "authorizePropertyOwner"
+	checkOrganizationMemberMethod     = "checkOrganizationMember"
 )
 
 // PropertyService defines the interface for property service methods.
@@ -29,6 +41,9 @@ type PropertyService struct {
 	transaction    *gorm.DB
 	propertyRepo   repository.PropertyRepository
 	userRepo       repository.UserRepository
+	roleRepo       repository.RoleRepository
+	orgRepo        repository.OrganizationRepository
+	mailer         mail.Mailer
 }
 
 // CreatePropertyService creates a new instance of PropertyService.
@@ -41,8 +56,8 @@ func CreatePropertyService(requestID string, transactionDB *gorm.DB) *PropertySe
 	}
 }
 
-// Create creates a new property
-func (service *PropertyService) Create(request dto.PropertyRequest) (response *dto.Property, errResult *custom.ErrorResult) {
+// Create creates a new property owned by the given user
+func (service *PropertyService) Create(userID uint, request dto.PropertyRequest) (response *dto.Property, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceCreateMethod), log.TraceMethodInputs(commonLogFields, request)...)
 
@@ -55,7 +70,15 @@ func (service *PropertyService) Create(request dto.PropertyRequest) (response *d
 		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceCreateMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.propertyRepo = repository.CreatePropertyRepository(service.serviceContext.RequestID)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	request.UserID = userID
+	if request.Status == constant.Empty {
+		request.Status = constant.PropertyStatusPublished
+	}
+	// With review on, a new listing waits in the moderation queue and its period starts on approval
+	if request.Status == constant.PropertyStatusPublished && config.GetConfig().ListingConfig.ReviewNew {
+		request.Status = constant.PropertyStatusPendingReview
+	}
 
 	// Validate images count
 	if len(request.Images) == 0 {
@@ -67,6 +90,26 @@ func (service *PropertyService) Create(request dto.PropertyRequest) (response *d
 		return nil, &errRes
 	}
 
+	// A risky listing waits for a moderator even when new listings are not reviewed
+	screened, risky := service.screenListing(screeningListing(request))
+	if request.Status == constant.PropertyStatusPublished && risky {
+		request.Status = constant.PropertyStatusPendingReview
+	}
+	if request.Status == constant.PropertyStatusPublished {
+		request.ExpiresAt = listingExpiry(request.PricingType, nil)
+	}
+
+	// Listing for an organization requires membership, whatever the role
+	if request.OrganizationID != nil {
+		if _, errResult = service.checkOrganizationMember(*request.OrganizationID, userID); errResult != nil {
+			return nil, errResult
+		}
+	}
+
+	if errResult = service.checkListingLimit(userID); errResult != nil {
+		return nil, errResult
+	}
+
 	// Create property
 	propertyID, err := service.propertyRepo.Create(request)
 	if err != nil {
@@ -74,6 +117,8 @@ func (service *PropertyService) Create(request dto.PropertyRequest) (response *d
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryCreateMethod), logFields...)
 		return nil, buildSelectErrFromRepo("property", err)
 	}
+	service.saveScreening(propertyID, screened)
+	service.detectDuplicates(propertyID)
 
 	property, err := service.propertyRepo.GetByID(propertyID)
 	if err != nil {
@@ -85,8 +130,42 @@ func (service *PropertyService) Create(request dto.PropertyRequest) (response *d
 	return &property, nil
 }
 
-// GetByID retrieves a property by ID
-func (service *PropertyService) GetByID(propertyID uint) (response dto.Property, errResult *custom.ErrorResult) {
+// checkListingLimit rejects a new listing once the user has reached the
+// listing limit of their roles. Sold, rented, expired and archived listings do not count.
+func (service *PropertyService) checkListingLimit(userID uint) *custom.ErrorResult {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(checkListingLimitMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(checkListingLimitMethod), commonLogFields...)
+
+	service.roleRepo = createRoleRepository(service.serviceContext.RequestID)
+
+	limit, err := service.roleRepo.GetListingLimit(userID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.RoleRepositoryGetListingLimitMethod), logFields...)
+		return buildSelectErrFromRepo("listing limit", err)
+	}
+	if limit == 0 {
+		return nil
+	}
+
+	count, err := service.propertyRepo.CountByUserID(userID, activePropertyStatuses...)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryCountByUserMethod), logFields...)
+		return buildSelectErrFromRepo("property", err)
+	}
+	if count >= int64(limit) {
+		errRes := custom.BuildForbiddenErrResult(constant.ListingLimitCode, fmt.Sprintf(constant.ListingLimitReachedMsg, limit), "Property")
+		return &errRes
+	}
+
+	return nil
+}
+
+// GetByID retrieves a property by ID. Listings that are not published are only found for the
+// principal when they may manage them or moderate listings; principal is nil for anonymous requests.
+func (service *PropertyService) GetByID(propertyID uint, principal *auth.Principal) (response dto.Property, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceGetByIDMethod), log.TraceMethodInputs(commonLogFields, propertyID)...)
 
@@ -99,21 +178,15 @@ func (service *PropertyService) GetByID(propertyID uint) (response dto.Property,
 		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceGetByIDMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.propertyRepo = repository.CreatePropertyRepository(service.serviceContext.RequestID)
-	property, err := service.propertyRepo.GetByID(propertyID)
-	if err != nil {
-		logFields := log.TraceError(commonLogFields, err)
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetByIDMethod), logFields...)
-		return response, buildSelectErrFromRepo("property", err)
-	}
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
 
-	return property, nil
+	return service.getVisibleProperty(propertyID, principal)
 }
 
-// Update updates a property
-func (service *PropertyService) Update(propertyID uint, request dto.PropertyRequest) (response dto.Property, errResult *custom.ErrorResult) {
+// Update updates a property of the given user
+func (service *PropertyService) Update(propertyID, userID uint, request dto.PropertyRequest) (response dto.Property, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceUpdateMethod), log.TraceMethodInputs(commonLogFields, propertyID, request)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceUpdateMethod), log.TraceMethodInputs(commonLogFields, propertyID, userID, request)...)
 
 	defer func() {
 		// Panic handling
@@ -124,13 +197,53 @@ func (service *PropertyService) Update(propertyID uint, request dto.PropertyRequ
 		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceUpdateMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.propertyRepo = repository.CreatePropertyRepository(service.serviceContext.RequestID)
-	err := service.propertyRepo.Update(propertyID, request)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	// Only the owner, or a manager of its organization, may update the property
+	ownerID, organizationID, errResult := service.authorizePropertyOwner(propertyID, userID)
+	if errResult != nil {
+		return response, errResult
+	}
+	// A request without an organization keeps the current one; moving the listing to another
+	// organization requires membership of that one too
+	if request.OrganizationID != nil && (organizationID == nil || *organizationID != *request.OrganizationID) {
+		if _, errResult = service.checkOrganizationMember(*request.OrganizationID, userID); errResult != nil {
+			return response, errResult
+		}
+	}
+	// The listing stays with the user who created it when someone else in the organization edits it
+	request.UserID = ownerID
+
+	current, errResult := service.getProperty(propertyID)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	// Only an edit of what was screened before is screened again
+	materialEdit := isMaterialEdit(current, request)
+	var screened screening.Result
+	var risky bool
+	if materialEdit {
+		screened, risky = service.screenListing(screeningListing(request))
+	}
+
+	// A material edit takes a published listing off the site until a moderator has looked at it again,
+	// as does an edit the content screening finds risky
+	reviewNote := constant.Empty
+	if risky {
+		reviewNote = constant.ListingScreeningReviewNote
+	} else if materialEdit {
+		reviewNote = editReviewNote()
+	}
+
+	err := service.propertyRepo.Update(propertyID, request, userID, reviewNote)
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryUpdateMethod), logFields...)
 		return response, buildSelectErrFromRepo("property", err)
 	}
+	service.saveScreening(propertyID, screened)
+	service.detectDuplicates(propertyID)
 
 	property, err := service.propertyRepo.GetByID(propertyID)
 	if err != nil {
@@ -142,10 +255,10 @@ func (service *PropertyService) Update(propertyID uint, request dto.PropertyRequ
 	return property, nil
 }
 
-// Delete deletes a property
-func (service *PropertyService) Delete(propertyID uint) (response dto.Property, errResult *custom.ErrorResult) {
+// Delete deletes a property of the given user
+func (service *PropertyService) Delete(propertyID, userID uint) (response dto.Property, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceDeleteMethod), log.TraceMethodInputs(commonLogFields, propertyID)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceDeleteMethod), log.TraceMethodInputs(commonLogFields, propertyID, userID)...)
 
 	defer func() {
 		// Panic handling
@@ -156,7 +269,13 @@ func (service *PropertyService) Delete(propertyID uint) (response dto.Property,
 		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceDeleteMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.propertyRepo = repository.CreatePropertyRepository(service.serviceContext.RequestID)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	// Only the owner, or a manager of its organization, may delete the property
+	if _, _, errResult = service.authorizePropertyOwner(propertyID, userID); errResult != nil {
+		return response, errResult
+	}
+
 	property, err := service.propertyRepo.GetByID(propertyID)
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
@@ -174,10 +293,10 @@ func (service *PropertyService) Delete(propertyID uint) (response dto.Property,
 	return property, nil
 }
 
-// List lists properties with pagination
-func (service *PropertyService) List(offset, limit int) (response []dto.Property, errResult *custom.ErrorResult) {
+// List lists one page of the properties matching the filter
+func (service *PropertyService) List(filter dto.PropertyFilterRequest) (response []dto.Property, page pagination.Page, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListMethod), log.TraceMethodInputs(commonLogFields, offset, limit)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListMethod), log.TraceMethodInputs(commonLogFields, filter)...)
 
 	defer func() {
 		// Panic handling
@@ -188,21 +307,72 @@ func (service *PropertyService) List(offset, limit int) (response []dto.Property
 		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceListMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.propertyRepo = repository.CreatePropertyRepository(service.serviceContext.RequestID)
-	properties, err := service.propertyRepo.List(offset, limit)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	properties, page, err := service.propertyRepo.List(filter)
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListMethod), logFields...)
-		return nil, buildSelectErrFromRepo("properties", err)
+		return nil, page, buildListErrFromRepo("properties", err)
+	}
+
+	return properties, page, nil
+}
+
+// Map returns the listings matching the filter inside the viewport, grouped into grid clusters
+// below constant.MapListingsMinZoom and as individual listings from it
+func (service *PropertyService) Map(request dto.PropertyMapRequest) (response dto.PropertyMapResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceMapMethod), log.TraceMethodInputs(commonLogFields, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceMapMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceMapMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	response.Zoom = request.Zoom
+
+	if request.Zoom >= constant.MapListingsMinZoom {
+		// One extra row tells whether the viewport holds more listings than are returned
+		pins, err := service.propertyRepo.ListPins(request.PropertyFilterRequest, constant.MaxMapListings+1)
+		if err != nil {
+			logFields := log.TraceError(commonLogFields, err)
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListPinsMethod), logFields...)
+			return response, buildSelectErrFromRepo("properties", err)
+		}
+		if len(pins) > constant.MaxMapListings {
+			pins = pins[:constant.MaxMapListings]
+			response.Truncated = true
+		}
+		response.Listings = pins
+		return response, nil
+	}
+
+	clusters, err := service.propertyRepo.ListClusters(request.PropertyFilterRequest, mapCellSize(request.Zoom))
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListClustersMethod), logFields...)
+		return response, buildSelectErrFromRepo("properties", err)
 	}
+	response.Clusters = clusters
+
+	return response, nil
+}
 
-	return properties, nil
+// mapCellSize returns the cluster cell size in degrees for a zoom level; a 256px tile
+// spans 360/2^zoom degrees of longitude
+func mapCellSize(zoom int) float64 {
+	return 360 / float64(int(1)<<zoom) / constant.MapCellsPerTile
 }
 
-// ListByUserID lists properties for a specific user with pagination
-func (service *PropertyService) ListByUserID(userID uint, offset, limit int) (response []dto.Property, errResult *custom.ErrorResult) {
+// ListByUserID lists one page of the published properties of a specific user
+func (service *PropertyService) ListByUserID(userID uint, request dto.PageRequest) (response []dto.Property, page pagination.Page, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
-	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListByUserIDMethod), log.TraceMethodInputs(commonLogFields, userID, offset, limit)...)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListByUserIDMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
 
 	defer func() {
 		// Panic handling
@@ -213,13 +383,76 @@ func (service *PropertyService) ListByUserID(userID uint, offset, limit int) (re
 		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceListByUserIDMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.propertyRepo = repository.CreatePropertyRepository(service.serviceContext.RequestID)
-	properties, err := service.propertyRepo.ListByUserID(userID, offset, limit)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	properties, page, err := service.propertyRepo.ListPublicByUserID(userID, request)
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
-		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListMethod), logFields...)
-		return nil, buildSelectErrFromRepo("properties", err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListPublicByUserMethod), logFields...)
+		return nil, page, buildListErrFromRepo("properties", err)
 	}
 
-	return properties, nil
+	return properties, page, nil
+}
+
+// authorizePropertyOwner checks that the property exists and the given user may manage it:
+// the user who listed it or, for an organization listing, an owner or manager of the
+// organization. Agents manage the organization listings they created while they remain
+// members. It returns the user who listed the property and its organization.
+func (service *PropertyService) authorizePropertyOwner(propertyID, userID uint) (uint, *uint, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(authorizePropertyOwnerMethod), log.TraceMethodInputs(commonLogFields, propertyID, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(authorizePropertyOwnerMethod), commonLogFields...)
+
+	if service.propertyRepo == nil {
+		service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	}
+
+	ownerID, organizationID, err := service.propertyRepo.GetOwnership(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.PropertyNotFoundCode, constant.PropertyNotFoundMessage, "Property")
+			return 0, nil, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetOwnershipMethod), logFields...)
+		return 0, nil, buildSelectErrFromRepo("property", err)
+	}
+
+	notOwnerErr := custom.BuildForbiddenErrResult(constant.NotPropertyOwnerCode, constant.NotPropertyOwnerMessage, "Property")
+
+	if organizationID == nil {
+		if ownerID != userID {
+			log.Logger.Warn(constant.NotPropertyOwnerMessage, log.TraceMethodInputs(commonLogFields, propertyID, userID)...)
+			return 0, nil, &notOwnerErr
+		}
+		return ownerID, nil, nil
+	}
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+	member, err := service.orgRepo.GetMember(*organizationID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Logger.Warn(constant.NotPropertyOwnerMessage, log.TraceMethodInputs(commonLogFields, propertyID, userID)...)
+			return 0, nil, &notOwnerErr
+		}
+		return 0, nil, checkRepoError(commonLogFields, repository.OrganizationRepositoryGetMemberMethod, err)
+	}
+	if member.Role == auth.OrganizationRoleAgent && ownerID != userID {
+		log.Logger.Warn(constant.NotPropertyOwnerMessage, log.TraceMethodInputs(commonLogFields, propertyID, userID)...)
+		return 0, nil, &notOwnerErr
+	}
+
+	return ownerID, organizationID, nil
+}
+
+// checkOrganizationMember returns the membership of the user in the organization,
+// a not found error for an unknown organization and forbidden for non-members
+func (service *PropertyService) checkOrganizationMember(organizationID, userID uint) (*internaldto.OrganizationMember, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(checkOrganizationMemberMethod), log.TraceMethodInputs(commonLogFields, organizationID, userID)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(checkOrganizationMemberMethod), commonLogFields...)
+
+	service.orgRepo = createOrganizationRepository(service.serviceContext.RequestID)
+
+	return getOrganizationMember(service.orgRepo, commonLogFields, organizationID, userID)
 }
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+func TestDeletePropertyOwnership(t *testing.T) {
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+	stranger := store.addUser("stranger@example.com", "password")
+	property := store.addProperty(owner.ID, nil, constant.PropertyStatusPublished)
+
+	_, errResult := CreatePropertyService("test", nil).Delete(property.ID, stranger.ID)
+	assertErrorStatus(t, errResult, http.StatusForbidden)
+	assertErrorCode(t, errResult, constant.NotPropertyOwnerCode)
+	if store.property(property.ID) == nil {
+		t.Fatalf("Delete() by another user removed the listing")
+	}
+
+	if _, errResult = CreatePropertyService("test", nil).Delete(property.ID, owner.ID); errResult != nil {
+		t.Fatalf("Delete() by the owner error = %v", errResult.ErrorList)
+	}
+	if store.property(property.ID) != nil {
+		t.Errorf("Delete() by the owner kept the listing")
+	}
+}
+
+func TestDeletePropertyUnknown(t *testing.T) {
+	store := setupServiceTest(t)
+	owner := store.addUser("owner@example.com", "password")
+
+	_, errResult := CreatePropertyService("test", nil).Delete(404, owner.ID)
+	assertErrorStatus(t, errResult, http.StatusNotFound)
+	assertErrorCode(t, errResult, constant.PropertyNotFoundCode)
+}
+
+func TestDeleteOrganizationProperty(t *testing.T) {
+	tests := []struct {
+		name        string
+		role        string
+		createdBy   bool
+		wantAllowed bool
+	}{
+		{name: "manager", role: auth.OrganizationRoleManager, wantAllowed: true},
+		{name: "agent on own listing", role: auth.OrganizationRoleAgent, createdBy: true, wantAllowed: true},
+		{name: "agent on another agent's listing", role: auth.OrganizationRoleAgent},
+		{name: "creator who left the organization", createdBy: true},
+		{name: "not a member"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupServiceTest(t)
+			owner := store.addUser("owner@example.com", "password")
+			organization := store.addOrganization(owner.ID)
+			actor := store.addUser("actor@example.com", "password")
+			if tt.role != "" {
+				store.addMember(organization.ID, actor.ID, tt.role)
+			}
+			creatorID := owner.ID
+			if tt.createdBy {
+				creatorID = actor.ID
+			}
+			property := store.addProperty(creatorID, &organization.ID, constant.PropertyStatusPublished)
+
+			_, errResult := CreatePropertyService("test", nil).Delete(property.ID, actor.ID)
+			if tt.wantAllowed {
+				if errResult != nil {
+					t.Fatalf("Delete() error = %v", errResult.ErrorList)
+				}
+				return
+			}
+			assertErrorStatus(t, errResult, http.StatusForbidden)
+			assertErrorCode(t, errResult, constant.NotPropertyOwnerCode)
+			if store.property(property.ID) == nil {
+				t.Errorf("rejected Delete() removed the listing")
+			}
+		})
+	}
+}
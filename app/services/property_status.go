@@ -0,0 +1,299 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/screening"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// Property status service methods
+	PropertyServiceChangeStatusMethod  = "PropertyServiceChangeStatus"
+	PropertyServiceStatusHistoryMethod = "PropertyServiceStatusHistory"
+	PropertyServiceListMineMethod      = "PropertyServiceListMine"
+	checkStatusTransitionMethod        = "checkStatusTransition"
+)
+
+// propertyStatusTransitions lists the statuses a listing may move to from each status
+var propertyStatusTransitions = map[string][]string{
+	constant.PropertyStatusDraft:         {constant.PropertyStatusPendingReview, constant.PropertyStatusPublished, constant.PropertyStatusArchived},
+	constant.PropertyStatusPendingReview: {constant.PropertyStatusDraft, constant.PropertyStatusRejected, constant.PropertyStatusPublished, constant.PropertyStatusArchived},
+	constant.PropertyStatusRejected:      {constant.PropertyStatusDraft, constant.PropertyStatusPendingReview, constant.PropertyStatusArchived},
+	constant.PropertyStatusPublished:     {constant.PropertyStatusDraft, constant.PropertyStatusReserved, constant.PropertyStatusSold, constant.PropertyStatusRented, constant.PropertyStatusExpired, constant.PropertyStatusArchived},
+	constant.PropertyStatusReserved:      {constant.PropertyStatusPublished, constant.PropertyStatusSold, constant.PropertyStatusRented, constant.PropertyStatusArchived},
+	constant.PropertyStatusSold:          {constant.PropertyStatusArchived},
+	// A rental can be offered again once the tenancy ends
+	constant.PropertyStatusRented:   {constant.PropertyStatusPublished, constant.PropertyStatusArchived},
+	constant.PropertyStatusExpired:  {constant.PropertyStatusDraft, constant.PropertyStatusArchived},
+	constant.PropertyStatusArchived: {constant.PropertyStatusDraft},
+}
+
+// activePropertyStatuses are the statuses that count towards the listing limit of a user
+var activePropertyStatuses = []string{
+	constant.PropertyStatusDraft,
+	constant.PropertyStatusPendingReview,
+	constant.PropertyStatusPublished,
+	constant.PropertyStatusReserved,
+}
+
+// ChangeStatus moves a property to another status. The owner, or a manager of its organization,
+// may make any allowed transition except approving a listing under review and expiring it;
+// moderators may make every allowed transition on any listing. While new listings are reviewed,
+// an owner publishing a draft submits it to the moderation queue instead.
+func (service *PropertyService) ChangeStatus(propertyID uint, principal *auth.Principal, request dto.PropertyStatusRequest) (response dto.Property, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceChangeStatusMethod), log.TraceMethodInputs(commonLogFields, propertyID, principal.ID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceChangeStatusMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceChangeStatusMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	moderator := principal.HasPermission(auth.PermissionModerateProperties)
+	if !moderator {
+		if _, _, errResult = service.authorizePropertyOwner(propertyID, principal.ID); errResult != nil {
+			return response, errResult
+		}
+	}
+
+	property, errResult := service.getProperty(propertyID)
+	if errResult != nil {
+		return response, errResult
+	}
+
+	if !moderator && property.Status == constant.PropertyStatusDraft && request.Status == constant.PropertyStatusPublished &&
+		config.GetConfig().ListingConfig.ReviewNew {
+		request.Status = constant.PropertyStatusPendingReview
+	}
+
+	// An owner publishing a draft has its text screened first, a risky listing waits for a moderator
+	var screened screening.Result
+	if !moderator && property.Status == constant.PropertyStatusDraft && request.Status == constant.PropertyStatusPublished {
+		var risky bool
+		screened, risky = service.screenListing(screening.Listing{Title: property.Title, Description: property.Description, Price: property.Price})
+		if risky {
+			request.Status = constant.PropertyStatusPendingReview
+			if request.Note == constant.Empty {
+				request.Note = constant.ListingScreeningReviewNote
+			}
+		}
+	}
+
+	if errResult = checkStatusTransition(commonLogFields, property, request.Status, moderator); errResult != nil {
+		return response, errResult
+	}
+
+	// A moderator publishing a listing under review approves it
+	if property.Status == constant.PropertyStatusPendingReview && request.Status == constant.PropertyStatusPublished {
+		return service.moderate(property, principal, constant.PropertyStatusPublished, internaldto.PropertyModerationDecision{
+			Decision: constant.ModerationDecisionApproved,
+			Note:     request.Note,
+		})
+	}
+
+	// The listing period starts when a listing is published for the first time
+	var expiresAt *time.Time
+	if request.Status == constant.PropertyStatusPublished && property.ExpiresAt == nil {
+		expiresAt = listingExpiry(property.PricingType, nil)
+	}
+
+	err := service.propertyRepo.ChangeStatus(propertyID, property.Status, request.Status, principal.ID, request.Note, expiresAt)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildBadReqErrResult(constant.StatusChangedCode, constant.StatusChangedMessage, "status")
+			return response, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryChangeStatusMethod), logFields...)
+		return response, buildSelectErrFromRepo("property", err)
+	}
+	service.saveScreening(propertyID, screened)
+
+	return service.getProperty(propertyID)
+}
+
+// StatusHistory lists every status transition of a property, oldest first, to those who may manage it
+func (service *PropertyService) StatusHistory(propertyID uint, principal *auth.Principal) (response []dto.PropertyStatusChangeResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceStatusHistoryMethod), log.TraceMethodInputs(commonLogFields, propertyID, principal.ID)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceStatusHistoryMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceStatusHistoryMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	if !principal.HasPermission(auth.PermissionModerateProperties) {
+		if _, _, errResult = service.authorizePropertyOwner(propertyID, principal.ID); errResult != nil {
+			return nil, errResult
+		}
+	}
+
+	changes, err := service.propertyRepo.ListStatusChanges(propertyID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListStatusChangesMethod), logFields...)
+		return nil, buildSelectErrFromRepo("property status history", err)
+	}
+
+	response = make([]dto.PropertyStatusChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		response = append(response, dto.PropertyStatusChangeResponse{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			ChangedBy:  change.ChangedBy,
+			Note:       change.Note,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// ListMine lists one page of the properties listed by the user in every status, or in the requested one
+func (service *PropertyService) ListMine(userID uint, request dto.MyPropertiesRequest) (response []dto.Property, page pagination.Page, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(PropertyServiceListMineMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
+
+	defer func() {
+		// Panic handling
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(PropertyServiceListMineMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(PropertyServiceListMineMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	var statuses []string
+	if request.Status != constant.Empty {
+		statuses = append(statuses, request.Status)
+	}
+
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	properties, page, err := service.propertyRepo.ListByUserID(userID, request.PageRequest, statuses...)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryListMethod), logFields...)
+		return nil, page, buildListErrFromRepo("properties", err)
+	}
+
+	return properties, page, nil
+}
+
+// checkStatusTransition checks that the property may move to the given status: the transition must be
+// allowed, sold is only for sales and rented only for rentals, approving a listing under review takes a
+// moderator, expiry and rejection are left to the service and the moderation queue, and a listing past its
+// period is published by renewing it
+func checkStatusTransition(commonLogFields []zap.Field, property dto.Property, status string, moderator bool) *custom.ErrorResult {
+	log.Logger.Debug(log.TraceMsgFuncStart(checkStatusTransitionMethod), log.TraceMethodInputs(commonLogFields, property.ID, property.Status, status, moderator)...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(checkStatusTransitionMethod), commonLogFields...)
+
+	if !slices.Contains(propertyStatusTransitions[property.Status], status) {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidTransitionCode, fmt.Sprintf(constant.InvalidTransitionMsg, property.Status, status), "status")
+		return &errRes
+	}
+
+	if (status == constant.PropertyStatusSold && property.PricingType != constant.PricingTypeSell) ||
+		(status == constant.PropertyStatusRented && property.PricingType == constant.PricingTypeSell) {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidTransitionCode, fmt.Sprintf(constant.InvalidClosingStatusMsg, property.PricingType, status), "status")
+		return &errRes
+	}
+
+	approving := property.Status == constant.PropertyStatusPendingReview && status == constant.PropertyStatusPublished
+	if status == constant.PropertyStatusExpired || status == constant.PropertyStatusRejected || (approving && !moderator) {
+		errRes := custom.BuildForbiddenErrResult(constant.InvalidTransitionCode, fmt.Sprintf(constant.InvalidTransitionMsg, property.Status, status), "status")
+		return &errRes
+	}
+
+	// An approved listing starts a new period, any other listing past its period has to be renewed
+	if status == constant.PropertyStatusPublished && !approving && property.ExpiresAt != nil && !property.ExpiresAt.After(time.Now()) {
+		errRes := custom.BuildBadReqErrResult(constant.ListingExpiredCode, constant.ListingPeriodEndedMsg, "status")
+		return &errRes
+	}
+
+	return nil
+}
+
+// canViewProperty reports whether the principal may see a listing that is not published:
+// moderators and those who may manage the listing can, anonymous requests cannot
+func (service *PropertyService) canViewProperty(propertyID uint, principal *auth.Principal) bool {
+	if principal == nil {
+		return false
+	}
+	if principal.HasPermission(auth.PermissionModerateProperties) {
+		return true
+	}
+	_, _, errResult := service.authorizePropertyOwner(propertyID, principal.ID)
+	return errResult == nil
+}
+
+// getVisibleProperty reads a property the principal may see, answering not found for an unknown
+// one and for one that is not listed publicly unless canViewProperty lets the principal see it
+func (service *PropertyService) getVisibleProperty(propertyID uint, principal *auth.Principal) (dto.Property, *custom.ErrorResult) {
+	if service.propertyRepo == nil {
+		service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	}
+
+	property, errResult := service.getProperty(propertyID)
+	if errResult != nil {
+		return property, errResult
+	}
+
+	if !listedPublicly(property, time.Now()) && !service.canViewProperty(propertyID, principal) {
+		errRes := custom.BuildNotFoundErrResult(constant.PropertyNotFoundCode, constant.PropertyNotFoundMessage, "Property")
+		return dto.Property{}, &errRes
+	}
+
+	return property, nil
+}
+
+// listedPublicly reports whether a listing is shown to everyone: published and within its listing
+// period, as the expiry job may not have caught up with an overdue listing yet
+func listedPublicly(property dto.Property, now time.Time) bool {
+	return property.Status == constant.PropertyStatusPublished && (property.ExpiresAt == nil || property.ExpiresAt.After(now))
+}
+
+// getProperty reads a property, answering not found for an unknown one
+func (service *PropertyService) getProperty(propertyID uint) (dto.Property, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+
+	property, err := service.propertyRepo.GetByID(propertyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.PropertyNotFoundCode, constant.PropertyNotFoundMessage, "Property")
+			return property, &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PropertyRepositoryGetByIDMethod), logFields...)
+		return property, buildSelectErrFromRepo("property", err)
+	}
+
+	return property, nil
+}
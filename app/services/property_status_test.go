@@ -0,0 +1,80 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+func TestCheckStatusTransition(t *testing.T) {
+	setupServiceTest(t)
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		status      string
+		pricingType string
+		expiresAt   *time.Time
+		to          string
+		moderator   bool
+		wantStatus  int
+		wantCode    string
+	}{
+		{name: "publish a draft", status: constant.PropertyStatusDraft, to: constant.PropertyStatusPublished},
+		{name: "submit a draft", status: constant.PropertyStatusDraft, to: constant.PropertyStatusPendingReview},
+		{name: "unpublish", status: constant.PropertyStatusPublished, to: constant.PropertyStatusDraft},
+		{name: "reserve", status: constant.PropertyStatusPublished, to: constant.PropertyStatusReserved},
+		{name: "sell a sale", status: constant.PropertyStatusReserved, to: constant.PropertyStatusSold},
+		{name: "rent a rental", status: constant.PropertyStatusPublished, pricingType: constant.PricingTypeRent, to: constant.PropertyStatusRented},
+		{name: "offer a rental again", status: constant.PropertyStatusRented, pricingType: constant.PricingTypeRent, to: constant.PropertyStatusPublished},
+		{name: "restore an archived listing", status: constant.PropertyStatusArchived, to: constant.PropertyStatusDraft},
+		{name: "moderator approves", status: constant.PropertyStatusPendingReview, to: constant.PropertyStatusPublished, moderator: true},
+		{name: "publish before the period ends", status: constant.PropertyStatusDraft, expiresAt: &future, to: constant.PropertyStatusPublished},
+		{name: "moderator approves after the period ended", status: constant.PropertyStatusPendingReview, expiresAt: &past, to: constant.PropertyStatusPublished, moderator: true},
+
+		{name: "same status", status: constant.PropertyStatusPublished, to: constant.PropertyStatusPublished,
+			wantStatus: http.StatusBadRequest, wantCode: constant.InvalidTransitionCode},
+		{name: "reopen a sold listing", status: constant.PropertyStatusSold, to: constant.PropertyStatusPublished,
+			wantStatus: http.StatusBadRequest, wantCode: constant.InvalidTransitionCode},
+		{name: "publish an expired listing", status: constant.PropertyStatusExpired, to: constant.PropertyStatusPublished,
+			wantStatus: http.StatusBadRequest, wantCode: constant.InvalidTransitionCode},
+		{name: "unknown status", status: constant.PropertyStatusDraft, to: "deleted",
+			wantStatus: http.StatusBadRequest, wantCode: constant.InvalidTransitionCode},
+		{name: "sell a rental", status: constant.PropertyStatusPublished, pricingType: constant.PricingTypeRent, to: constant.PropertyStatusSold,
+			wantStatus: http.StatusBadRequest, wantCode: constant.InvalidTransitionCode},
+		{name: "rent a sale", status: constant.PropertyStatusPublished, to: constant.PropertyStatusRented,
+			wantStatus: http.StatusBadRequest, wantCode: constant.InvalidTransitionCode},
+		{name: "owner approves", status: constant.PropertyStatusPendingReview, to: constant.PropertyStatusPublished,
+			wantStatus: http.StatusForbidden, wantCode: constant.InvalidTransitionCode},
+		{name: "owner rejects", status: constant.PropertyStatusPendingReview, to: constant.PropertyStatusRejected,
+			wantStatus: http.StatusForbidden, wantCode: constant.InvalidTransitionCode},
+		{name: "moderator expires", status: constant.PropertyStatusPublished, to: constant.PropertyStatusExpired, moderator: true,
+			wantStatus: http.StatusForbidden, wantCode: constant.InvalidTransitionCode},
+		{name: "publish after the period ended", status: constant.PropertyStatusDraft, expiresAt: &past, to: constant.PropertyStatusPublished,
+			wantStatus: http.StatusBadRequest, wantCode: constant.ListingExpiredCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pricingType := tt.pricingType
+			if pricingType == "" {
+				pricingType = constant.PricingTypeSell
+			}
+			property := dto.Property{ID: 1, Status: tt.status, PricingType: pricingType, ExpiresAt: tt.expiresAt}
+
+			errResult := checkStatusTransition(nil, property, tt.to, tt.moderator)
+			if tt.wantStatus == 0 {
+				if errResult != nil {
+					t.Fatalf("checkStatusTransition(%s -> %s) error = %v", tt.status, tt.to, errResult.ErrorList)
+				}
+				return
+			}
+			assertErrorStatus(t, errResult, tt.wantStatus)
+			assertErrorCode(t, errResult, tt.wantCode)
+		})
+	}
+}
@@ -0,0 +1,887 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/repository"
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/config/authconfig"
+	"github.com/chazool/serendib_asia_service/pkg/config/mailconfig"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// testStore is an in-memory database behind the repositories the services use in tests.
+// Each repository embeds its interface, so calling a method a test does not expect panics.
+type testStore struct {
+	mu            sync.Mutex
+	nextID        uint
+	users         map[uint]*internaldto.User
+	passwords     map[uint]string
+	sessions      map[uint]*internaldto.UserSession
+	refreshTokens map[uint]*internaldto.RefreshToken
+	loginAttempts []loginAttempt
+	actionTokens  map[uint]*internaldto.UserActionToken
+	properties    map[uint]*dto.Property
+	images        map[uint]*dto.PropertyImage
+	organizations map[uint]*internaldto.Organization
+	members       []internaldto.OrganizationMember
+	duplicates    []internaldto.PropertyDuplicate
+	renewals      []internaldto.PropertyRenewal
+}
+
+type loginAttempt struct {
+	email     string
+	ipAddress string
+	succeeded bool
+	at        time.Time
+}
+
+// setupServiceTest builds the config for the services and points them at a new in-memory store
+func setupServiceTest(t *testing.T) *testStore {
+	t.Helper()
+	t.Setenv(config.LogLevel, "FATAL")
+	t.Setenv(authconfig.JWTSigningKeys, "test:test-secret")
+	t.Setenv(authconfig.JWTActiveKeyID, "test")
+	t.Setenv(authconfig.LoginFailureDelay, "1ms")
+	t.Setenv(authconfig.LoginMaxFailureDelay, "1ms")
+	t.Setenv(mailconfig.MailDriver, mailconfig.DriverOutbox)
+	t.Setenv(mailconfig.MailOutboxDir, t.TempDir())
+	config.InitConfig()
+	InitScreener()
+
+	store := &testStore{
+		users:         make(map[uint]*internaldto.User),
+		passwords:     make(map[uint]string),
+		sessions:      make(map[uint]*internaldto.UserSession),
+		refreshTokens: make(map[uint]*internaldto.RefreshToken),
+		actionTokens:  make(map[uint]*internaldto.UserActionToken),
+		properties:    make(map[uint]*dto.Property),
+		images:        make(map[uint]*dto.PropertyImage),
+		organizations: make(map[uint]*internaldto.Organization),
+	}
+
+	replace(t, &createUserRepository, func(string) repository.UserRepository { return &testUserRepository{store: store} })
+	replace(t, &createTokenRepository, func(string) repository.TokenRepository { return &testTokenRepository{store: store} })
+	replace(t, &createSessionRepository, func(string) repository.SessionRepository { return &testSessionRepository{store: store} })
+	replace(t, &createLoginAttemptRepository, func(string) repository.LoginAttemptRepository { return &testLoginAttemptRepository{store: store} })
+	replace(t, &createActionTokenRepository, func(string) repository.ActionTokenRepository { return &testActionTokenRepository{store: store} })
+	replace(t, &createOrganizationRepository, func(string) repository.OrganizationRepository { return &testOrganizationRepository{store: store} })
+	replace(t, &createPropertyRepository, func(string) repository.PropertyRepository { return &testPropertyRepository{store: store} })
+	replace(t, &createImageRepository, func(string) repository.ImageRepository { return &testImageRepository{store: store} })
+
+	return store
+}
+
+// replace swaps a repository constructor for the duration of the test
+func replace[T any](t *testing.T, constructor *T, fake T) {
+	t.Helper()
+	original := *constructor
+	*constructor = fake
+	t.Cleanup(func() { *constructor = original })
+}
+
+func (store *testStore) id() uint {
+	store.nextID++
+	return store.nextID
+}
+
+// addUser stores an active user with the given password
+func (store *testStore) addUser(email, password string) *internaldto.User {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	user := &internaldto.User{ID: store.id(), FullName: "Test User", Email: email, CreatedAt: time.Now()}
+	store.users[user.ID] = user
+	store.passwords[user.ID] = password
+	return user
+}
+
+// issueActionToken stores a token emailed to the user for the given purpose and returns it
+func (store *testStore) issueActionToken(userID uint, purpose string, ttl time.Duration) string {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	id := store.id()
+	token := fmt.Sprintf("%s-token-%d", purpose, id)
+	store.actionTokens[id] = &internaldto.UserActionToken{
+		ID:        id,
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: auth.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	return token
+}
+
+// unusedActionTokens counts the tokens of the user for the given purpose that can still be used
+func (store *testStore) unusedActionTokens(userID uint, purpose string) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	count := 0
+	for _, token := range store.actionTokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// suspend suspends the user as a moderator would
+func (store *testStore) suspend(userID uint) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	store.users[userID].SuspendedAt = &now
+}
+
+// activeSessions counts the sessions of a user that are not revoked
+func (store *testStore) activeSessions(userID uint) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	count := 0
+	for _, session := range store.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			count++
+		}
+	}
+	return count
+}
+
+// addProperty stores a listing of the user with an image of its own
+func (store *testStore) addProperty(userID uint, organizationID *uint, status string) *dto.Property {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	property := &dto.Property{
+		ID:             store.id(),
+		UserID:         userID,
+		OrganizationID: organizationID,
+		Title:          "Two bedroom apartment in Colombo",
+		City:           "Colombo",
+		Address:        "12 Galle Road",
+		Price:          25000000,
+		PricingType:    "sell",
+		Status:         status,
+		CreatedAt:      time.Now(),
+	}
+	image := &dto.PropertyImage{ID: store.id(), PropertyID: property.ID, URL: fmt.Sprintf("https://cdn.example.com/%d/front.jpg", property.ID), IsPrimary: true}
+	store.properties[property.ID] = property
+	store.images[image.ID] = image
+	return property
+}
+
+// addOrganization stores an organization owned by the user
+func (store *testStore) addOrganization(ownerID uint) *internaldto.Organization {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	organization := &internaldto.Organization{ID: store.id(), Name: "Lanka Homes", CreatedBy: ownerID, CreatedAt: time.Now()}
+	store.organizations[organization.ID] = organization
+	store.members = append(store.members, internaldto.OrganizationMember{OrganizationID: organization.ID, UserID: ownerID, Role: auth.OrganizationRoleOwner})
+	return organization
+}
+
+// addMember adds the user to the organization with the given role
+func (store *testStore) addMember(organizationID, userID uint, role string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.members = append(store.members, internaldto.OrganizationMember{OrganizationID: organizationID, UserID: userID, Role: role})
+}
+
+// setExpiresAt moves the end of the listing period of a listing
+func (store *testStore) setExpiresAt(propertyID uint, expiresAt time.Time) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.properties[propertyID].ExpiresAt = &expiresAt
+}
+
+// duplicatesOf lists the duplicate flags that involve the listing
+func (store *testStore) duplicatesOf(propertyID uint) []internaldto.PropertyDuplicate {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var duplicates []internaldto.PropertyDuplicate
+	for _, duplicate := range store.duplicates {
+		if duplicate.PropertyID == propertyID || duplicate.DuplicateOfID == propertyID {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+	return duplicates
+}
+
+// property returns the stored listing, or nil once it is deleted
+func (store *testStore) property(id uint) *dto.Property {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	return store.properties[id]
+}
+
+// imageCount counts the images of a listing
+func (store *testStore) imageCount(propertyID uint) int {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	count := 0
+	for _, image := range store.images {
+		if image.PropertyID == propertyID {
+			count++
+		}
+	}
+	return count
+}
+
+// sendToReview moves a published listing back to pending review when a note is given.
+// The caller holds the lock.
+func (store *testStore) sendToReview(propertyID uint, note string) {
+	property, ok := store.properties[propertyID]
+	if ok && note != constant.Empty && property.Status == constant.PropertyStatusPublished {
+		property.Status = constant.PropertyStatusPendingReview
+	}
+}
+
+func profileOf(user *internaldto.User) *dto.UserProfileResponse {
+	return &dto.UserProfileResponse{ID: user.ID, FullName: user.FullName, Email: user.Email, CreatedAt: user.CreatedAt}
+}
+
+type testUserRepository struct {
+	repository.UserRepository
+	store *testStore
+}
+
+func (r *testUserRepository) Login(email, password string) (*dto.UserProfileResponse, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for id, user := range r.store.users {
+		if user.Email == email && r.store.passwords[id] == password {
+			return profileOf(user), nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *testUserRepository) GetProfile(userID uint) (*dto.UserProfileResponse, error) {
+	user, err := r.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return profileOf(user), nil
+}
+
+func (r *testUserRepository) GetUserByID(userID uint) (*internaldto.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *testUserRepository) GetUserByEmail(email string) (*internaldto.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, user := range r.store.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *testUserRepository) ResetPassword(userID uint, newPassword string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	user.PasswordChangedAt = &now
+	r.store.passwords[userID] = newPassword
+	return nil
+}
+
+func (r *testUserRepository) MarkEmailVerified(userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	return nil
+}
+
+// DeleteAccount removes or unpublishes the personal listings of the user and anonymises the account
+func (r *testUserRepository) DeleteAccount(userID uint, password string, deleteListings bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	user, ok := r.store.users[userID]
+	if !ok || user.DeletedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	if r.store.passwords[userID] != password {
+		return bcrypt.ErrMismatchedHashAndPassword
+	}
+
+	now := time.Now()
+	for id, property := range r.store.properties {
+		if property.UserID != userID || property.OrganizationID != nil {
+			continue
+		}
+		if deleteListings {
+			delete(r.store.properties, id)
+		} else {
+			property.Base = &dto.Base{DeletedAt: gorm.DeletedAt{Time: now, Valid: true}}
+		}
+	}
+	user.Email = fmt.Sprintf("deleted-%d@users.invalid", userID)
+	user.DeletedAt = &now
+	delete(r.store.passwords, userID)
+	return nil
+}
+
+type testTokenRepository struct {
+	repository.TokenRepository
+	store *testStore
+}
+
+func (r *testTokenRepository) CreateRefreshToken(token *internaldto.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	token.ID = r.store.id()
+	copied := *token
+	r.store.refreshTokens[token.ID] = &copied
+	return nil
+}
+
+func (r *testTokenRepository) GetRefreshTokenByHash(tokenHash string) (*internaldto.RefreshToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *testTokenRepository) RotateRefreshToken(oldTokenID uint, newToken *internaldto.RefreshToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	old := r.store.refreshTokens[oldTokenID]
+	if old == nil || old.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	newToken.ID = r.store.id()
+	copied := *newToken
+	r.store.refreshTokens[newToken.ID] = &copied
+	old.RevokedAt = &now
+	old.ReplacedByID = &newToken.ID
+	return nil
+}
+
+func (r *testTokenRepository) RevokeRefreshToken(tokenHash string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.store.refreshTokens {
+		if token.TokenHash == tokenHash && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type testSessionRepository struct {
+	repository.SessionRepository
+	store *testStore
+}
+
+func (r *testSessionRepository) Create(session *internaldto.UserSession) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session.ID = r.store.id()
+	copied := *session
+	r.store.sessions[session.ID] = &copied
+	return nil
+}
+
+func (r *testSessionRepository) GetByID(id uint) (*internaldto.UserSession, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (r *testSessionRepository) Touch(id uint, expiresAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if session, ok := r.store.sessions[id]; ok {
+		session.LastUsedAt = time.Now()
+		if expiresAt != nil {
+			session.ExpiresAt = *expiresAt
+		}
+	}
+	return nil
+}
+
+func (r *testSessionRepository) Revoke(userID, id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	session, ok := r.store.sessions[id]
+	if !ok || session.UserID != userID || session.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	for _, token := range r.store.refreshTokens {
+		if token.SessionID == id && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r *testSessionRepository) RevokeAllForUser(userID, exceptID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, session := range r.store.sessions {
+		if session.UserID == userID && session.ID != exceptID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	for _, token := range r.store.refreshTokens {
+		if token.UserID == userID && token.SessionID != exceptID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+type testActionTokenRepository struct {
+	repository.ActionTokenRepository
+	store *testStore
+}
+
+func (r *testActionTokenRepository) Create(token *internaldto.UserActionToken) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, stored := range r.store.actionTokens {
+		if stored.UserID == token.UserID && stored.Purpose == token.Purpose && stored.UsedAt == nil {
+			stored.UsedAt = &now
+		}
+	}
+	token.ID = r.store.id()
+	copied := *token
+	r.store.actionTokens[token.ID] = &copied
+	return nil
+}
+
+func (r *testActionTokenRepository) Consume(purpose, tokenHash string) (*internaldto.UserActionToken, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	now := time.Now()
+	for _, token := range r.store.actionTokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose && token.UsedAt == nil && token.ExpiresAt.After(now) {
+			token.UsedAt = &now
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type testLoginAttemptRepository struct {
+	repository.LoginAttemptRepository
+	store *testStore
+}
+
+func (r *testLoginAttemptRepository) Record(email, ipAddress string, succeeded bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.loginAttempts = append(r.store.loginAttempts, loginAttempt{email: email, ipAddress: ipAddress, succeeded: succeeded, at: time.Now()})
+	return nil
+}
+
+// CountEmailFailures counts the failures since the last success of the email, as a success resets the count
+func (r *testLoginAttemptRepository) CountEmailFailures(email string, since time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var failures int64
+	for _, attempt := range r.store.loginAttempts {
+		if attempt.email != email || attempt.at.Before(since) {
+			continue
+		}
+		if attempt.succeeded {
+			failures = 0
+		} else {
+			failures++
+		}
+	}
+	return failures, nil
+}
+
+func (r *testLoginAttemptRepository) CountIPFailures(ipAddress string, since time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var failures int64
+	for _, attempt := range r.store.loginAttempts {
+		if attempt.ipAddress == ipAddress && !attempt.succeeded && !attempt.at.Before(since) {
+			failures++
+		}
+	}
+	return failures, nil
+}
+
+type testOrganizationRepository struct {
+	repository.OrganizationRepository
+	store *testStore
+}
+
+func (r *testOrganizationRepository) GetByID(id uint) (*internaldto.Organization, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	organization, ok := r.store.organizations[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *organization
+	return &copied, nil
+}
+
+func (r *testOrganizationRepository) Update(organization *internaldto.Organization) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	copied := *organization
+	r.store.organizations[organization.ID] = &copied
+	return nil
+}
+
+func (r *testOrganizationRepository) GetMember(organizationID, userID uint) (*internaldto.OrganizationMember, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, member := range r.store.members {
+		if member.OrganizationID == organizationID && member.UserID == userID {
+			copied := member
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *testOrganizationRepository) CountMembers(organizationID uint, role string) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, member := range r.store.members {
+		if member.OrganizationID == organizationID && (role == "" || member.Role == role) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *testOrganizationRepository) RemoveMember(organizationID, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i, member := range r.store.members {
+		if member.OrganizationID == organizationID && member.UserID == userID {
+			r.store.members = append(r.store.members[:i], r.store.members[i+1:]...)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+type testPropertyRepository struct {
+	repository.PropertyRepository
+	store *testStore
+}
+
+func (r *testPropertyRepository) GetByID(id uint) (dto.Property, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	property, ok := r.store.properties[id]
+	if !ok {
+		return dto.Property{}, gorm.ErrRecordNotFound
+	}
+	copied := *property
+	copied.PropertyImages = nil
+	for _, image := range r.store.images {
+		if image.PropertyID == id {
+			copied.PropertyImages = append(copied.PropertyImages, *image)
+		}
+	}
+	return copied, nil
+}
+
+func (r *testPropertyRepository) GetOwnership(id uint) (uint, *uint, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	property, ok := r.store.properties[id]
+	if !ok {
+		return 0, nil, gorm.ErrRecordNotFound
+	}
+	return property.UserID, property.OrganizationID, nil
+}
+
+func (r *testPropertyRepository) Delete(id uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	delete(r.store.properties, id)
+	for imageID, image := range r.store.images {
+		if image.PropertyID == id {
+			delete(r.store.images, imageID)
+		}
+	}
+	return nil
+}
+
+func (r *testPropertyRepository) ListExpired(now time.Time, limit int) ([]dto.Property, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var properties []dto.Property
+	for _, property := range r.store.properties {
+		if len(properties) < limit && property.Status == constant.PropertyStatusPublished &&
+			property.ExpiresAt != nil && !property.ExpiresAt.After(now) {
+			properties = append(properties, *property)
+		}
+	}
+	return properties, nil
+}
+
+func (r *testPropertyRepository) ChangeStatus(id uint, from, to string, changedBy uint, note string, expiresAt *time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	property, ok := r.store.properties[id]
+	if !ok || property.Status != from {
+		return gorm.ErrRecordNotFound
+	}
+	property.Status = to
+	if expiresAt != nil {
+		property.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+// Renew moves the end of the listing period and publishes an expired listing again
+func (r *testPropertyRepository) Renew(property dto.Property, expiresAt time.Time, userID uint) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	stored, ok := r.store.properties[property.ID]
+	if !ok || stored.Status != property.Status {
+		return gorm.ErrRecordNotFound
+	}
+	if stored.Status == constant.PropertyStatusExpired {
+		stored.Status = constant.PropertyStatusPublished
+	}
+	r.store.renewals = append(r.store.renewals, internaldto.PropertyRenewal{
+		PropertyID:        property.ID,
+		UserID:            userID,
+		PreviousExpiresAt: stored.ExpiresAt,
+		ExpiresAt:         expiresAt,
+		CreatedAt:         time.Now(),
+	})
+	stored.ExpiresAt = &expiresAt
+	return nil
+}
+
+func (r *testPropertyRepository) CountRenewalsByUserID(userID uint, since time.Time) (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var count int64
+	for _, renewal := range r.store.renewals {
+		if renewal.UserID == userID && renewal.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Moderate moves a listing under review to the decided status
+func (r *testPropertyRepository) Moderate(id uint, to string, decision internaldto.PropertyModerationDecision, expiresAt *time.Time) error {
+	return r.ChangeStatus(id, constant.PropertyStatusPendingReview, to, decision.ModeratorID, decision.Note, expiresAt)
+}
+
+// ListDuplicateCandidates returns every other listing that is not archived
+func (r *testPropertyRepository) ListDuplicateCandidates(property dto.Property, radiusKm, priceTolerance float64, limit int) ([]dto.Property, error) {
+	r.store.mu.Lock()
+	ids := make([]uint, 0, len(r.store.properties))
+	for id := range r.store.properties {
+		ids = append(ids, id)
+	}
+	r.store.mu.Unlock()
+	slices.Sort(ids)
+
+	var candidates []dto.Property
+	for _, id := range ids {
+		candidate, _ := r.GetByID(id)
+		if id != property.ID && candidate.Status != constant.PropertyStatusArchived && len(candidates) < limit {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, nil
+}
+
+func (r *testPropertyRepository) SaveDuplicate(duplicate internaldto.PropertyDuplicate) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	r.store.duplicates = append(r.store.duplicates, duplicate)
+	return nil
+}
+
+type testImageRepository struct {
+	repository.ImageRepository
+	store *testStore
+}
+
+func (r *testImageRepository) Upload(propertyID uint, url string, isPrimary bool, changedBy uint, reviewNote string) (*dto.ImageResponse, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	image := &dto.PropertyImage{ID: r.store.id(), PropertyID: propertyID, URL: url, IsPrimary: isPrimary}
+	r.store.images[image.ID] = image
+	r.store.sendToReview(propertyID, reviewNote)
+	return &dto.ImageResponse{ID: image.ID, PropertyID: propertyID, URL: url, IsPrimary: isPrimary}, nil
+}
+
+func (r *testImageRepository) Delete(imageID, changedBy uint, reviewNote string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	image, ok := r.store.images[imageID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.images, imageID)
+	r.store.sendToReview(image.PropertyID, reviewNote)
+	return nil
+}
+
+func (r *testImageRepository) SetPrimary(imageID, changedBy uint, reviewNote string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	image, ok := r.store.images[imageID]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for _, other := range r.store.images {
+		if other.PropertyID == image.PropertyID {
+			other.IsPrimary = other.ID == imageID
+		}
+	}
+	r.store.sendToReview(image.PropertyID, reviewNote)
+	return nil
+}
+
+func (r *testImageRepository) GetPropertyID(imageID uint) (uint, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	image, ok := r.store.images[imageID]
+	if !ok {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return image.PropertyID, nil
+}
+
+// List returns every image of the property on one page
+func (r *testImageRepository) List(propertyID uint, request dto.PageRequest) ([]dto.ImageResponse, pagination.Page, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var images []dto.ImageResponse
+	for _, image := range r.store.images {
+		if image.PropertyID == propertyID {
+			images = append(images, dto.ImageResponse{ID: image.ID, PropertyID: propertyID, URL: image.URL, IsPrimary: image.IsPrimary})
+		}
+	}
+	return images, pagination.Page{PageSize: pagination.Size(request.PageSize)}, nil
+}
+
+// assertErrorStatus fails the test unless the service answered with the given status
+func assertErrorStatus(t *testing.T, errResult *custom.ErrorResult, status int) {
+	t.Helper()
+	if errResult == nil {
+		t.Fatalf("error = nil, want status %d", status)
+	}
+	if errResult.StatusCode != status {
+		t.Fatalf("error status = %d (%v), want %d", errResult.StatusCode, errResult.ErrorList, status)
+	}
+}
+
+// assertErrorCode fails the test unless the service answered with the given error code
+func assertErrorCode(t *testing.T, errResult *custom.ErrorResult, code string) {
+	t.Helper()
+	if errResult == nil || len(errResult.ErrorList) == 0 {
+		t.Fatalf("error = %v, want code %s", errResult, code)
+	}
+	if got := errResult.ErrorList[0].ErrorCode; got != code {
+		t.Fatalf("error code = %s (%v), want %s", got, errResult.ErrorList, code)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/chazool/serendib_asia_service/pkg/auth"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/utils"
 
 	"github.com/chazool/serendib_asia_service/pkg/custom"
@@ -26,6 +28,11 @@ func checkRepoError(commonLogFields []zap.Field, errorOccurredFrom string, err e
 		return &errRes
 	}
 
+	// page token that does not belong to the list
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return buildInvalidCursorErr()
+	}
+
 	// DB errors
 	errRes := custom.BuildInternalServerErrResult(constant.ErrDatabaseCode, constant.ErrOccurredWhileRetrieving, err.Error())
 	return &errRes
@@ -54,6 +61,21 @@ func ExtractAccessToken(commonLogFields []zap.Field, authString string) (accessT
 	return splitTokenStr[constant.IntOne], nil
 }
 
+// requirePermission checks that the principal holds the given permission
+func requirePermission(principal *auth.Principal, permission string) *custom.ErrorResult {
+	if principal == nil {
+		errRes := custom.BuildUnauthorizedErrResult(constant.ErrEmptyAuthHeaderCode, constant.ErrEmptyAuthHeaderMsg, constant.Empty)
+		return &errRes
+	}
+
+	if !principal.HasPermission(permission) {
+		errRes := custom.BuildForbiddenErrResult(constant.ErrCodeForbidden, constant.ErrMissingPermissionMsg, permission)
+		return &errRes
+	}
+
+	return nil
+}
+
 func buildDBError(method string, err error) *custom.ErrorResult {
 	errRes := custom.BuildInternalServerErrResult(constant.ErrDatabaseCode,
 		fmt.Sprintf(constant.ErrorOccurredWhenSelecting, method),
@@ -75,6 +97,19 @@ func buildSelectErrFromRepo(when string, err error) *custom.ErrorResult {
 	return &errRes
 }
 
+// buildListErrFromRepo builds the error of a paged list, telling a bad page token apart from database errors
+func buildListErrFromRepo(when string, err error) *custom.ErrorResult {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return buildInvalidCursorErr()
+	}
+	return buildSelectErrFromRepo(when, err)
+}
+
+func buildInvalidCursorErr() *custom.ErrorResult {
+	errRes := custom.BuildBadReqErrResult(constant.InvalidCursorCode, constant.InvalidCursorMessage, constant.Empty)
+	return &errRes
+}
+
 func buildInsertErrFromRepo(when string, err error) *custom.ErrorResult {
 	errRes := custom.BuildInternalServerErrResult(constant.ErrDatabaseCode,
 		fmt.Sprintf(constant.ErrorOccurredWhenInserting, when),
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"github.com/chazool/serendib_asia_service/app/repository"
+)
+
+// Repository constructors used by the services. They are variables so that tests
+// can replace them with in-memory repositories.
+var (
+	createActionTokenRepository       = repository.CreateActionTokenRepository
+	createAPIKeyRepository            = repository.CreateAPIKeyRepository
+	createFavoriteRepository          = repository.CreateFavoriteRepository
+	createImageRepository             = repository.CreateImageRepository
+	createLoginAttemptRepository      = repository.CreateLoginAttemptRepository
+	createLookupRepository            = repository.CreateLookupRepository
+	createOrganizationRepository      = repository.CreateOrganizationRepository
+	createPhoneVerificationRepository = repository.CreatePhoneVerificationRepository
+	createPropertyRepository          = repository.CreatePropertyRepository
+	createRoleRepository              = repository.CreateRoleRepository
+	createSessionRepository           = repository.CreateSessionRepository
+	createTokenRepository             = repository.CreateTokenRepository
+	createUserRepository              = repository.CreateUserRepository
+)
@@ -1,14 +1,26 @@
 package services
 
 import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
 	"runtime/debug"
+	"strings"
+	"time"
 
 	"github.com/chazool/serendib_asia_service/app/repository"
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/config/authconfig"
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/mail"
+	"github.com/chazool/serendib_asia_service/pkg/sms"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +31,26 @@ const (
 	UserServiceGetProfileMethod     = "UserServiceGetProfile"
 	UserServiceUpdateProfileMethod  = "UserServiceUpdateProfile"
 	UserServiceUpdatePasswordMethod = "UserServiceUpdatePassword"
+	UserServiceRefreshTokenMethod   = "UserServiceRefreshToken"
+	UserServiceLogoutMethod         = "UserServiceLogout"
+	UserServiceIssueTokensMethod    = "UserServiceIssueTokens"
+	UserServiceForgotPasswordMethod = "UserServiceForgotPassword"
+	UserServiceResetPasswordMethod  = "UserServiceResetPassword"
+	UserServiceVerifyEmailMethod    = "UserServiceVerifyEmail"
+	UserServiceResendVerifyMethod   = "UserServiceResendVerification"
+	sendVerificationEmailMethod     = "sendVerificationEmail"
+	UserServiceUnlockAccountMethod  = "UserServiceUnlockAccount"
+	checkLoginThrottleMethod        = "checkLoginThrottle"
+	recordLoginFailureMethod        = "recordLoginFailure"
+	UserServiceListSessionsMethod   = "UserServiceListSessions"
+	UserServiceRevokeSessionMethod  = "UserServiceRevokeSession"
+	UserServiceRevokeAllMethod      = "UserServiceRevokeAllSessions"
+	startSessionMethod              = "startSession"
+	UserServiceExportDataMethod     = "UserServiceExportData"
+	UserServiceDeleteAccountMethod  = "UserServiceDeleteAccount"
+	UserServiceSendPhoneCodeMethod  = "UserServiceSendPhoneVerification"
+	UserServiceVerifyPhoneMethod    = "UserServiceVerifyPhone"
+	UserServicePublicProfileMethod  = "UserServiceGetPublicProfile"
 )
 
 // UserService defines the interface for user service methods
@@ -27,6 +59,16 @@ type UserService struct {
 	serviceContext ServiceContext
 	transaction    *gorm.DB
 	userRepo       repository.UserRepository
+	tokenRepo      repository.TokenRepository
+	actionRepo     repository.ActionTokenRepository
+	attemptRepo    repository.LoginAttemptRepository
+	sessionRepo    repository.SessionRepository
+	propertyRepo   repository.PropertyRepository
+	favoriteRepo   repository.FavoriteRepository
+	roleRepo       repository.RoleRepository
+	phoneRepo      repository.PhoneVerificationRepository
+	mailer         mail.Mailer
+	smsSender      sms.Sender
 }
 
 // CreateUserService creates a new instance of UserService
@@ -38,7 +80,7 @@ func CreateUserService(requestID string, transactionDB *gorm.DB) *UserService {
 }
 
 // Register handles user registration
-func (service *UserService) Register(request *dto.UserRegisterRequest) (response *dto.UserLoginResponse, errResult *custom.ErrorResult) {
+func (service *UserService) Register(request *dto.UserRegisterRequest, client dto.ClientInfo) (response *dto.UserLoginResponse, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceRegisterMethod), commonLogFields...)
 
@@ -50,7 +92,11 @@ func (service *UserService) Register(request *dto.UserRegisterRequest) (response
 		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceRegisterMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.userRepo = repository.CreateUserRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	if errResult = normalizePhoneNumber(&request.PhoneNumber); errResult != nil {
+		return nil, errResult
+	}
 
 	// Check if email already exists
 	exists, err := service.userRepo.CheckEmailExists(request.Email)
@@ -73,19 +119,27 @@ func (service *UserService) Register(request *dto.UserRegisterRequest) (response
 		return nil, buildInsertErrFromRepo("user", err)
 	}
 
-	// TODO: Generate JWT token
-	token := "dummy-token" // Replace with actual JWT token generation
+	// The account is usable right away, a failed email can be resent later
+	if err = service.sendVerificationEmail(user.ID, user.Email); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(sendVerificationEmailMethod), log.TraceError(commonLogFields, err)...)
+	}
+
+	tokens, errResult := service.startSession(user.ID, user.Email, request.DeviceName, client)
+	if errResult != nil {
+		return nil, errResult
+	}
 
 	response = &dto.UserLoginResponse{
-		User:  *user,
-		Token: token,
+		User:          *user,
+		TokenResponse: *tokens,
 	}
 
 	return response, nil
 }
 
-// Login handles user login
-func (service *UserService) Login(request *dto.UserLoginRequest) (response *dto.UserLoginResponse, errResult *custom.ErrorResult) {
+// Login handles user login. Failed attempts are counted per email and per client
+// IP; every failure is answered the same way whether or not the email exists.
+func (service *UserService) Login(request *dto.UserLoginRequest, client dto.ClientInfo) (response *dto.UserLoginResponse, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceLoginMethod), commonLogFields...)
 
@@ -97,23 +151,47 @@ func (service *UserService) Login(request *dto.UserLoginRequest) (response *dto.
 		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceLoginMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.userRepo = repository.CreateUserRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.attemptRepo = createLoginAttemptRepository(service.serviceContext.RequestID)
+
+	attemptKey := strings.ToLower(strings.TrimSpace(request.Email))
+
+	emailFailures, errResult := service.checkLoginThrottle(attemptKey, client.IPAddress)
+	if errResult != nil {
+		return nil, errResult
+	}
 
 	// Login user
 	user, err := service.userRepo.Login(request.Email, request.Password)
 	if err != nil {
 		logFields := log.TraceError(commonLogFields, err)
 		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.UserRepositoryLoginMethod), logFields...)
-		errRes := custom.BuildBadReqErrResult(constant.InvalidCredentialsCode, constant.InvalidCredentialsMessage, "Credentials")
+		service.recordLoginFailure(attemptKey, client.IPAddress, emailFailures+1)
+		errRes := custom.BuildUnauthorizedErrResult(constant.InvalidCredentialsCode, constant.InvalidCredentialsMessage, "Credentials")
 		return nil, &errRes
 	}
 
-	// TODO: Generate JWT token
-	token := "dummy-token" // Replace with actual JWT token generation
+	if err = service.attemptRepo.Record(attemptKey, client.IPAddress, true); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.LoginAttemptRepositoryRecordMethod), log.TraceError(commonLogFields, err)...)
+	}
+
+	// The right password does not open a suspended or deleted account
+	account, err := service.userRepo.GetUserByID(user.ID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+	if errResult = checkAccountActive(account); errResult != nil {
+		return nil, errResult
+	}
+
+	tokens, errResult := service.startSession(user.ID, user.Email, request.DeviceName, client)
+	if errResult != nil {
+		return nil, errResult
+	}
 
 	response = &dto.UserLoginResponse{
-		User:  *user,
-		Token: token,
+		User:          *user,
+		TokenResponse: *tokens,
 	}
 
 	return response, nil
@@ -132,7 +210,7 @@ func (service *UserService) GetProfile(userID uint) (response *dto.UserProfileRe
 		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceGetProfileMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.userRepo = repository.CreateUserRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
 
 	response, err := service.userRepo.GetProfile(userID)
 	if err != nil {
@@ -145,6 +223,65 @@ func (service *UserService) GetProfile(userID uint) (response *dto.UserProfileRe
 	return response, nil
 }
 
+// GetPublicProfile returns the public profile of a seller with a page of their
+// published listings. Deleted and suspended accounts are reported as not found.
+func (service *UserService) GetPublicProfile(userID uint, request dto.PageRequest) (response *dto.PublicProfileResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServicePublicProfileMethod), log.TraceMethodInputs(commonLogFields, userID, request)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServicePublicProfileMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServicePublicProfileMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+
+	notFoundErr := custom.BuildNotFoundErrResult(constant.UserNotFoundCode, constant.UserNotFoundMessage, "User")
+
+	user, err := service.userRepo.GetUserByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &notFoundErr
+		}
+		return nil, checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+	if user.DeletedAt != nil || user.SuspendedAt != nil {
+		return nil, &notFoundErr
+	}
+
+	count, err := service.propertyRepo.CountPublicByUserID(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.PropertyRepositoryCountPublicByUserMethod, err)
+	}
+
+	// The listing count is always part of the profile
+	request.IncludeTotal = false
+	listings, page, err := service.propertyRepo.ListPublicByUserID(userID, request)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.PropertyRepositoryListPublicByUserMethod, err)
+	}
+
+	response = &dto.PublicProfileResponse{
+		ID:           user.ID,
+		DisplayName:  user.FullName,
+		ProfileImage: user.ProfileImage,
+		MemberSince:  user.CreatedAt,
+		Badges: dto.VerificationBadges{
+			EmailVerified: user.EmailVerifiedAt != nil,
+			PhoneVerified: user.PhoneVerifiedAt != nil,
+		},
+		ActiveListingCount: count,
+		Listings:           listings,
+		Pagination:         page,
+	}
+
+	return response, nil
+}
+
 // UpdateProfile updates user profile
 func (service *UserService) UpdateProfile(userID uint, request *dto.UserUpdateProfileRequest) (response *dto.UserProfileResponse, errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
@@ -158,7 +295,11 @@ func (service *UserService) UpdateProfile(userID uint, request *dto.UserUpdatePr
 		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceUpdateProfileMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
 	}()
 
-	service.userRepo = repository.CreateUserRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	if errResult = normalizePhoneNumber(&request.PhoneNumber); errResult != nil {
+		return nil, errResult
+	}
 
 	response, err := service.userRepo.UpdateProfile(userID, request)
 	if err != nil {
@@ -170,8 +311,8 @@ func (service *UserService) UpdateProfile(userID uint, request *dto.UserUpdatePr
 	return response, nil
 }
 
-// UpdatePassword updates user password
-func (service *UserService) UpdatePassword(userID uint, request *dto.UserUpdatePasswordRequest) (errResult *custom.ErrorResult) {
+// UpdatePassword updates user password and signs out every other session of the user
+func (service *UserService) UpdatePassword(userID, sessionID uint, request *dto.UserUpdatePasswordRequest) (errResult *custom.ErrorResult) {
 	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
 	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceUpdatePasswordMethod), commonLogFields...)
 
@@ -183,7 +324,8 @@ func (service *UserService) UpdatePassword(userID uint, request *dto.UserUpdateP
 		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceUpdatePasswordMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
 	}()
 
-	service.userRepo = repository.CreateUserRepository(service.serviceContext.RequestID)
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
 
 	err := service.userRepo.UpdatePassword(userID, request.CurrentPassword, request.NewPassword)
 	if err != nil {
@@ -192,5 +334,870 @@ func (service *UserService) UpdatePassword(userID uint, request *dto.UserUpdateP
 		return buildUpdateErrFromRepo("user password", err)
 	}
 
+	err = service.sessionRepo.RevokeAllForUser(userID, sessionID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryRevokeAllForUserMethod), logFields...)
+		return buildUpdateErrFromRepo("user sessions", err)
+	}
+
+	return nil
+}
+
+// RefreshToken rotates a refresh token and issues a new access token.
+// Presenting a token that was already rotated or revoked is treated as token theft
+// and revokes every refresh token of the user.
+func (service *UserService) RefreshToken(request *dto.RefreshTokenRequest) (response *dto.TokenResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceRefreshTokenMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceRefreshTokenMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceRefreshTokenMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.tokenRepo = createTokenRepository(service.serviceContext.RequestID)
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	invalidTokenErr := custom.BuildUnauthorizedErrResult(constant.ErrInvalidRefreshTokenCode, constant.ErrInvalidRefreshTokenMsg, "RefreshToken")
+
+	storedToken, err := service.tokenRepo.GetRefreshTokenByHash(auth.HashToken(request.RefreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &invalidTokenErr
+		}
+		return nil, checkRepoError(commonLogFields, repository.TokenRepositoryGetRefreshTokenByHashMethod, err)
+	}
+
+	if storedToken.RevokedAt != nil {
+		log.Logger.Warn(constant.ErrInvalidRefreshTokenMsg, commonLogFields...)
+		if err := service.sessionRepo.RevokeAllForUser(storedToken.UserID, 0); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryRevokeAllForUserMethod), log.TraceError(commonLogFields, err)...)
+		}
+		return nil, &invalidTokenErr
+	}
+
+	if time.Now().After(storedToken.ExpiresAt) {
+		return nil, &invalidTokenErr
+	}
+
+	user, err := service.userRepo.GetUserByID(storedToken.UserID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.UserRepositoryGetUserByIDMethod), logFields...)
+		return nil, &invalidTokenErr
+	}
+
+	// A suspended or deleted account loses the session instead of rotating its token
+	if errResult = checkAccountActive(user); errResult != nil {
+		if storedToken.SessionID == 0 {
+			err = service.tokenRepo.RevokeRefreshToken(storedToken.TokenHash)
+		} else {
+			err = service.sessionRepo.Revoke(storedToken.UserID, storedToken.SessionID)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserServiceRefreshTokenMethod), log.TraceError(commonLogFields, err)...)
+		}
+		return nil, errResult
+	}
+
+	response, errResult = service.issueTokens(user.ID, user.Email, storedToken.SessionID, storedToken)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	if storedToken.SessionID != 0 {
+		if err := service.sessionRepo.Touch(storedToken.SessionID, &response.RefreshTokenExpiresAt); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryTouchMethod), log.TraceError(commonLogFields, err)...)
+		}
+	}
+
+	return response, nil
+}
+
+// Logout ends the session of the given refresh token
+func (service *UserService) Logout(request *dto.LogoutRequest) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceLogoutMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceLogoutMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceLogoutMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.tokenRepo = createTokenRepository(service.serviceContext.RequestID)
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	tokenHash := auth.HashToken(request.RefreshToken)
+	storedToken, err := service.tokenRepo.GetRefreshTokenByHash(tokenHash)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return checkRepoError(commonLogFields, repository.TokenRepositoryGetRefreshTokenByHashMethod, err)
+	}
+
+	// Tokens issued before sessions existed are revoked on their own
+	if storedToken.SessionID == 0 {
+		err = service.tokenRepo.RevokeRefreshToken(tokenHash)
+	} else {
+		err = service.sessionRepo.Revoke(storedToken.UserID, storedToken.SessionID)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserServiceLogoutMethod), logFields...)
+		return buildUpdateErrFromRepo("refresh token", err)
+	}
+
+	return nil
+}
+
+// ForgotPassword emails a single use password reset link to the user. The outcome is
+// never reported to the caller, so the endpoint cannot be used to probe for accounts.
+func (service *UserService) ForgotPassword(request *dto.ForgotPasswordRequest) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceForgotPasswordMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceForgotPasswordMethod), commonLogFields...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.actionRepo = createActionTokenRepository(service.serviceContext.RequestID)
+
+	user, err := service.userRepo.GetUserByEmail(request.Email)
+	if err != nil {
+		log.Logger.Info(log.TraceMsgErrorOccurredFrom(repository.UserRepositoryGetUserByEmailMethod), log.TraceError(commonLogFields, err)...)
+		return
+	}
+
+	authConfig := config.GetConfig().AuthConfig
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserServiceForgotPasswordMethod), log.TraceError(commonLogFields, err)...)
+		return
+	}
+
+	err = service.actionRepo.Create(&internaldto.UserActionToken{
+		UserID:    user.ID,
+		Purpose:   internaldto.TokenPurposePasswordReset,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(authConfig.PasswordResetTTL),
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.ActionTokenRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return
+	}
+
+	link := authConfig.PasswordResetURL + "?token=" + url.QueryEscape(token)
+	if err = service.sendMail(mail.PasswordResetMessage(user.Email, link)); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserServiceForgotPasswordMethod), log.TraceError(commonLogFields, err)...)
+	}
+}
+
+// ResetPassword sets a new password with a reset token and ends every session of the user
+func (service *UserService) ResetPassword(request *dto.ResetPasswordRequest) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceResetPasswordMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceResetPasswordMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceResetPasswordMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+	service.actionRepo = createActionTokenRepository(service.serviceContext.RequestID)
+
+	// Consuming first makes the token single use even if the reset below fails
+	token, err := service.actionRepo.Consume(internaldto.TokenPurposePasswordReset, auth.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildBadReqErrResult(constant.ErrInvalidResetTokenCode, constant.ErrInvalidResetTokenMsg, "Token")
+			return &errRes
+		}
+		return checkRepoError(commonLogFields, repository.ActionTokenRepositoryConsumeMethod, err)
+	}
+
+	err = service.userRepo.ResetPassword(token.UserID, request.NewPassword)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.UserRepositoryResetPasswordMethod), logFields...)
+		return buildUpdateErrFromRepo("user password", err)
+	}
+
+	err = service.sessionRepo.RevokeAllForUser(token.UserID, 0)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryRevokeAllForUserMethod), logFields...)
+		return buildUpdateErrFromRepo("user sessions", err)
+	}
+
+	return nil
+}
+
+// VerifyEmail confirms the email address a verification token was sent to
+func (service *UserService) VerifyEmail(request *dto.VerifyEmailRequest) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceVerifyEmailMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceVerifyEmailMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceVerifyEmailMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.actionRepo = createActionTokenRepository(service.serviceContext.RequestID)
+
+	token, err := service.actionRepo.Consume(internaldto.TokenPurposeEmailVerification, auth.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildBadReqErrResult(constant.ErrInvalidVerifyTokenCode, constant.ErrInvalidVerifyTokenMsg, "Token")
+			return &errRes
+		}
+		return checkRepoError(commonLogFields, repository.ActionTokenRepositoryConsumeMethod, err)
+	}
+
+	err = service.userRepo.MarkEmailVerified(token.UserID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.UserRepositoryMarkEmailVerifiedMethod), logFields...)
+		return buildUpdateErrFromRepo("user", err)
+	}
+
+	return nil
+}
+
+// ResendVerification emails a new verification link, invalidating the previous one
+func (service *UserService) ResendVerification(userID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceResendVerifyMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceResendVerifyMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceResendVerifyMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	user, err := service.userRepo.GetUserByID(userID)
+	if err != nil {
+		return checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+	if user.EmailVerifiedAt != nil {
+		errRes := custom.BuildBadReqErrResult(constant.ErrEmailVerifiedCode, constant.ErrEmailVerifiedMsg, "Email")
+		return &errRes
+	}
+
+	if err = service.sendVerificationEmail(user.ID, user.Email); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(sendVerificationEmailMethod), log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.UnexpectedErrorCode, constant.ErrOccurredWhenSendingEmail, err.Error())
+		return &errRes
+	}
+
+	return nil
+}
+
+// sendVerificationEmail stores a new email verification token and emails its link
+func (service *UserService) sendVerificationEmail(userID uint, email string) error {
+	authConfig := config.GetConfig().AuthConfig
+	service.actionRepo = createActionTokenRepository(service.serviceContext.RequestID)
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = service.actionRepo.Create(&internaldto.UserActionToken{
+		UserID:    userID,
+		Purpose:   internaldto.TokenPurposeEmailVerification,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(authConfig.EmailVerificationTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := authConfig.EmailVerificationURL + "?token=" + url.QueryEscape(token)
+
+	return service.sendMail(mail.EmailVerificationMessage(email, link))
+}
+
+// SendPhoneVerification texts a one-time code to the phone number on the user's profile
+func (service *UserService) SendPhoneVerification(userID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceSendPhoneCodeMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceSendPhoneCodeMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceSendPhoneCodeMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	authConfig := config.GetConfig().AuthConfig
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.phoneRepo = createPhoneVerificationRepository(service.serviceContext.RequestID)
+
+	user, err := service.userRepo.GetUserByID(userID)
+	if err != nil {
+		return checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+	if user.PhoneNumber == constant.Empty {
+		errRes := custom.BuildBadReqErrResult(constant.ErrNoPhoneNumberCode, constant.ErrNoPhoneNumberMsg, "PhoneNumber")
+		return &errRes
+	}
+	if user.PhoneVerifiedAt != nil {
+		errRes := custom.BuildBadReqErrResult(constant.ErrPhoneVerifiedCode, constant.ErrPhoneVerifiedMsg, "PhoneNumber")
+		return &errRes
+	}
+
+	// Every code costs an SMS, so codes are rate limited per user
+	latest, err := service.phoneRepo.GetLatest(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return checkRepoError(commonLogFields, repository.PhoneVerificationRepositoryGetLatestMethod, err)
+	}
+	if latest != nil && time.Since(latest.CreatedAt) < authConfig.PhoneOTPResendInterval {
+		errRes := custom.BuildTooManyRequestsErrResult(constant.ErrPhoneCodeThrottledCode, constant.ErrPhoneCodeThrottledMsg, "PhoneNumber")
+		return &errRes
+	}
+
+	code, codeHash, err := auth.GenerateNumericCode(authConfig.PhoneOTPLength)
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhen("generating phone verification code"), log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.UnexpectedErrorCode, constant.ErrOccurredWhenSendingSMS, err.Error())
+		return &errRes
+	}
+
+	err = service.phoneRepo.Create(&internaldto.PhoneVerification{
+		UserID:      user.ID,
+		PhoneNumber: user.PhoneNumber,
+		CodeHash:    codeHash,
+		ExpiresAt:   time.Now().Add(authConfig.PhoneOTPTTL),
+	})
+	if err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PhoneVerificationRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return buildInsertErrFromRepo("phone verification", err)
+	}
+
+	if err = service.sendSMS(sms.PhoneVerificationMessage(user.PhoneNumber, code, authConfig.PhoneOTPTTL)); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredWhen("sending phone verification code"), log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.UnexpectedErrorCode, constant.ErrOccurredWhenSendingSMS, err.Error())
+		return &errRes
+	}
+
+	return nil
+}
+
+// VerifyPhone confirms the phone number of a user with the last code texted to it.
+// A code stops working after PHONE_OTP_MAX_ATTEMPTS wrong guesses.
+func (service *UserService) VerifyPhone(userID uint, request *dto.VerifyPhoneRequest) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceVerifyPhoneMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceVerifyPhoneMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceVerifyPhoneMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	maxAttempts := config.GetConfig().AuthConfig.PhoneOTPMaxAttempts
+	service.phoneRepo = createPhoneVerificationRepository(service.serviceContext.RequestID)
+
+	invalidCodeErr := custom.BuildBadReqErrResult(constant.ErrInvalidPhoneCodeCode, constant.ErrInvalidPhoneCodeMsg, "Code")
+	attemptsErr := custom.BuildBadReqErrResult(constant.ErrPhoneCodeAttemptsCode, constant.ErrPhoneCodeAttemptsMsg, "Code")
+
+	verification, err := service.phoneRepo.GetLatest(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &invalidCodeErr
+		}
+		return checkRepoError(commonLogFields, repository.PhoneVerificationRepositoryGetLatestMethod, err)
+	}
+	if verification.UsedAt != nil || verification.ExpiresAt.Before(time.Now()) {
+		return &invalidCodeErr
+	}
+	if verification.Attempts >= maxAttempts {
+		return &attemptsErr
+	}
+
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(request.Code)), []byte(verification.CodeHash)) != 1 {
+		if err = service.phoneRepo.RecordFailedAttempt(verification.ID); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PhoneVerificationRepositoryRecordFailedAttemptMethod), log.TraceError(commonLogFields, err)...)
+			return buildUpdateErrFromRepo("phone verification", err)
+		}
+		if verification.Attempts+1 >= maxAttempts {
+			return &attemptsErr
+		}
+		return &invalidCodeErr
+	}
+
+	// Fails when the code was used meanwhile or the profile number changed since
+	err = service.phoneRepo.Confirm(verification)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &invalidCodeErr
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.PhoneVerificationRepositoryConfirmMethod), log.TraceError(commonLogFields, err)...)
+		return buildUpdateErrFromRepo("phone verification", err)
+	}
+
+	return nil
+}
+
+// sendSMS delivers a text message with the configured SMS sender
+func (service *UserService) sendSMS(message sms.Message) (err error) {
+	service.smsSender, err = sms.NewSender()
+	if err != nil {
+		return err
+	}
+
+	return service.smsSender.Send(message)
+}
+
+// normalizePhoneNumber rewrites a profile phone number to E.164, leaving an empty
+// number as is
+func normalizePhoneNumber(phoneNumber *string) *custom.ErrorResult {
+	if *phoneNumber == constant.Empty {
+		return nil
+	}
+
+	normalized, err := sms.NormalizePhoneNumber(*phoneNumber, config.GetConfig().SMSConfig.DefaultCountryCode)
+	if err != nil {
+		errRes := custom.BuildBadReqErrResult(constant.InvalidPhoneNumberCode, constant.InvalidPhoneNumberMessage, "PhoneNumber")
+		return &errRes
+	}
+	*phoneNumber = normalized
+
 	return nil
 }
+
+// UnlockAccount lifts a login lockout with the token of an unlock email
+func (service *UserService) UnlockAccount(request *dto.UnlockAccountRequest, ipAddress string) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceUnlockAccountMethod), commonLogFields...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceUnlockAccountMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceUnlockAccountMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.actionRepo = createActionTokenRepository(service.serviceContext.RequestID)
+	service.attemptRepo = createLoginAttemptRepository(service.serviceContext.RequestID)
+
+	token, err := service.actionRepo.Consume(internaldto.TokenPurposeAccountUnlock, auth.HashToken(request.Token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildBadReqErrResult(constant.ErrInvalidUnlockTokenCode, constant.ErrInvalidUnlockTokenMsg, "Token")
+			return &errRes
+		}
+		return checkRepoError(commonLogFields, repository.ActionTokenRepositoryConsumeMethod, err)
+	}
+
+	user, err := service.userRepo.GetUserByID(token.UserID)
+	if err != nil {
+		return checkRepoError(commonLogFields, repository.UserRepositoryGetUserByIDMethod, err)
+	}
+
+	// A successful attempt resets the failure count of the account
+	err = service.attemptRepo.Record(strings.ToLower(user.Email), ipAddress, true)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.LoginAttemptRepositoryRecordMethod), logFields...)
+		return buildInsertErrFromRepo("login attempt", err)
+	}
+
+	return nil
+}
+
+// checkLoginThrottle rejects a login while the client IP or the account has too many
+// recent failures, and returns the number of recent failures of the account
+func (service *UserService) checkLoginThrottle(attemptKey, ipAddress string) (int64, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(checkLoginThrottleMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(checkLoginThrottleMethod), commonLogFields...)
+
+	authConfig := config.GetConfig().AuthConfig
+	since := time.Now().Add(-authConfig.LoginAttemptWindow)
+	throttledErr := custom.BuildTooManyRequestsErrResult(constant.ErrLoginThrottledCode, constant.ErrLoginThrottledMsg, "Credentials")
+
+	ipFailures, err := service.attemptRepo.CountIPFailures(ipAddress, since)
+	if err != nil {
+		return 0, checkRepoError(commonLogFields, repository.LoginAttemptRepositoryCountIPFailuresMethod, err)
+	}
+	if ipFailures >= int64(authConfig.LoginMaxIPFailures) {
+		log.Logger.Warn(constant.ErrLoginThrottledMsg, log.TraceMethodInputs(commonLogFields, ipAddress)...)
+		return 0, &throttledErr
+	}
+
+	emailFailures, err := service.attemptRepo.CountEmailFailures(attemptKey, since)
+	if err != nil {
+		return 0, checkRepoError(commonLogFields, repository.LoginAttemptRepositoryCountEmailFailuresMethod, err)
+	}
+	if emailFailures >= int64(authConfig.LoginMaxAccountFailures) {
+		log.Logger.Warn(constant.ErrLoginThrottledMsg, commonLogFields...)
+		return 0, &throttledErr
+	}
+
+	return emailFailures, nil
+}
+
+// recordLoginFailure stores a failed attempt, emails an unlock link when it locks the
+// account and slows the response down, doubling the delay with every failure
+func (service *UserService) recordLoginFailure(attemptKey, ipAddress string, failures int64) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(recordLoginFailureMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(recordLoginFailureMethod), commonLogFields...)
+
+	authConfig := config.GetConfig().AuthConfig
+
+	if err := service.attemptRepo.Record(attemptKey, ipAddress, false); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.LoginAttemptRepositoryRecordMethod), log.TraceError(commonLogFields, err)...)
+	}
+
+	if failures == int64(authConfig.LoginMaxAccountFailures) {
+		if err := service.sendUnlockEmail(attemptKey); err != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredFrom(recordLoginFailureMethod), log.TraceError(commonLogFields, err)...)
+		}
+	}
+
+	delay := authConfig.LoginFailureDelay
+	for i := int64(1); i < failures && delay < authConfig.LoginMaxFailureDelay; i++ {
+		delay *= 2
+	}
+	time.Sleep(min(delay, authConfig.LoginMaxFailureDelay))
+}
+
+// sendUnlockEmail emails an unlock link when the locked email belongs to an account
+func (service *UserService) sendUnlockEmail(email string) error {
+	user, err := service.userRepo.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	authConfig := config.GetConfig().AuthConfig
+	service.actionRepo = createActionTokenRepository(service.serviceContext.RequestID)
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	err = service.actionRepo.Create(&internaldto.UserActionToken{
+		UserID:    user.ID,
+		Purpose:   internaldto.TokenPurposeAccountUnlock,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(authConfig.AccountUnlockTTL),
+	})
+	if err != nil {
+		return err
+	}
+
+	link := authConfig.AccountUnlockURL + "?token=" + url.QueryEscape(token)
+
+	return service.sendMail(mail.AccountUnlockMessage(user.Email, link))
+}
+
+// sendMail delivers a message with the configured mailer
+func (service *UserService) sendMail(message mail.Message) (err error) {
+	service.mailer, err = mail.NewMailer()
+	if err != nil {
+		return err
+	}
+
+	return service.mailer.Send(message)
+}
+
+// ListSessions lists the active sessions of a user, flagging the one of the current request
+func (service *UserService) ListSessions(userID, currentSessionID uint) (response []dto.SessionResponse, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceListSessionsMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceListSessionsMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceListSessionsMethod), log.TraceMethodOutputs(commonLogFields, response, errResult)...)
+	}()
+
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	sessions, err := service.sessionRepo.ListActive(userID)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryListActiveMethod), logFields...)
+		return nil, buildSelectErrFromRepo("user sessions", err)
+	}
+
+	response = make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, dto.SessionResponse{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			LastUsedAt: session.LastUsedAt,
+			CreatedAt:  session.CreatedAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return response, nil
+}
+
+// RevokeSession signs a user out of one of their sessions
+func (service *UserService) RevokeSession(userID, sessionID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceRevokeSessionMethod), log.TraceMethodInputs(commonLogFields, userID, sessionID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceRevokeSessionMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceRevokeSessionMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	err := service.sessionRepo.Revoke(userID, sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.SessionNotFoundCode, constant.SessionNotFoundMessage, "Session")
+			return &errRes
+		}
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryRevokeMethod), logFields...)
+		return buildUpdateErrFromRepo("user session", err)
+	}
+
+	return nil
+}
+
+// RevokeAllSessions signs a user out everywhere, including the current session
+func (service *UserService) RevokeAllSessions(userID uint) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceRevokeAllMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceRevokeAllMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceRevokeAllMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	err := service.sessionRepo.RevokeAllForUser(userID, 0)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryRevokeAllForUserMethod), logFields...)
+		return buildUpdateErrFromRepo("user sessions", err)
+	}
+
+	return nil
+}
+
+// ExportData collects everything stored about a user for a data subject access request
+func (service *UserService) ExportData(userID uint) (response *dto.UserDataExport, errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceExportDataMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceExportDataMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceExportDataMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+	service.roleRepo = createRoleRepository(service.serviceContext.RequestID)
+	service.propertyRepo = createPropertyRepository(service.serviceContext.RequestID)
+	service.favoriteRepo = createFavoriteRepository(service.serviceContext.RequestID)
+
+	profile, err := service.userRepo.GetProfile(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.UserRepositoryGetProfileMethod, err)
+	}
+
+	roles, err := service.roleRepo.GetUserRoles(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.RoleRepositoryGetUserRolesMethod, err)
+	}
+
+	properties, err := service.propertyRepo.ListAllByUserID(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.PropertyRepositoryListMethod, err)
+	}
+
+	favorites, err := service.favoriteRepo.ListAll(userID)
+	if err != nil {
+		return nil, checkRepoError(commonLogFields, repository.FavoriteRepositoryListAllMethod, err)
+	}
+
+	sessions, errResult := service.ListSessions(userID, 0)
+	if errResult != nil {
+		return nil, errResult
+	}
+
+	response = &dto.UserDataExport{
+		ExportedAt: time.Now(),
+		Profile:    profile,
+		Roles:      make([]string, 0, len(roles)),
+		Properties: properties,
+		Favorites:  favorites,
+		Sessions:   sessions,
+	}
+	for _, role := range roles {
+		response.Roles = append(response.Roles, role.Name)
+	}
+
+	return response, nil
+}
+
+// DeleteAccount anonymises the account of a user after confirming their password.
+// Listings are unpublished or deleted as configured and every session ends.
+func (service *UserService) DeleteAccount(userID uint, request *dto.DeleteAccountRequest) (errResult *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceDeleteAccountMethod), log.TraceMethodInputs(commonLogFields, userID)...)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Logger.Error(constant.PanicOccurred, log.TraceStack(commonLogFields, debug.Stack())...)
+			errResult = buildPanicErr(UserServiceDeleteAccountMethod)
+		}
+		log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceDeleteAccountMethod), log.TraceMethodOutputs(commonLogFields, nil, errResult)...)
+	}()
+
+	service.userRepo = createUserRepository(service.serviceContext.RequestID)
+
+	deleteListings := config.GetConfig().AuthConfig.AccountDeletionListingPolicy == authconfig.ListingPolicyDelete
+	err := service.userRepo.DeleteAccount(userID, request.Password, deleteListings)
+	if err != nil {
+		logFields := log.TraceError(commonLogFields, err)
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.UserRepositoryDeleteAccountMethod), logFields...)
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			errRes := custom.BuildUnauthorizedErrResult(constant.InvalidCredentialsCode, constant.InvalidCredentialsMessage, "Password")
+			return &errRes
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildNotFoundErrResult(constant.UserNotFoundCode, constant.UserNotFoundMessage, "User")
+			return &errRes
+		}
+		return buildDeleteErrFromRepo("user account", err)
+	}
+
+	return nil
+}
+
+// startSession records a new signed in device and issues its first token pair
+func (service *UserService) startSession(userID uint, email, deviceName string, client dto.ClientInfo) (*dto.TokenResponse, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(startSessionMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(startSessionMethod), commonLogFields...)
+
+	service.sessionRepo = createSessionRepository(service.serviceContext.RequestID)
+
+	now := time.Now()
+	session := &internaldto.UserSession{
+		UserID:     userID,
+		DeviceName: deviceName,
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(config.GetConfig().AuthConfig.RefreshTokenTTL),
+	}
+	if err := service.sessionRepo.Create(session); err != nil {
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(repository.SessionRepositoryCreateMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildInsertErrFromRepo("user session", err)
+	}
+
+	return service.issueTokens(userID, email, session.ID, nil)
+}
+
+// issueTokens signs a new access token and stores a new refresh token for the user session.
+// When previous is set the new refresh token replaces it.
+func (service *UserService) issueTokens(userID uint, email string, sessionID uint, previous *internaldto.RefreshToken) (*dto.TokenResponse, *custom.ErrorResult) {
+	commonLogFields := log.CommonLogField(service.serviceContext.RequestID)
+	log.Logger.Debug(log.TraceMsgFuncStart(UserServiceIssueTokensMethod), commonLogFields...)
+	defer log.Logger.Debug(log.TraceMsgFuncEnd(UserServiceIssueTokensMethod), commonLogFields...)
+
+	accessToken, accessExpiresAt, err := auth.IssueAccessToken(userID, email, sessionID)
+	if err != nil {
+		log.Logger.Error(constant.ErrOccurredWhenSigningJWTToken, log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.ErrAccessTokenCode, constant.ErrOccurredWhenGenAccessTokenMsg, err.Error())
+		return nil, &errRes
+	}
+
+	refreshToken, refreshTokenHash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		log.Logger.Error(constant.ErrOccurredWhenGenAccessTokenMsg, log.TraceError(commonLogFields, err)...)
+		errRes := custom.BuildInternalServerErrResult(constant.ErrAccessTokenCode, constant.ErrOccurredWhenGenAccessTokenMsg, err.Error())
+		return nil, &errRes
+	}
+
+	if service.tokenRepo == nil {
+		service.tokenRepo = createTokenRepository(service.serviceContext.RequestID)
+	}
+
+	newToken := &internaldto.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: time.Now().Add(config.GetConfig().AuthConfig.RefreshTokenTTL),
+	}
+
+	if previous == nil {
+		err = service.tokenRepo.CreateRefreshToken(newToken)
+	} else {
+		err = service.tokenRepo.RotateRefreshToken(previous.ID, newToken)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrInvalidRefreshTokenCode, constant.ErrInvalidRefreshTokenMsg, "RefreshToken")
+			return nil, &errRes
+		}
+		log.Logger.Error(log.TraceMsgErrorOccurredFrom(UserServiceIssueTokensMethod), log.TraceError(commonLogFields, err)...)
+		return nil, buildInsertErrFromRepo("refresh token", err)
+	}
+
+	return &dto.TokenResponse{
+		Token:                 accessToken,
+		TokenExpiresAt:        accessExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: newToken.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/app/routes/dto"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
+	"github.com/chazool/serendib_asia_service/pkg/config/authconfig"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+var testClient = dto.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "test"}
+
+// login signs the user in on a new device and returns its tokens
+func login(t *testing.T, email, password string) *dto.TokenResponse {
+	t.Helper()
+	response, errResult := CreateUserService("test", nil).Login(&dto.UserLoginRequest{Email: email, Password: password}, testClient)
+	if errResult != nil {
+		t.Fatalf("Login() error = %v", errResult.ErrorList)
+	}
+	return &response.TokenResponse
+}
+
+func refresh(refreshToken string) (*dto.TokenResponse, *custom.ErrorResult) {
+	return CreateUserService("test", nil).RefreshToken(&dto.RefreshTokenRequest{RefreshToken: refreshToken})
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	store := setupServiceTest(t)
+	store.addUser("owner@example.com", "password")
+	tokens := login(t, "owner@example.com", "password")
+
+	rotated, errResult := refresh(tokens.RefreshToken)
+	if errResult != nil {
+		t.Fatalf("RefreshToken() error = %v", errResult.ErrorList)
+	}
+	if rotated.RefreshToken == tokens.RefreshToken || rotated.Token == "" {
+		t.Errorf("RefreshToken() did not issue a new token pair")
+	}
+
+	if _, errResult = refresh(rotated.RefreshToken); errResult != nil {
+		t.Errorf("RefreshToken(rotated) error = %v", errResult.ErrorList)
+	}
+}
+
+func TestRefreshTokenReuseRevokesEverySession(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	phone := login(t, "owner@example.com", "password")
+	laptop := login(t, "owner@example.com", "password")
+
+	rotated, errResult := refresh(phone.RefreshToken)
+	if errResult != nil {
+		t.Fatalf("RefreshToken() error = %v", errResult.ErrorList)
+	}
+
+	// presenting the already rotated token again looks like a stolen token
+	_, errResult = refresh(phone.RefreshToken)
+	assertErrorStatus(t, errResult, http.StatusUnauthorized)
+	assertErrorCode(t, errResult, constant.ErrInvalidRefreshTokenCode)
+
+	if active := store.activeSessions(user.ID); active != 0 {
+		t.Errorf("active sessions after reuse = %d, want 0", active)
+	}
+	for _, token := range []string{rotated.RefreshToken, laptop.RefreshToken} {
+		_, errResult = refresh(token)
+		assertErrorStatus(t, errResult, http.StatusUnauthorized)
+	}
+}
+
+func TestRefreshTokenUnknown(t *testing.T) {
+	setupServiceTest(t)
+
+	_, errResult := refresh("not-a-token")
+	assertErrorStatus(t, errResult, http.StatusUnauthorized)
+	assertErrorCode(t, errResult, constant.ErrInvalidRefreshTokenCode)
+}
+
+func TestLoginSuspendedUser(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	store.suspend(user.ID)
+
+	_, errResult := CreateUserService("test", nil).Login(&dto.UserLoginRequest{Email: "owner@example.com", Password: "password"}, testClient)
+	assertErrorStatus(t, errResult, http.StatusForbidden)
+	assertErrorCode(t, errResult, constant.ErrAccountSuspendedCode)
+	if active := store.activeSessions(user.ID); active != 0 {
+		t.Errorf("active sessions of a suspended user = %d, want 0", active)
+	}
+}
+
+func TestRefreshTokenSuspendedUser(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	tokens := login(t, "owner@example.com", "password")
+	store.suspend(user.ID)
+
+	_, errResult := refresh(tokens.RefreshToken)
+	assertErrorStatus(t, errResult, http.StatusForbidden)
+	assertErrorCode(t, errResult, constant.ErrAccountSuspendedCode)
+	if active := store.activeSessions(user.ID); active != 0 {
+		t.Errorf("active sessions after a refresh by a suspended user = %d, want 0", active)
+	}
+
+	// the refresh token is revoked, so lifting the suspension does not revive it
+	store.users[user.ID].SuspendedAt = nil
+	_, errResult = refresh(tokens.RefreshToken)
+	assertErrorStatus(t, errResult, http.StatusUnauthorized)
+}
+
+func TestResetPasswordTokenIsSingleUse(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	login(t, "owner@example.com", "password")
+	token := store.issueActionToken(user.ID, internaldto.TokenPurposePasswordReset, time.Hour)
+
+	reset := func() *custom.ErrorResult {
+		return CreateUserService("test", nil).ResetPassword(&dto.ResetPasswordRequest{Token: token, NewPassword: "new-password"})
+	}
+	if errResult := reset(); errResult != nil {
+		t.Fatalf("ResetPassword() error = %v", errResult.ErrorList)
+	}
+	if active := store.activeSessions(user.ID); active != 0 {
+		t.Errorf("active sessions after a reset = %d, want 0", active)
+	}
+	login(t, "owner@example.com", "new-password")
+
+	errResult := reset()
+	assertErrorStatus(t, errResult, http.StatusBadRequest)
+	assertErrorCode(t, errResult, constant.ErrInvalidResetTokenCode)
+}
+
+func TestResetPasswordRejectsToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		purpose string
+		ttl     time.Duration
+	}{
+		{name: "expired", purpose: internaldto.TokenPurposePasswordReset, ttl: -time.Minute},
+		{name: "other purpose", purpose: internaldto.TokenPurposeEmailVerification, ttl: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := setupServiceTest(t)
+			user := store.addUser("owner@example.com", "password")
+			token := store.issueActionToken(user.ID, tt.purpose, tt.ttl)
+
+			errResult := CreateUserService("test", nil).ResetPassword(&dto.ResetPasswordRequest{Token: token, NewPassword: "new-password"})
+			assertErrorStatus(t, errResult, http.StatusBadRequest)
+			assertErrorCode(t, errResult, constant.ErrInvalidResetTokenCode)
+			login(t, "owner@example.com", "password")
+		})
+	}
+}
+
+func TestVerifyEmailTokenIsSingleUse(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	token := store.issueActionToken(user.ID, internaldto.TokenPurposeEmailVerification, time.Hour)
+
+	verify := func() *custom.ErrorResult {
+		return CreateUserService("test", nil).VerifyEmail(&dto.VerifyEmailRequest{Token: token})
+	}
+	if errResult := verify(); errResult != nil {
+		t.Fatalf("VerifyEmail() error = %v", errResult.ErrorList)
+	}
+	if store.users[user.ID].EmailVerifiedAt == nil {
+		t.Errorf("VerifyEmail() did not mark the email verified")
+	}
+
+	errResult := verify()
+	assertErrorStatus(t, errResult, http.StatusBadRequest)
+	assertErrorCode(t, errResult, constant.ErrInvalidVerifyTokenCode)
+}
+
+func TestResendVerificationRetiresPreviousToken(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	token := store.issueActionToken(user.ID, internaldto.TokenPurposeEmailVerification, time.Hour)
+
+	if errResult := CreateUserService("test", nil).ResendVerification(user.ID); errResult != nil {
+		t.Fatalf("ResendVerification() error = %v", errResult.ErrorList)
+	}
+
+	errResult := CreateUserService("test", nil).VerifyEmail(&dto.VerifyEmailRequest{Token: token})
+	assertErrorStatus(t, errResult, http.StatusBadRequest)
+	assertErrorCode(t, errResult, constant.ErrInvalidVerifyTokenCode)
+}
+
+// tryLogin attempts a login from the test client and returns its error
+func tryLogin(email, password string) *custom.ErrorResult {
+	_, errResult := CreateUserService("test", nil).Login(&dto.UserLoginRequest{Email: email, Password: password}, testClient)
+	return errResult
+}
+
+func TestLoginLocksAccountAfterFailures(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+
+	for i := 0; i < 5; i++ {
+		errResult := tryLogin("owner@example.com", "wrong")
+		assertErrorStatus(t, errResult, http.StatusUnauthorized)
+		assertErrorCode(t, errResult, constant.InvalidCredentialsCode)
+	}
+	if unused := store.unusedActionTokens(user.ID, internaldto.TokenPurposeAccountUnlock); unused != 1 {
+		t.Errorf("unlock tokens after the lockout = %d, want 1", unused)
+	}
+
+	// the right password no longer helps once the account is locked
+	errResult := tryLogin("owner@example.com", "password")
+	assertErrorStatus(t, errResult, http.StatusTooManyRequests)
+	assertErrorCode(t, errResult, constant.ErrLoginThrottledCode)
+
+	// the address is matched case insensitively, so changing its case does not get around the lock
+	errResult = tryLogin("Owner@Example.com", "password")
+	assertErrorStatus(t, errResult, http.StatusTooManyRequests)
+}
+
+func TestLoginLockoutForUnknownEmail(t *testing.T) {
+	setupServiceTest(t)
+
+	for i := 0; i < 5; i++ {
+		assertErrorStatus(t, tryLogin("nobody@example.com", "wrong"), http.StatusUnauthorized)
+	}
+	assertErrorStatus(t, tryLogin("nobody@example.com", "wrong"), http.StatusTooManyRequests)
+}
+
+func TestLoginBlocksClientIP(t *testing.T) {
+	t.Setenv(authconfig.LoginMaxIPFailures, "3")
+	store := setupServiceTest(t)
+	store.addUser("owner@example.com", "password")
+
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		assertErrorStatus(t, tryLogin(email, "wrong"), http.StatusUnauthorized)
+	}
+	assertErrorStatus(t, tryLogin("owner@example.com", "password"), http.StatusTooManyRequests)
+}
+
+func TestUnlockAccountLiftsLockout(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("owner@example.com", "password")
+	for i := 0; i < 5; i++ {
+		tryLogin("owner@example.com", "wrong")
+	}
+	token := store.issueActionToken(user.ID, internaldto.TokenPurposeAccountUnlock, time.Hour)
+
+	unlock := func() *custom.ErrorResult {
+		return CreateUserService("test", nil).UnlockAccount(&dto.UnlockAccountRequest{Token: token}, testClient.IPAddress)
+	}
+	if errResult := unlock(); errResult != nil {
+		t.Fatalf("UnlockAccount() error = %v", errResult.ErrorList)
+	}
+	login(t, "owner@example.com", "password")
+
+	errResult := unlock()
+	assertErrorStatus(t, errResult, http.StatusBadRequest)
+	assertErrorCode(t, errResult, constant.ErrInvalidUnlockTokenCode)
+}
+
+func TestDeleteAccount(t *testing.T) {
+	tests := []struct {
+		name         string
+		policy       string
+		wantListing  bool
+		wantUnlisted bool
+	}{
+		{name: "listings deleted", policy: authconfig.ListingPolicyDelete},
+		{name: "listings unpublished", policy: authconfig.ListingPolicyUnpublish, wantListing: true, wantUnlisted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(authconfig.AccountDeletionListingPolicy, tt.policy)
+			store := setupServiceTest(t)
+			user := store.addUser("seller@example.com", "password")
+			organization := store.addOrganization(user.ID)
+			listing := store.addProperty(user.ID, nil, constant.PropertyStatusPublished)
+			agencyListing := store.addProperty(user.ID, &organization.ID, constant.PropertyStatusPublished)
+
+			if errResult := CreateUserService("test", nil).DeleteAccount(user.ID, &dto.DeleteAccountRequest{Password: "password"}); errResult != nil {
+				t.Fatalf("DeleteAccount() error = %v", errResult.ErrorList)
+			}
+
+			if user.DeletedAt == nil || user.Email == "seller@example.com" {
+				t.Errorf("account not anonymised: deleted at %v, email %q", user.DeletedAt, user.Email)
+			}
+			remaining := store.property(listing.ID)
+			if (remaining != nil) != tt.wantListing {
+				t.Fatalf("personal listing kept = %v, want %v", remaining != nil, tt.wantListing)
+			}
+			if remaining != nil && (remaining.Base != nil && remaining.DeletedAt.Valid) != tt.wantUnlisted {
+				t.Errorf("personal listing unpublished = %v, want %v", !tt.wantUnlisted, tt.wantUnlisted)
+			}
+			if kept := store.property(agencyListing.ID); kept == nil || kept.Base != nil {
+				t.Error("organization listing changed, want it left with the organization")
+			}
+		})
+	}
+}
+
+func TestDeleteAccountRejects(t *testing.T) {
+	store := setupServiceTest(t)
+	user := store.addUser("seller@example.com", "password")
+
+	errResult := CreateUserService("test", nil).DeleteAccount(user.ID, &dto.DeleteAccountRequest{Password: "wrong"})
+	assertErrorStatus(t, errResult, http.StatusUnauthorized)
+	assertErrorCode(t, errResult, constant.InvalidCredentialsCode)
+	if user.DeletedAt != nil {
+		t.Fatal("account deleted with a wrong password")
+	}
+
+	if errResult = CreateUserService("test", nil).DeleteAccount(user.ID, &dto.DeleteAccountRequest{Password: "password"}); errResult != nil {
+		t.Fatalf("DeleteAccount() error = %v", errResult.ErrorList)
+	}
+	errResult = CreateUserService("test", nil).DeleteAccount(user.ID, &dto.DeleteAccountRequest{Password: "password"})
+	assertErrorStatus(t, errResult, http.StatusNotFound)
+	assertErrorCode(t, errResult, constant.UserNotFoundCode)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/config"
+)
+
+// Mint Token
+// Prints a bearer token accepted by the local token verifier (TOKEN_VERIFIER=local).
+// The signing key is read from AUTH_LOCAL_SIGNING_KEY.
+//
+//	go run ./cmd/mint_token -user 1 -email user@example.com
+func main() {
+	userID := flag.Uint("user", 0, "users.id the token is issued to")
+	email := flag.String("email", "", "email of the user, used when -user is not set")
+	ttl := flag.Duration("ttl", time.Hour, "token lifetime")
+	flag.Parse()
+
+	config.InitConfig()
+
+	verifier, err := auth.NewLocalVerifier(config.GetConfig().AuthConfig.LocalSigningKey)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	token, err := verifier.Mint(*userID, *email, *ttl)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Println(token)
+}
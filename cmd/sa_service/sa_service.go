@@ -1,9 +1,12 @@
 package main
 
 import (
+	"github.com/chazool/serendib_asia_service/app/repository"
 	"github.com/chazool/serendib_asia_service/app/routes"
 	"github.com/chazool/serendib_asia_service/app/routes/dto"
 	"github.com/chazool/serendib_asia_service/app/routes/handler/validator"
+	"github.com/chazool/serendib_asia_service/app/services"
+	internaldto "github.com/chazool/serendib_asia_service/internal/dto"
 
 	"github.com/chazool/serendib_asia_service/pkg/config"
 	"github.com/chazool/serendib_asia_service/pkg/config/appconfig"
@@ -18,13 +21,24 @@ import (
 func init() {
 	config.InitConfig()
 
-	err := dbconfig.InitDBConWithAutoMigrate(&dto.Property{})
+	err := dbconfig.InitDBConWithAutoMigrate(&dto.Property{}, &internaldto.PropertyStatusChange{}, &internaldto.PropertyRenewal{}, &internaldto.PropertyModerationDecision{}, &internaldto.PropertyScreening{}, &internaldto.PropertyDuplicate{}, &internaldto.PropertyRevision{}, &internaldto.UserSession{}, &internaldto.RefreshToken{}, &internaldto.UserActionToken{}, &internaldto.LoginAttempt{}, &internaldto.PhoneVerification{}, &internaldto.APIKey{}, &internaldto.Organization{}, &internaldto.OrganizationMember{}, &internaldto.OrganizationInvitation{}, &internaldto.User{}, &internaldto.Role{}, &internaldto.Permission{}, &internaldto.UserRole{})
 	if err != nil {
 		log.Logger.Error(constant.DBInitFailError, zap.Error(err))
 	}
 
+	err = repository.MigratePropertySearch()
+	if err != nil {
+		log.Logger.Error(constant.DBErrorOccurredWhenAutoMigrate, zap.Error(err))
+	}
+
+	err = repository.MigratePropertyLocation()
+	if err != nil {
+		log.Logger.Error(constant.DBErrorOccurredWhenAutoMigrate, zap.Error(err))
+	}
+
 	utils.HTTPClientImplInstance = utils.NewHTTPClientUtil()
 	validator.InitValidator()
+	services.InitScreener()
 }
 
 // @SecurityDefinitions.api apiKey
@@ -42,5 +56,5 @@ func init() {
 // This is the main entry point for the Serendib Asia Service
 // It initializes the configuration, database connection, and starts the API routes
 func main() {
-	appconfig.Start(routes.APIRoutes)
+	appconfig.Start(routes.APIRoutes, services.RunListingExpiryJob)
 }
@@ -0,0 +1,118 @@
+package dto
+
+import "time"
+
+// RefreshToken represents the refresh_tokens table
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	SessionID    uint       `gorm:"not null;default:0;index" json:"session_id"`
+	TokenHash    string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+	CreatedAt    time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the RefreshToken model
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// token purposes of a UserActionToken
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeAccountUnlock     = "account_unlock"
+)
+
+// UserActionToken represents the user_action_tokens table. It holds single use
+// tokens that are emailed to a user to confirm an action.
+type UserActionToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"type:varchar(30);not null" json:"purpose"`
+	TokenHash string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the UserActionToken model
+func (UserActionToken) TableName() string {
+	return "user_action_tokens"
+}
+
+// LoginAttempt represents the login_attempts table. Attempts are keyed by the
+// submitted email, so unknown addresses are throttled the same as real accounts.
+type LoginAttempt struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Email     string    `gorm:"type:varchar(100);not null;index" json:"email"`
+	IPAddress string    `gorm:"type:varchar(45);not null;index" json:"ip_address"`
+	Succeeded bool      `gorm:"not null" json:"succeeded"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP;index" json:"created_at"`
+}
+
+// TableName specifies the table name for the LoginAttempt model
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
+
+// UserSession represents the user_sessions table. A session is one signed in device;
+// its refresh tokens rotate but the session stays the same until it is revoked.
+type UserSession struct {
+	ID         uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	DeviceName string     `gorm:"type:varchar(100)" json:"device_name"`
+	IPAddress  string     `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	LastUsedAt time.Time  `gorm:"not null" json:"last_used_at"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the UserSession model
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+// PhoneVerification represents the phone_verifications table. It holds the one-time
+// codes sent by SMS to confirm the phone number of a user.
+type PhoneVerification struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	PhoneNumber string     `gorm:"type:varchar(16);not null" json:"phone_number"`
+	CodeHash    string     `gorm:"type:varchar(64);not null" json:"-"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt   time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt      *time.Time `json:"used_at"`
+	CreatedAt   time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the PhoneVerification model
+func (PhoneVerification) TableName() string {
+	return "phone_verifications"
+}
+
+// APIKey represents the api_keys table. Partner integrations authenticate with the
+// key instead of a bearer token; only its hash is stored.
+type APIKey struct {
+	ID     uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	Name   string `gorm:"type:varchar(100);not null" json:"name"`
+	// Prefix is the start of the key, shown so owners can tell their keys apart
+	Prefix  string `gorm:"type:varchar(16);not null" json:"prefix"`
+	KeyHash string `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	// Scopes is the comma separated list of granted scopes
+	Scopes     string     `gorm:"type:text;not null" json:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,57 @@
+package dto
+
+import "time"
+
+// Organization represents the organizations table, an agency whose members share listings
+type Organization struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"type:varchar(150);not null" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Email       string    `gorm:"type:varchar(100)" json:"email"`
+	PhoneNumber string    `gorm:"type:varchar(16)" json:"phone_number"`
+	Website     string    `gorm:"type:text" json:"website"`
+	LogoURL     string    `gorm:"type:text" json:"logo_url"`
+	City        string    `gorm:"type:varchar(50)" json:"city"`
+	CreatedBy   uint      `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Organization model
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMember represents the organization_members table
+type OrganizationMember struct {
+	OrganizationID uint `gorm:"primaryKey" json:"organization_id"`
+	UserID         uint `gorm:"primaryKey;index" json:"user_id"`
+	// Role is one of owner, manager or agent
+	Role      string    `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the OrganizationMember model
+func (OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// OrganizationInvitation represents the organization_invitations table. Only the
+// hash of the emailed token is stored.
+type OrganizationInvitation struct {
+	ID             uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	OrganizationID uint       `gorm:"not null;index" json:"organization_id"`
+	Email          string     `gorm:"type:varchar(100);not null" json:"email"`
+	Role           string     `gorm:"type:varchar(20);not null" json:"role"`
+	TokenHash      string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	InvitedBy      uint       `gorm:"not null" json:"invited_by"`
+	ExpiresAt      time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time `json:"accepted_at"`
+	RevokedAt      *time.Time `json:"revoked_at"`
+	CreatedAt      time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the OrganizationInvitation model
+func (OrganizationInvitation) TableName() string {
+	return "organization_invitations"
+}
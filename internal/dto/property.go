@@ -88,3 +88,110 @@ func (PropertyAmenity) TableName() string {
 func (PropertyUtility) TableName() string {
 	return "property_utilities"
 }
+
+// PropertyStatusChange represents the property_status_changes table, one row for
+// every transition of a listing between statuses
+type PropertyStatusChange struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID uint   `gorm:"not null;index" json:"property_id"`
+	FromStatus string `gorm:"type:varchar(20);not null" json:"from_status"`
+	ToStatus   string `gorm:"type:varchar(20);not null" json:"to_status"`
+	// ChangedBy is the user who made the change, zero for changes made by the service itself
+	ChangedBy uint      `gorm:"not null;default:0" json:"changed_by"`
+	Note      string    `gorm:"type:varchar(500)" json:"note"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the PropertyStatusChange model
+func (PropertyStatusChange) TableName() string {
+	return "property_status_changes"
+}
+
+// PropertyModerationDecision represents the property_moderation_decisions table, one row
+// for every approval or rejection of a listing under review
+type PropertyModerationDecision struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID  uint      `gorm:"not null;index" json:"property_id"`
+	ModeratorID uint      `gorm:"not null" json:"moderator_id"`
+	Decision    string    `gorm:"type:varchar(20);not null" json:"decision"`
+	ReasonCode  string    `gorm:"type:varchar(40)" json:"reason_code"`
+	Note        string    `gorm:"type:varchar(500)" json:"note"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the PropertyModerationDecision model
+func (PropertyModerationDecision) TableName() string {
+	return "property_moderation_decisions"
+}
+
+// PropertyRevision represents the property_revisions table, one row for every stored version of
+// the content of a listing
+type PropertyRevision struct {
+	ID         uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID uint `gorm:"not null;uniqueIndex:idx_property_revisions_version" json:"property_id"`
+	Version    int  `gorm:"not null;uniqueIndex:idx_property_revisions_version" json:"version"`
+	// Snapshot holds the content of the listing as a JSON dto.PropertySnapshot
+	Snapshot  string    `gorm:"type:text;not null" json:"snapshot"`
+	ChangedBy uint      `gorm:"not null" json:"changed_by"`
+	Note      string    `gorm:"type:varchar(200)" json:"note"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the PropertyRevision model
+func (PropertyRevision) TableName() string {
+	return "property_revisions"
+}
+
+// PropertyDuplicate represents the property_duplicates table, one row for every pair of listings
+// flagged as likely the same property. PropertyID is the newer listing of the pair.
+type PropertyDuplicate struct {
+	ID            uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID    uint `gorm:"not null;uniqueIndex:idx_property_duplicates_pair" json:"property_id"`
+	DuplicateOfID uint `gorm:"not null;uniqueIndex:idx_property_duplicates_pair;index" json:"duplicate_of_id"`
+	Score         int  `gorm:"not null" json:"score"`
+	// Reasons holds the shared signals, comma separated
+	Reasons    string     `gorm:"type:varchar(100);not null" json:"reasons"`
+	Status     string     `gorm:"type:varchar(20);not null;default:open;index" json:"status"`
+	ResolvedBy *uint      `json:"resolved_by"`
+	Note       string     `gorm:"type:varchar(500)" json:"note"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+	CreatedAt  time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for the PropertyDuplicate model
+func (PropertyDuplicate) TableName() string {
+	return "property_duplicates"
+}
+
+// PropertyScreening represents the property_screenings table, one row for every time
+// the content screening flagged the text of a listing
+type PropertyScreening struct {
+	ID         uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID uint `gorm:"not null;index" json:"property_id"`
+	Score      int  `gorm:"not null" json:"score"`
+	// Matches holds the matched rules as a JSON array
+	Matches   string    `gorm:"type:text;not null" json:"matches"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the PropertyScreening model
+func (PropertyScreening) TableName() string {
+	return "property_screenings"
+}
+
+// PropertyRenewal represents the property_renewals table, one row for every time
+// a listing period was extended
+type PropertyRenewal struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID        uint       `gorm:"not null;index" json:"property_id"`
+	UserID            uint       `gorm:"not null;index:idx_property_renewals_on_user_id_created_at" json:"user_id"`
+	PreviousExpiresAt *time.Time `json:"previous_expires_at"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	CreatedAt         time.Time  `gorm:"default:CURRENT_TIMESTAMP;index:idx_property_renewals_on_user_id_created_at" json:"created_at"`
+}
+
+// TableName specifies the table name for the PropertyRenewal model
+func (PropertyRenewal) TableName() string {
+	return "property_renewals"
+}
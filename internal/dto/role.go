@@ -0,0 +1,44 @@
+package dto
+
+import "time"
+
+// Role represents the roles table
+type Role struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string `gorm:"type:varchar(50);unique;not null" json:"name"`
+	Description string `gorm:"type:text" json:"description"`
+	// ListingLimit is the number of properties a holder of the role may list, 0 means unlimited
+	ListingLimit int          `gorm:"not null;default:0" json:"listing_limit"`
+	Permissions  []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+	CreatedAt    time.Time    `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// Permission represents the permissions table
+type Permission struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code        string    `gorm:"type:varchar(100);unique;not null" json:"code"`
+	Description string    `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the Permission model
+func (Permission) TableName() string {
+	return "permissions"
+}
+
+// UserRole represents the user_roles table
+type UserRole struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	RoleID    uint      `gorm:"primaryKey" json:"role_id"`
+	CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for the UserRole model
+func (UserRole) TableName() string {
+	return "user_roles"
+}
@@ -4,13 +4,20 @@ import "time"
 
 // User represents the users table
 type User struct {
-	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	FullName     string    `gorm:"type:varchar(100);not null" json:"full_name"`
-	Email        string    `gorm:"type:varchar(100);unique;not null" json:"email"`
-	PasswordHash string    `gorm:"type:text;not null" json:"-"`
-	PhoneNumber  string    `gorm:"type:varchar(15)" json:"phone_number"`
-	ProfileImage string    `gorm:"type:text" json:"profile_image"`
-	CreatedAt    time.Time `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	FullName        string     `gorm:"type:varchar(100);not null" json:"full_name"`
+	Email           string     `gorm:"type:varchar(100);unique;not null" json:"email"`
+	PasswordHash    string     `gorm:"type:text;not null" json:"-"`
+	PhoneNumber     string     `gorm:"type:varchar(16)" json:"phone_number"`
+	ProfileImage    string     `gorm:"type:text" json:"profile_image"`
+	SuspendedAt     *time.Time `json:"suspended_at,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty"`
+	// PasswordChangedAt invalidates access tokens issued before a password reset
+	PasswordChangedAt *time.Time `json:"-"`
+	// DeletedAt marks an account anonymised on its owner's request
+	DeletedAt *time.Time `json:"-"`
+	CreatedAt time.Time  `gorm:"default:CURRENT_TIMESTAMP" json:"created_at"`
 }
 
 // TableName specifies the table name for the User model
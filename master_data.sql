@@ -63,3 +63,28 @@ INSERT INTO amenities (name, created_at, updated_at) VALUES
 ('School Nearby', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
 ('Hospital Nearby', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP),
 ('Public Transport Access', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP);
+
+-- ==============================
+-- 🔹 roles
+-- ==============================
+
+INSERT INTO roles (name, description, listing_limit, created_at) VALUES
+('admin', 'Platform administrator', 0, CURRENT_TIMESTAMP),
+('agent', 'Real estate agent', 50, CURRENT_TIMESTAMP),
+('member', 'Registered member', 5, CURRENT_TIMESTAMP);
+
+-- ==============================
+-- 🔹 permissions
+-- ==============================
+
+INSERT INTO permissions (code, description, created_at) VALUES
+('properties:moderate', 'Moderate property listings', CURRENT_TIMESTAMP),
+('lookups:manage', 'Manage lookup tables', CURRENT_TIMESTAMP),
+('users:manage', 'Assign roles and suspend users', CURRENT_TIMESTAMP);
+
+-- ==============================
+-- 🔹 role_permissions
+-- ==============================
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r CROSS JOIN permissions p WHERE r.name = 'admin';
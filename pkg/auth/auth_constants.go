@@ -0,0 +1,62 @@
+package auth
+
+// token constants
+const (
+	keyIDHeader      = "kid"
+	accessTokenType  = "access"
+	opaqueTokenBytes = 32
+	localIssuer      = "local"
+)
+
+// claim constants
+const (
+	emailClaim         = "email"
+	emailVerifiedClaim = "email_verified"
+)
+
+// identity provider constants
+const (
+	ProviderJWT      = "jwt"
+	ProviderFirebase = "firebase"
+	ProviderLocal    = "local"
+)
+
+// role constants
+const (
+	RoleAdmin  = "admin"
+	RoleAgent  = "agent"
+	RoleMember = "member"
+)
+
+// organisation role constants, ordered from most to least privileged
+const (
+	OrganizationRoleOwner   = "owner"
+	OrganizationRoleManager = "manager"
+	OrganizationRoleAgent   = "agent"
+)
+
+// OrganizationRoles lists every role a member of an organisation can hold
+var OrganizationRoles = []string{OrganizationRoleOwner, OrganizationRoleManager, OrganizationRoleAgent}
+
+// permission constants
+const (
+	PermissionModerateProperties = "properties:moderate"
+	PermissionManageLookups      = "lookups:manage"
+	PermissionManageUsers        = "users:manage"
+)
+
+// api key scope constants
+const (
+	ScopePropertiesWrite = "properties:write"
+	ScopeImagesWrite     = "images:write"
+	ScopeLeadsRead       = "leads:read"
+)
+
+// APIKeyScopes lists every scope an API key can be granted
+var APIKeyScopes = []string{ScopePropertiesWrite, ScopeImagesWrite, ScopeLeadsRead}
+
+// APIKeyPrefix starts every API key, so leaked keys are easy to recognise
+const APIKeyPrefix = "sak_"
+
+// PrincipalContextKey is the fiber context key the authenticated principal is stored under
+const PrincipalContextKey = "principal"
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/firebase"
+
+	firebaseApp "firebase.google.com/go/v4"
+	firebaseAuth "firebase.google.com/go/v4/auth"
+	"google.golang.org/api/option"
+)
+
+// FirebaseVerifier verifies Firebase ID tokens. The Firebase client is created on the
+// first verification so the service can start without Google credentials.
+type FirebaseVerifier struct {
+	_         struct{}
+	projectID string
+	mu        sync.Mutex
+	client    *firebaseAuth.Client
+}
+
+// NewFirebaseVerifier creates a new instance of FirebaseVerifier
+func NewFirebaseVerifier(projectID string) *FirebaseVerifier {
+	return &FirebaseVerifier{
+		projectID: projectID,
+	}
+}
+
+// Verify verifies a Firebase ID token. The email is only part of the identity when
+// Firebase has verified it, since it is used to match the user.
+func (verifier *FirebaseVerifier) Verify(ctx context.Context, token string) (*Identity, error) {
+	client, err := verifier.getClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decodedToken, err := client.VerifyIDToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: ProviderFirebase,
+		Subject:  decodedToken.UID,
+		IssuedAt: time.Unix(decodedToken.IssuedAt, 0),
+	}
+	if verified, _ := decodedToken.Claims[emailVerifiedClaim].(bool); verified {
+		identity.Email, _ = decodedToken.Claims[emailClaim].(string)
+	}
+
+	return identity, nil
+}
+
+func (verifier *FirebaseVerifier) getClient(ctx context.Context) (*firebaseAuth.Client, error) {
+	verifier.mu.Lock()
+	defer verifier.mu.Unlock()
+
+	if verifier.client != nil {
+		return verifier.client, nil
+	}
+
+	firebaseConfig := firebaseApp.Config{
+		ProjectID: verifier.projectID,
+	}
+	opt := option.WithCredentialsJSON(firebase.GetServiceAccountJSON())
+
+	app, err := firebaseApp.NewApp(ctx, &firebaseConfig, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := app.Auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier.client = client
+	return client, nil
+}
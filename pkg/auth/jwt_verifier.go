@@ -0,0 +1,39 @@
+package auth
+
+import "context"
+
+// JWTVerifier verifies access tokens issued by this service
+type JWTVerifier struct {
+	_ struct{}
+}
+
+// NewJWTVerifier creates a new instance of JWTVerifier
+func NewJWTVerifier() *JWTVerifier {
+	return &JWTVerifier{}
+}
+
+// Verify verifies an access token issued by IssueAccessToken
+func (verifier *JWTVerifier) Verify(_ context.Context, token string) (*Identity, error) {
+	claims, err := ParseAccessToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := claims.UserID()
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider:  ProviderJWT,
+		Subject:   claims.Subject,
+		UserID:    userID,
+		Email:     claims.Email,
+		SessionID: claims.SessionID,
+	}
+	if claims.IssuedAt != nil {
+		identity.IssuedAt = claims.IssuedAt.Time
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"slices"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+)
+
+// Identity is the verified subject of a bearer token, before it is mapped to a user
+type Identity struct {
+	// Provider is the issuer family that verified the token
+	Provider string
+	// Subject is the provider specific subject of the token
+	Subject string
+	// UserID is set when the provider already knows the users.id
+	UserID uint
+	Email  string
+	// IssuedAt is when the token was issued, zero when the provider does not say
+	IssuedAt time.Time
+	// SessionID is the user session the token belongs to, zero for external providers
+	SessionID uint
+}
+
+// Principal is the authenticated user of a request
+type Principal struct {
+	ID          uint
+	Email       string
+	Roles       []string
+	Permissions []string
+	// SessionID is the session of the current request, zero when not session bound
+	SessionID uint
+	// EmailVerified is set when the user confirmed their email address
+	EmailVerified bool
+	// APIKeyID is the API key the request authenticated with, zero for bearer tokens
+	APIKeyID uint
+	// Scopes limits what an API key request may do, unused for bearer tokens
+	Scopes []string
+}
+
+// HasRole reports whether the principal holds the given role
+func (principal *Principal) HasRole(role string) bool {
+	return slices.Contains(principal.Roles, role)
+}
+
+// HasPermission reports whether any role of the principal grants the given permission
+func (principal *Principal) HasPermission(permission string) bool {
+	return principal != nil && slices.Contains(principal.Permissions, permission)
+}
+
+// HasScope reports whether the principal may act within the given API key scope.
+// Bearer token principals are not limited by scopes.
+func (principal *Principal) HasScope(scope string) bool {
+	return principal != nil && (principal.APIKeyID == 0 || slices.Contains(principal.Scopes, scope))
+}
+
+// PrincipalResolver maps a verified identity to a user of this service
+type PrincipalResolver func(requestID string, identity *Identity) (*Principal, *custom.ErrorResult)
+
+// APIKeyResolver maps an API key to the principal of its owner
+type APIKeyResolver func(requestID, key string) (*Principal, *custom.ErrorResult)
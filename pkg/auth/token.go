@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrNoActiveSigningKey is returned when the active key id has no configured secret
+	ErrNoActiveSigningKey = errors.New("no active jwt signing key configured")
+	// ErrUnknownSigningKey is returned when a token references a key id that is not configured
+	ErrUnknownSigningKey = errors.New("unknown jwt signing key")
+	// ErrInvalidTokenType is returned when a token is used for a purpose it was not issued for
+	ErrInvalidTokenType = errors.New("invalid token type")
+	// ErrUnknownTokenVerifier is returned when TOKEN_VERIFIER names no known verifier
+	ErrUnknownTokenVerifier = errors.New("unknown token verifier")
+	// ErrNoLocalSigningKey is returned when the local verifier is selected without a key
+	ErrNoLocalSigningKey = errors.New("no local signing key configured")
+)
+
+// AccessClaims are the claims carried by an access token issued by this service
+type AccessClaims struct {
+	Email     string `json:"email,omitempty"`
+	TokenType string `json:"typ"`
+	// SessionID is the user_sessions.id the token was issued for
+	SessionID uint `json:"sid,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// UserID returns the users.id the token was issued to
+func (claims *AccessClaims) UserID() (uint, error) {
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(id), nil
+}
+
+// IssueAccessToken signs a short lived access token for the given user session with the active key
+func IssueAccessToken(userID uint, email string, sessionID uint) (token string, expiresAt time.Time, err error) {
+	authConfig := config.GetConfig().AuthConfig
+
+	secret, ok := authConfig.SigningKeys[authConfig.ActiveKeyID]
+	if !ok {
+		return "", time.Time{}, ErrNoActiveSigningKey
+	}
+
+	now := time.Now()
+	expiresAt = now.Add(authConfig.AccessTokenTTL)
+	claims := AccessClaims{
+		Email:     email,
+		TokenType: accessTokenType,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    authConfig.Issuer,
+			Subject:   strconv.FormatUint(uint64(userID), 10),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	jwtToken.Header[keyIDHeader] = authConfig.ActiveKeyID
+
+	token, err = jwtToken.SignedString([]byte(secret))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiresAt, nil
+}
+
+// ParseAccessToken verifies the signature, issuer and expiry of an access token.
+// Tokens signed with any configured key are accepted so keys can be rotated.
+func ParseAccessToken(token string) (*AccessClaims, error) {
+	authConfig := config.GetConfig().AuthConfig
+
+	claims := &AccessClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header[keyIDHeader].(string)
+		secret, ok := authConfig.SigningKeys[kid]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSigningKey, kid)
+		}
+		return []byte(secret), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(authConfig.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.TokenType != accessTokenType {
+		return nil, ErrInvalidTokenType
+	}
+
+	return claims, nil
+}
+
+// GenerateRefreshToken returns a random opaque refresh token and the hash to persist
+func GenerateRefreshToken() (token, tokenHash string, err error) {
+	return GenerateOpaqueToken()
+}
+
+// GenerateOpaqueToken returns a random URL safe token and the hash to persist.
+// Only the hash is stored so a leaked table cannot be replayed.
+func GenerateOpaqueToken() (token, tokenHash string, err error) {
+	buf := make([]byte, opaqueTokenBytes)
+	if _, err = rand.Read(buf); err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the hex encoded SHA-256 of an opaque token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateNumericCode returns a random numeric code of the given length, as sent by
+// SMS, and the hash to persist
+func GenerateNumericCode(length int) (code, codeHash string, err error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", "", err
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+
+	code = string(digits)
+	return code, HashToken(code), nil
+}
+
+// GenerateAPIKey returns a new API key and the hash to persist. The key is only
+// shown to its owner once.
+func GenerateAPIKey() (key, keyHash string, err error) {
+	token, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	key = APIKeyPrefix + token
+	return key, HashToken(key), nil
+}
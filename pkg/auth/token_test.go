@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/config/authconfig"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testIssuer = "serendib_test"
+
+// useSigningKeys builds the config with the given key ring and active key
+func useSigningKeys(t *testing.T, keys, activeKeyID string) {
+	t.Helper()
+	t.Setenv(config.LogLevel, "FATAL")
+	t.Setenv(authconfig.JWTIssuer, testIssuer)
+	t.Setenv(authconfig.JWTSigningKeys, keys)
+	t.Setenv(authconfig.JWTActiveKeyID, activeKeyID)
+	config.InitConfig()
+}
+
+// signClaims signs claims with a key of the ring, as a token from elsewhere would be
+func signClaims(t *testing.T, claims AccessClaims, kid, secret string) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header[keyIDHeader] = kid
+
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return signed
+}
+
+func validClaims() AccessClaims {
+	now := time.Now()
+	return AccessClaims{
+		Email:     "owner@example.com",
+		TokenType: accessTokenType,
+		SessionID: 7,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Subject:   "42",
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	}
+}
+
+func TestIssueAndParseAccessToken(t *testing.T) {
+	useSigningKeys(t, "k1:first-secret", "k1")
+
+	token, expiresAt, err := IssueAccessToken(42, "owner@example.com", 7)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+	if time.Until(expiresAt) <= 0 {
+		t.Errorf("IssueAccessToken() expiresAt = %v, want in the future", expiresAt)
+	}
+
+	claims, err := ParseAccessToken(token)
+	if err != nil {
+		t.Fatalf("ParseAccessToken() error = %v", err)
+	}
+
+	userID, err := claims.UserID()
+	if err != nil || userID != 42 {
+		t.Errorf("UserID() = %d, %v, want 42", userID, err)
+	}
+	if claims.Email != "owner@example.com" || claims.SessionID != 7 || claims.Issuer != testIssuer {
+		t.Errorf("ParseAccessToken() claims = %+v", claims)
+	}
+}
+
+func TestIssueAccessTokenWithoutActiveKey(t *testing.T) {
+	useSigningKeys(t, "k1:first-secret", "k2")
+
+	if _, _, err := IssueAccessToken(42, "owner@example.com", 7); !errors.Is(err, ErrNoActiveSigningKey) {
+		t.Errorf("IssueAccessToken() error = %v, want %v", err, ErrNoActiveSigningKey)
+	}
+}
+
+func TestParseAccessTokenRejects(t *testing.T) {
+	useSigningKeys(t, "k1:first-secret", "k1")
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	noExpiry := validClaims()
+	noExpiry.ExpiresAt = nil
+
+	otherIssuer := validClaims()
+	otherIssuer.Issuer = "someone_else"
+
+	otherType := validClaims()
+	otherType.TokenType = "refresh"
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"expired", signClaims(t, expired, "k1", "first-secret"), jwt.ErrTokenExpired},
+		{"no expiry", signClaims(t, noExpiry, "k1", "first-secret"), jwt.ErrTokenRequiredClaimMissing},
+		{"other issuer", signClaims(t, otherIssuer, "k1", "first-secret"), jwt.ErrTokenInvalidIssuer},
+		{"other token type", signClaims(t, otherType, "k1", "first-secret"), ErrInvalidTokenType},
+		{"unknown key id", signClaims(t, validClaims(), "k9", "first-secret"), ErrUnknownSigningKey},
+		{"wrong secret", signClaims(t, validClaims(), "k1", "not-the-secret"), jwt.ErrTokenSignatureInvalid},
+		{"not a token", "not.a.token", jwt.ErrTokenMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseAccessToken(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("ParseAccessToken() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAccessTokenRejectsOtherAlgorithm(t *testing.T) {
+	useSigningKeys(t, "k1:first-secret", "k1")
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, validClaims())
+	token.Header[keyIDHeader] = "k1"
+	signed, err := token.SignedString([]byte("first-secret"))
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+
+	if _, err = ParseAccessToken(signed); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("ParseAccessToken() error = %v, want %v", err, jwt.ErrTokenSignatureInvalid)
+	}
+}
+
+func TestParseAccessTokenKeyRotation(t *testing.T) {
+	useSigningKeys(t, "k1:first-secret", "k1")
+	oldToken, _, err := IssueAccessToken(42, "owner@example.com", 7)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	// k2 signs new tokens, k1 is kept so tokens it signed still verify
+	useSigningKeys(t, "k1:first-secret,k2:second-secret", "k2")
+	newToken, _, err := IssueAccessToken(42, "owner@example.com", 7)
+	if err != nil {
+		t.Fatalf("IssueAccessToken() error = %v", err)
+	}
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &AccessClaims{})
+	if err != nil || parsed.Header[keyIDHeader] != "k2" {
+		t.Errorf("new token kid = %v, %v, want k2", parsed.Header[keyIDHeader], err)
+	}
+	for name, token := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err = ParseAccessToken(token); err != nil {
+			t.Errorf("ParseAccessToken(%s token) error = %v", name, err)
+		}
+	}
+
+	// once k1 is retired its tokens stop verifying
+	useSigningKeys(t, "k2:second-secret", "k2")
+	if _, err = ParseAccessToken(oldToken); !errors.Is(err, ErrUnknownSigningKey) {
+		t.Errorf("ParseAccessToken(old token) error = %v, want %v", err, ErrUnknownSigningKey)
+	}
+	if _, err = ParseAccessToken(newToken); err != nil {
+		t.Errorf("ParseAccessToken(new token) error = %v", err)
+	}
+}
+
+func TestAccessClaimsUserID(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    uint
+		wantErr bool
+	}{
+		{subject: "42", want: 42},
+		{subject: strconv.FormatUint(1<<40, 10), want: 1 << 40},
+		{subject: "", wantErr: true},
+		{subject: "abc", wantErr: true},
+		{subject: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		claims := AccessClaims{RegisteredClaims: jwt.RegisteredClaims{Subject: tt.subject}}
+		got, err := claims.UserID()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("UserID(%q) = %d, %v, want %d, error %t", tt.subject, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/config/authconfig"
+)
+
+// TokenVerifier verifies a bearer token and returns the identity it was issued to
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*Identity, error)
+}
+
+// NewTokenVerifier creates the token verifier selected by the TOKEN_VERIFIER config
+func NewTokenVerifier() (TokenVerifier, error) {
+	authConfig := config.GetConfig().AuthConfig
+
+	switch authConfig.TokenVerifier {
+	case authconfig.VerifierJWT:
+		return NewJWTVerifier(), nil
+	case authconfig.VerifierFirebase:
+		return NewFirebaseVerifier(config.GetConfig().FirebaseConfig.ProjectID), nil
+	case authconfig.VerifierLocal:
+		return NewLocalVerifier(authConfig.LocalSigningKey)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTokenVerifier, authConfig.TokenVerifier)
+	}
+}
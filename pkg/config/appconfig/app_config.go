@@ -27,7 +27,8 @@ type service struct {
 }
 
 // Start initializes the application and starts listening for requests.
-func Start(routes func(*fiber.App)) {
+// Every job is started in its own goroutine and its context is cancelled on shutdown.
+func Start(routes func(*fiber.App), jobs ...func(context.Context)) {
 	appConfig := config.GetConfig()
 	app := web.SetupFiber(appConfig.ChildFiberProcessIdleTimeout)
 
@@ -43,6 +44,11 @@ func Start(routes func(*fiber.App)) {
 	// call routes
 	routes(app)
 
+	// start background jobs
+	for _, job := range jobs {
+		go job(ctx)
+	}
+
 	// fiber will listen from a defferent gorouting
 	go func() {
 		if err := app.Listen(":" + appConfig.SrvListenPort); err != nil {
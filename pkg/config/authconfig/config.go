@@ -0,0 +1,200 @@
+package authconfig
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// JWT constants
+	JWTIssuer          = "JWT_ISSUER"
+	JWTSigningKeys     = "JWT_SIGNING_KEYS"
+	JWTActiveKeyID     = "JWT_ACTIVE_KEY_ID"
+	JWTAccessTokenTTL  = "JWT_ACCESS_TOKEN_TTL"
+	JWTRefreshTokenTTL = "JWT_REFRESH_TOKEN_TTL"
+
+	// token verifier constants
+	TokenVerifier   = "TOKEN_VERIFIER"
+	LocalSigningKey = "AUTH_LOCAL_SIGNING_KEY"
+
+	// password reset constants
+	PasswordResetTTL = "PASSWORD_RESET_TTL"
+	PasswordResetURL = "PASSWORD_RESET_URL"
+
+	// email verification constants
+	EmailVerificationTTL = "EMAIL_VERIFICATION_TTL"
+	EmailVerificationURL = "EMAIL_VERIFICATION_URL"
+	RequireVerifiedEmail = "REQUIRE_VERIFIED_EMAIL"
+
+	// login throttling constants
+	LoginAttemptWindow      = "LOGIN_ATTEMPT_WINDOW"
+	LoginMaxAccountFailures = "LOGIN_MAX_ACCOUNT_FAILURES"
+	LoginMaxIPFailures      = "LOGIN_MAX_IP_FAILURES"
+	LoginFailureDelay       = "LOGIN_FAILURE_DELAY"
+	LoginMaxFailureDelay    = "LOGIN_MAX_FAILURE_DELAY"
+	AccountUnlockTTL        = "ACCOUNT_UNLOCK_TTL"
+	AccountUnlockURL        = "ACCOUNT_UNLOCK_URL"
+
+	// phone verification constants
+	PhoneOTPTTL            = "PHONE_OTP_TTL"
+	PhoneOTPLength         = "PHONE_OTP_LENGTH"
+	PhoneOTPMaxAttempts    = "PHONE_OTP_MAX_ATTEMPTS"
+	PhoneOTPResendInterval = "PHONE_OTP_RESEND_INTERVAL"
+
+	// account deletion constants
+	AccountDeletionListingPolicy = "ACCOUNT_DELETION_LISTING_POLICY"
+
+	// organization invitation constants
+	OrganizationInvitationTTL = "ORGANIZATION_INVITATION_TTL"
+	OrganizationInvitationURL = "ORGANIZATION_INVITATION_URL"
+
+	keySeparator   = ","
+	keyIDSeparator = ":"
+)
+
+// token verifier values
+const (
+	VerifierJWT      = "jwt"
+	VerifierFirebase = "firebase"
+	VerifierLocal    = "local"
+)
+
+// account deletion listing policy values
+const (
+	ListingPolicyUnpublish = "unpublish"
+	ListingPolicyDelete    = "delete"
+)
+
+// Config holds the token issuing configuration
+type Config struct {
+	_           struct{}
+	Issuer      string
+	ActiveKeyID string
+	// SigningKeys maps a key id to its HMAC secret. Every key is accepted for
+	// verification, only ActiveKeyID is used to sign new tokens.
+	SigningKeys     map[string]string `json:"-"`
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// TokenVerifier selects how bearer tokens are verified: jwt, firebase or local
+	TokenVerifier string
+	// LocalSigningKey is the static HMAC key of the local verifier
+	LocalSigningKey string `json:"-"`
+	// PasswordResetTTL is how long a password reset token stays valid
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the frontend page the reset token is appended to
+	PasswordResetURL string
+	// EmailVerificationTTL is how long an email verification token stays valid
+	EmailVerificationTTL time.Duration
+	// EmailVerificationURL is the frontend page the verification token is appended to
+	EmailVerificationURL string
+	// RequireVerifiedEmail blocks unverified users from creating listings
+	RequireVerifiedEmail bool
+	// LoginAttemptWindow is the period failed logins are counted over. A locked
+	// account unlocks once its failures age out of the window.
+	LoginAttemptWindow time.Duration
+	// LoginMaxAccountFailures locks an account after this many failures in the window
+	LoginMaxAccountFailures int
+	// LoginMaxIPFailures blocks a client IP after this many failures in the window
+	LoginMaxIPFailures int
+	// LoginFailureDelay is the delay after the first failure, doubled on every further one
+	LoginFailureDelay time.Duration
+	// LoginMaxFailureDelay caps the delay after a failed login
+	LoginMaxFailureDelay time.Duration
+	// AccountUnlockTTL is how long an emailed unlock link stays valid
+	AccountUnlockTTL time.Duration
+	// AccountUnlockURL is the frontend page the unlock token is appended to
+	AccountUnlockURL string
+	// PhoneOTPTTL is how long an SMS verification code stays valid
+	PhoneOTPTTL time.Duration
+	// PhoneOTPLength is the number of digits of an SMS verification code
+	PhoneOTPLength int
+	// PhoneOTPMaxAttempts is how many wrong guesses burn a verification code
+	PhoneOTPMaxAttempts int
+	// PhoneOTPResendInterval is the minimum wait between two codes for the same user
+	PhoneOTPResendInterval time.Duration
+	// AccountDeletionListingPolicy decides what happens to the listings of a deleted
+	// account: unpublish hides them, delete removes them with their images
+	AccountDeletionListingPolicy string
+	// OrganizationInvitationTTL is how long an emailed organization invitation stays valid
+	OrganizationInvitationTTL time.Duration
+	// OrganizationInvitationURL is the frontend page the invitation token is appended to
+	OrganizationInvitationURL string
+}
+
+// SetDefaultConfig sets the default token issuing configuration
+func SetDefaultConfig() {
+	viper.SetDefault(JWTIssuer, "serendib_asia_service")
+	viper.SetDefault(JWTSigningKeys, "")
+	viper.SetDefault(JWTActiveKeyID, "")
+	viper.SetDefault(JWTAccessTokenTTL, 15*time.Minute)
+	viper.SetDefault(JWTRefreshTokenTTL, 30*24*time.Hour)
+	viper.SetDefault(TokenVerifier, VerifierJWT)
+	viper.SetDefault(LocalSigningKey, "")
+	viper.SetDefault(PasswordResetTTL, time.Hour)
+	viper.SetDefault(PasswordResetURL, "http://localhost:3000/reset-password")
+	viper.SetDefault(EmailVerificationTTL, 48*time.Hour)
+	viper.SetDefault(EmailVerificationURL, "http://localhost:3000/verify-email")
+	viper.SetDefault(RequireVerifiedEmail, true)
+	viper.SetDefault(LoginAttemptWindow, 15*time.Minute)
+	viper.SetDefault(LoginMaxAccountFailures, 5)
+	viper.SetDefault(LoginMaxIPFailures, 50)
+	viper.SetDefault(LoginFailureDelay, 250*time.Millisecond)
+	viper.SetDefault(LoginMaxFailureDelay, 5*time.Second)
+	viper.SetDefault(AccountUnlockTTL, time.Hour)
+	viper.SetDefault(AccountUnlockURL, "http://localhost:3000/unlock-account")
+	viper.SetDefault(PhoneOTPTTL, 10*time.Minute)
+	viper.SetDefault(PhoneOTPLength, 6)
+	viper.SetDefault(PhoneOTPMaxAttempts, 5)
+	viper.SetDefault(PhoneOTPResendInterval, time.Minute)
+	viper.SetDefault(AccountDeletionListingPolicy, ListingPolicyUnpublish)
+	viper.SetDefault(OrganizationInvitationTTL, 7*24*time.Hour)
+	viper.SetDefault(OrganizationInvitationURL, "http://localhost:3000/accept-invitation")
+}
+
+// GetConfig returns the token issuing configuration
+func GetConfig() Config {
+	return Config{
+		Issuer:                       viper.GetString(JWTIssuer),
+		ActiveKeyID:                  viper.GetString(JWTActiveKeyID),
+		SigningKeys:                  parseSigningKeys(viper.GetString(JWTSigningKeys)),
+		AccessTokenTTL:               viper.GetDuration(JWTAccessTokenTTL),
+		RefreshTokenTTL:              viper.GetDuration(JWTRefreshTokenTTL),
+		TokenVerifier:                strings.ToLower(viper.GetString(TokenVerifier)),
+		LocalSigningKey:              viper.GetString(LocalSigningKey),
+		PasswordResetTTL:             viper.GetDuration(PasswordResetTTL),
+		PasswordResetURL:             viper.GetString(PasswordResetURL),
+		EmailVerificationTTL:         viper.GetDuration(EmailVerificationTTL),
+		EmailVerificationURL:         viper.GetString(EmailVerificationURL),
+		RequireVerifiedEmail:         viper.GetBool(RequireVerifiedEmail),
+		LoginAttemptWindow:           viper.GetDuration(LoginAttemptWindow),
+		LoginMaxAccountFailures:      viper.GetInt(LoginMaxAccountFailures),
+		LoginMaxIPFailures:           viper.GetInt(LoginMaxIPFailures),
+		LoginFailureDelay:            viper.GetDuration(LoginFailureDelay),
+		LoginMaxFailureDelay:         viper.GetDuration(LoginMaxFailureDelay),
+		AccountUnlockTTL:             viper.GetDuration(AccountUnlockTTL),
+		AccountUnlockURL:             viper.GetString(AccountUnlockURL),
+		PhoneOTPTTL:                  viper.GetDuration(PhoneOTPTTL),
+		PhoneOTPLength:               viper.GetInt(PhoneOTPLength),
+		PhoneOTPMaxAttempts:          viper.GetInt(PhoneOTPMaxAttempts),
+		PhoneOTPResendInterval:       viper.GetDuration(PhoneOTPResendInterval),
+		AccountDeletionListingPolicy: strings.ToLower(viper.GetString(AccountDeletionListingPolicy)),
+		OrganizationInvitationTTL:    viper.GetDuration(OrganizationInvitationTTL),
+		OrganizationInvitationURL:    viper.GetString(OrganizationInvitationURL),
+	}
+}
+
+// parseSigningKeys parses the "kid:secret,kid:secret" key ring format
+func parseSigningKeys(value string) map[string]string {
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, keySeparator) {
+		kid, secret, found := strings.Cut(strings.TrimSpace(pair), keyIDSeparator)
+		if !found || kid == "" || secret == "" {
+			continue
+		}
+		keys[kid] = secret
+	}
+
+	return keys
+}
@@ -7,7 +7,13 @@ import (
 	"sort"
 	"time"
 
+	"github.com/chazool/serendib_asia_service/pkg/config/authconfig"
+	"github.com/chazool/serendib_asia_service/pkg/config/duplicateconfig"
 	"github.com/chazool/serendib_asia_service/pkg/config/firebase"
+	"github.com/chazool/serendib_asia_service/pkg/config/listingconfig"
+	"github.com/chazool/serendib_asia_service/pkg/config/mailconfig"
+	"github.com/chazool/serendib_asia_service/pkg/config/screeningconfig"
+	"github.com/chazool/serendib_asia_service/pkg/config/smsconfig"
 	lg "github.com/chazool/serendib_asia_service/pkg/log"
 
 	"github.com/spf13/viper"
@@ -68,6 +74,12 @@ type CommonConfig struct {
 	LogConfig
 	DBConfig
 	FirebaseConfig               firebase.Config
+	AuthConfig                   authconfig.Config
+	MailConfig                   mailconfig.Config
+	SMSConfig                    smsconfig.Config
+	ListingConfig                listingconfig.Config
+	ScreeningConfig              screeningconfig.Config
+	DuplicateConfig              duplicateconfig.Config
 	ChildFiberProcessIdleTimeout time.Duration
 	SrvListenPort                string
 	Pprofenabled                 bool
@@ -128,6 +140,24 @@ func (config *CommonConfig) setDefaultConfig() {
 	// Set Firebase default config
 	firebase.SetDefaultConfig()
 
+	// Set token issuing default config
+	authconfig.SetDefaultConfig()
+
+	// Set mail default config
+	mailconfig.SetDefaultConfig()
+
+	// Set sms default config
+	smsconfig.SetDefaultConfig()
+
+	// Set listing default config
+	listingconfig.SetDefaultConfig()
+
+	// Set content screening default config
+	screeningconfig.SetDefaultConfig()
+
+	// Set duplicate detection default config
+	duplicateconfig.SetDefaultConfig()
+
 	// you can supply "console" or "File". if json, logging formant is in json
 	viper.SetDefault(LogFileName, JSON)
 	viper.SetDefault(Pprofenabled, "true")
@@ -157,6 +187,12 @@ func (config *CommonConfig) BuildConfig() *CommonConfig {
 		LogConfig:                    logConfig,
 		DBConfig:                     config.getDBConfig(),
 		FirebaseConfig:               firebase.GetConfig(),
+		AuthConfig:                   authconfig.GetConfig(),
+		MailConfig:                   mailconfig.GetConfig(),
+		SMSConfig:                    smsconfig.GetConfig(),
+		ListingConfig:                listingconfig.GetConfig(),
+		ScreeningConfig:              screeningconfig.GetConfig(),
+		DuplicateConfig:              duplicateconfig.GetConfig(),
 		ChildFiberProcessIdleTimeout: viper.GetDuration(ChildFiberProcessIdleTimeout),
 		SrvListenPort:                viper.GetString(SrvListenPort),
 		Pprofenabled:                 viper.GetBool(Pprofenabled),
@@ -26,6 +26,11 @@ func SetDBConnection(db *gorm.DB) {
 	dbCon = db
 }
 
+// IsPostgres reports whether the current database connection is PostgreSQL
+func IsPostgres() bool {
+	return dbCon != nil && dbCon.Dialector.Name() == postgresDialect
+}
+
 // InitDBConnection initializes the database connection
 // This function initializes the database connection based on the configuration
 // It supports both PostgreSQL and MySQL databases
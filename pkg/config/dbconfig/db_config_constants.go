@@ -8,6 +8,11 @@ const (
 	InitDBConWithAutoMigrateMethod = "InitDBConWithAutoMigrate"
 )
 
+// dialects
+const (
+	postgresDialect = "postgres"
+)
+
 // log constants
 const (
 	OpenGormDBConnection = "Open GORM DB connection"
@@ -0,0 +1,54 @@
+package duplicateconfig
+
+import (
+	"github.com/spf13/viper"
+)
+
+const (
+	// duplicate detection constants
+	DuplicateDetectionEnabled = "DUPLICATE_DETECTION_ENABLED"
+	DuplicateRadiusMeters     = "DUPLICATE_RADIUS_METERS"
+	DuplicateTitleSimilarity  = "DUPLICATE_TITLE_SIMILARITY"
+	DuplicatePriceTolerance   = "DUPLICATE_PRICE_TOLERANCE"
+	DuplicateMinScore         = "DUPLICATE_MIN_SCORE"
+	DuplicateCandidateLimit   = "DUPLICATE_CANDIDATE_LIMIT"
+)
+
+// Config holds the duplicate listing detection configuration
+type Config struct {
+	_ struct{}
+	// Enabled turns the duplicate detection on create, update and image upload on or off
+	Enabled bool
+	// RadiusMeters is how close two listings must be to count as the same location
+	RadiusMeters float64
+	// TitleSimilarity is the share of title words, from 0 to 1, two listings must have in common
+	TitleSimilarity float64
+	// PriceTolerance is how far, as a fraction, two prices may be apart to count as the same
+	PriceTolerance float64
+	// MinScore is the similarity score from which two listings are flagged as duplicates
+	MinScore int
+	// CandidateLimit caps the existing listings a listing is compared with
+	CandidateLimit int
+}
+
+// SetDefaultConfig sets the default duplicate detection configuration
+func SetDefaultConfig() {
+	viper.SetDefault(DuplicateDetectionEnabled, true)
+	viper.SetDefault(DuplicateRadiusMeters, 50)
+	viper.SetDefault(DuplicateTitleSimilarity, 0.6)
+	viper.SetDefault(DuplicatePriceTolerance, 0.1)
+	viper.SetDefault(DuplicateMinScore, 60)
+	viper.SetDefault(DuplicateCandidateLimit, 50)
+}
+
+// GetConfig returns the duplicate detection configuration
+func GetConfig() Config {
+	return Config{
+		Enabled:         viper.GetBool(DuplicateDetectionEnabled),
+		RadiusMeters:    viper.GetFloat64(DuplicateRadiusMeters),
+		TitleSimilarity: viper.GetFloat64(DuplicateTitleSimilarity),
+		PriceTolerance:  viper.GetFloat64(DuplicatePriceTolerance),
+		MinScore:        viper.GetInt(DuplicateMinScore),
+		CandidateLimit:  viper.GetInt(DuplicateCandidateLimit),
+	}
+}
@@ -0,0 +1,106 @@
+package listingconfig
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// listing expiry constants
+	ListingTTLSell         = "LISTING_TTL_SELL"
+	ListingTTLRent         = "LISTING_TTL_RENT"
+	ListingTTLStay         = "LISTING_TTL_STAY"
+	ListingExpiryReminder  = "LISTING_EXPIRY_REMINDER"
+	ListingExpiryInterval  = "LISTING_EXPIRY_INTERVAL"
+	ListingExpiryBatchSize = "LISTING_EXPIRY_BATCH_SIZE"
+	ListingManageURL       = "LISTING_MANAGE_URL"
+
+	// listing renewal constants
+	ListingRenewalWindow = "LISTING_RENEWAL_WINDOW"
+	ListingMaxRenewals   = "LISTING_MAX_RENEWALS"
+
+	// listing moderation constants
+	ListingReviewNew   = "LISTING_REVIEW_NEW"
+	ListingReviewEdits = "LISTING_REVIEW_EDITS"
+)
+
+// pricing type values, mirrored from the listing pricing types
+const (
+	pricingTypeSell = "sell"
+	pricingTypeRent = "rent"
+	pricingTypeStay = "stay"
+)
+
+// Config holds the listing expiry, renewal and moderation configuration
+type Config struct {
+	_ struct{}
+	// TTLSell, TTLRent and TTLStay are how long a listing of each pricing type stays
+	// published before it expires, and how far a renewal extends it
+	TTLSell time.Duration
+	TTLRent time.Duration
+	TTLStay time.Duration
+	// ExpiryReminder is how long before expiry the owner is reminded to renew
+	ExpiryReminder time.Duration
+	// ExpiryInterval is how often the expiry job runs, zero disables it
+	ExpiryInterval time.Duration
+	// ExpiryBatchSize caps the listings read at once by the expiry job, and the reminders sent in one run
+	ExpiryBatchSize int
+	// ManageURL is the frontend page the reminder email links to
+	ManageURL string
+	// RenewalWindow is the period renewals are counted over
+	RenewalWindow time.Duration
+	// MaxRenewals is how many renewals a user may make in the window, zero means no limit
+	MaxRenewals int
+	// ReviewNew sends listings to the moderation queue instead of publishing them
+	ReviewNew bool
+	// ReviewEdits sends a published listing back to the queue when its title, description,
+	// price or images are edited, including through the image endpoints
+	ReviewEdits bool
+}
+
+// SetDefaultConfig sets the default listing configuration
+func SetDefaultConfig() {
+	viper.SetDefault(ListingTTLSell, 90*24*time.Hour)
+	viper.SetDefault(ListingTTLRent, 30*24*time.Hour)
+	viper.SetDefault(ListingTTLStay, 60*24*time.Hour)
+	viper.SetDefault(ListingExpiryReminder, 3*24*time.Hour)
+	viper.SetDefault(ListingExpiryInterval, time.Hour)
+	viper.SetDefault(ListingExpiryBatchSize, 100)
+	viper.SetDefault(ListingManageURL, "http://localhost:3000/my-listings")
+	viper.SetDefault(ListingRenewalWindow, 30*24*time.Hour)
+	viper.SetDefault(ListingMaxRenewals, 5)
+	viper.SetDefault(ListingReviewNew, true)
+	viper.SetDefault(ListingReviewEdits, true)
+}
+
+// GetConfig returns the listing configuration
+func GetConfig() Config {
+	return Config{
+		TTLSell:         viper.GetDuration(ListingTTLSell),
+		TTLRent:         viper.GetDuration(ListingTTLRent),
+		TTLStay:         viper.GetDuration(ListingTTLStay),
+		ExpiryReminder:  viper.GetDuration(ListingExpiryReminder),
+		ExpiryInterval:  viper.GetDuration(ListingExpiryInterval),
+		ExpiryBatchSize: viper.GetInt(ListingExpiryBatchSize),
+		ManageURL:       viper.GetString(ListingManageURL),
+		RenewalWindow:   viper.GetDuration(ListingRenewalWindow),
+		MaxRenewals:     viper.GetInt(ListingMaxRenewals),
+		ReviewNew:       viper.GetBool(ListingReviewNew),
+		ReviewEdits:     viper.GetBool(ListingReviewEdits),
+	}
+}
+
+// TTL returns how long a listing of the given pricing type stays published
+func (config Config) TTL(pricingType string) time.Duration {
+	switch pricingType {
+	case pricingTypeSell:
+		return config.TTLSell
+	case pricingTypeRent:
+		return config.TTLRent
+	case pricingTypeStay:
+		return config.TTLStay
+	default:
+		return config.TTLRent
+	}
+}
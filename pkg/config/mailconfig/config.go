@@ -0,0 +1,64 @@
+package mailconfig
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// mail constants
+	MailDriver    = "MAIL_DRIVER"
+	MailFrom      = "MAIL_FROM"
+	MailOutboxDir = "MAIL_OUTBOX_DIR"
+
+	// SMTP constants
+	SMTPHost     = "SMTP_HOST"
+	SMTPPort     = "SMTP_PORT"
+	SMTPUsername = "SMTP_USERNAME"
+	SMTPPassword = "SMTP_PASSWORD"
+)
+
+// mail driver values
+const (
+	DriverSMTP   = "smtp"
+	DriverOutbox = "outbox"
+)
+
+// Config holds the outgoing mail configuration
+type Config struct {
+	_ struct{}
+	// Driver selects how mail is delivered: smtp or outbox
+	Driver string
+	From   string
+	// OutboxDir is the directory the outbox driver writes messages to
+	OutboxDir    string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string `json:"-"`
+}
+
+// SetDefaultConfig sets the default mail configuration
+func SetDefaultConfig() {
+	viper.SetDefault(MailDriver, DriverOutbox)
+	viper.SetDefault(MailFrom, "no-reply@serendib.asia")
+	viper.SetDefault(MailOutboxDir, "outbox")
+	viper.SetDefault(SMTPHost, "")
+	viper.SetDefault(SMTPPort, "587")
+	viper.SetDefault(SMTPUsername, "")
+	viper.SetDefault(SMTPPassword, "")
+}
+
+// GetConfig returns the mail configuration
+func GetConfig() Config {
+	return Config{
+		Driver:       strings.ToLower(viper.GetString(MailDriver)),
+		From:         viper.GetString(MailFrom),
+		OutboxDir:    viper.GetString(MailOutboxDir),
+		SMTPHost:     viper.GetString(SMTPHost),
+		SMTPPort:     viper.GetString(SMTPPort),
+		SMTPUsername: viper.GetString(SMTPUsername),
+		SMTPPassword: viper.GetString(SMTPPassword),
+	}
+}
@@ -0,0 +1,91 @@
+package screeningconfig
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// content screening constants
+	ScreeningEnabled        = "SCREENING_ENABLED"
+	ScreeningReviewScore    = "SCREENING_REVIEW_SCORE"
+	ScreeningBannedWords    = "SCREENING_BANNED_WORDS"
+	ScreeningWordWeight     = "SCREENING_WORD_WEIGHT"
+	ScreeningPatterns       = "SCREENING_PATTERNS"
+	ScreeningPatternWeight  = "SCREENING_PATTERN_WEIGHT"
+	ScreeningPriceTolerance = "SCREENING_PRICE_TOLERANCE"
+
+	wordSeparator       = ","
+	patternSeparator    = ";;"
+	patternNameSplitter = "="
+)
+
+// Config holds the listing content screening configuration
+type Config struct {
+	_ struct{}
+	// Enabled turns the screening of listing text on create and update on or off
+	Enabled bool
+	// ReviewScore is the risk score from which a listing is sent to manual review
+	ReviewScore int
+	// BannedWords are matched as whole words or phrases, ignoring case
+	BannedWords []string
+	// WordWeight is added to the score once when any banned word matches
+	WordWeight int
+	// Patterns maps a rule name to a regular expression matched against the listing text
+	Patterns map[string]string
+	// PatternWeight is added to the score for every pattern that matches
+	PatternWeight int
+	// PriceTolerance is how far, as a fraction, a price in the description may be from the listing price
+	PriceTolerance float64
+}
+
+// SetDefaultConfig sets the default content screening configuration
+func SetDefaultConfig() {
+	viper.SetDefault(ScreeningEnabled, true)
+	viper.SetDefault(ScreeningReviewScore, 50)
+	viper.SetDefault(ScreeningBannedWords, "")
+	viper.SetDefault(ScreeningWordWeight, 40)
+	viper.SetDefault(ScreeningPatterns, "")
+	viper.SetDefault(ScreeningPatternWeight, 40)
+	viper.SetDefault(ScreeningPriceTolerance, 0.25)
+}
+
+// GetConfig returns the content screening configuration
+func GetConfig() Config {
+	return Config{
+		Enabled:        viper.GetBool(ScreeningEnabled),
+		ReviewScore:    viper.GetInt(ScreeningReviewScore),
+		BannedWords:    parseWords(viper.GetString(ScreeningBannedWords)),
+		WordWeight:     viper.GetInt(ScreeningWordWeight),
+		Patterns:       parsePatterns(viper.GetString(ScreeningPatterns)),
+		PatternWeight:  viper.GetInt(ScreeningPatternWeight),
+		PriceTolerance: viper.GetFloat64(ScreeningPriceTolerance),
+	}
+}
+
+// parseWords parses the "word,word phrase" banned word list format
+func parseWords(value string) []string {
+	var words []string
+	for _, word := range strings.Split(value, wordSeparator) {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return words
+}
+
+// parsePatterns parses the "name=regex;;name=regex" pattern list format
+func parsePatterns(value string) map[string]string {
+	patterns := make(map[string]string)
+	for _, entry := range strings.Split(value, patternSeparator) {
+		name, pattern, found := strings.Cut(strings.TrimSpace(entry), patternNameSplitter)
+		if !found || name == "" || pattern == "" {
+			continue
+		}
+		patterns[strings.TrimSpace(name)] = pattern
+	}
+
+	return patterns
+}
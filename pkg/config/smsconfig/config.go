@@ -0,0 +1,45 @@
+package smsconfig
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// sms constants
+	SMSDriver             = "SMS_DRIVER"
+	SMSSenderID           = "SMS_SENDER_ID"
+	SMSDefaultCountryCode = "SMS_DEFAULT_COUNTRY_CODE"
+)
+
+// sms driver values
+const (
+	DriverLog = "log"
+)
+
+// Config holds the outgoing SMS configuration
+type Config struct {
+	_ struct{}
+	// Driver selects how SMS messages are delivered: log
+	Driver   string
+	SenderID string
+	// DefaultCountryCode is assumed for phone numbers entered in national format
+	DefaultCountryCode string
+}
+
+// SetDefaultConfig sets the default SMS configuration
+func SetDefaultConfig() {
+	viper.SetDefault(SMSDriver, DriverLog)
+	viper.SetDefault(SMSSenderID, "SerendibAsia")
+	viper.SetDefault(SMSDefaultCountryCode, "94")
+}
+
+// GetConfig returns the SMS configuration
+func GetConfig() Config {
+	return Config{
+		Driver:             strings.ToLower(viper.GetString(SMSDriver)),
+		SenderID:           viper.GetString(SMSSenderID),
+		DefaultCountryCode: strings.TrimPrefix(viper.GetString(SMSDefaultCountryCode), "+"),
+	}
+}
@@ -17,7 +17,7 @@ type ErrorResult struct {
 
 // Error implements error.
 func (e *ErrorResult) Error() string {
-	panic("unimplemented")
+	return GetErrorMessage(e)
 }
 
 // ErrorInfo use to define error information of the ErrorResult
@@ -110,6 +110,28 @@ func BuildForbiddenErrResult(errCode, errMessage, errDetail string) ErrorResult
 	}
 }
 
+// BuildUnauthorizedErrResult used to build ErrorResult with unauthorized code
+func BuildUnauthorizedErrResult(errCode, errMessage, errDetail string) ErrorResult {
+	errList := []ErrorInfo{BuildErrorInfo(errCode, errMessage, errDetail)}
+
+	return ErrorResult{
+		ErrorList:  errList,
+		IsError:    false,
+		StatusCode: http.StatusUnauthorized,
+	}
+}
+
+// BuildTooManyRequestsErrResult used to build ErrorResult with too many requests code
+func BuildTooManyRequestsErrResult(errCode, errMessage, errDetail string) ErrorResult {
+	errList := []ErrorInfo{BuildErrorInfo(errCode, errMessage, errDetail)}
+
+	return ErrorResult{
+		ErrorList:  errList,
+		IsError:    false,
+		StatusCode: http.StatusTooManyRequests,
+	}
+}
+
 // BuildPanicErrResult used to build ErrorResult with internal server error code
 func BuildPanicErrResult(panicMethod string) *ErrorResult {
 	errRes := BuildInternalServerErrResult(constant.UnexpectedErrorCode, fmt.Sprintf(constant.UnexpectedErrorMessage, panicMethod), "")
@@ -0,0 +1,220 @@
+package duplicate
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/duplicateconfig"
+	"github.com/chazool/serendib_asia_service/pkg/geo"
+)
+
+// Reasons reported for the signals two listings share
+const (
+	ReasonAddress  = "address"
+	ReasonLocation = "location"
+	ReasonTitle    = "title"
+	ReasonPrice    = "price"
+	ReasonImages   = "images"
+)
+
+const (
+	// MaxScore caps the similarity score of two listings
+	MaxScore = 100
+
+	// weights of the signals
+	addressWeight  = 40
+	locationWeight = 30
+	titleWeight    = 20
+	priceWeight    = 10
+	imagesWeight   = 60
+)
+
+// addressAbbreviations expands the abbreviations common in local addresses, so both spellings compare equal
+var addressAbbreviations = map[string]string{
+	"rd":   "road",
+	"st":   "street",
+	"mw":   "mawatha",
+	"mwt":  "mawatha",
+	"ln":   "lane",
+	"ave":  "avenue",
+	"av":   "avenue",
+	"pl":   "place",
+	"gdn":  "gardens",
+	"gdns": "gardens",
+	"n":    "north",
+	"s":    "south",
+	"e":    "east",
+	"w":    "west",
+}
+
+// addressNoise are the address words that carry no meaning of their own
+var addressNoise = map[string]bool{
+	"no":  true,
+	"the": true,
+	"of":  true,
+}
+
+// titleNoise are the title words too common in listings to tell two apart
+var titleNoise = map[string]bool{
+	"a": true, "an": true, "the": true, "in": true, "at": true, "for": true, "with": true,
+	"and": true, "of": true, "on": true, "to": true, "near": true,
+	"sale": true, "rent": true, "house": true, "property": true,
+}
+
+// Listing is a listing compared for duplicates
+type Listing struct {
+	ID        uint
+	Title     string
+	Address   string
+	City      string
+	Latitude  float64
+	Longitude float64
+	Price     float64
+	Images    []string
+}
+
+// Result is the similarity of two listings
+type Result struct {
+	Score   int
+	Reasons []string
+}
+
+// Detector compares listings using the duplicate detection config
+type Detector struct {
+	_      struct{}
+	config duplicateconfig.Config
+}
+
+// NewDetector creates a detector from the duplicate detection config
+func NewDetector(config duplicateconfig.Config) *Detector {
+	return &Detector{config: config}
+}
+
+// Compare scores how likely two listings are the same property. Every shared signal adds its
+// weight to the score, which is capped at MaxScore.
+func (detector *Detector) Compare(listing, candidate Listing) Result {
+	var result Result
+	add := func(reason string, weight int) {
+		result.Reasons = append(result.Reasons, reason)
+		result.Score += weight
+	}
+
+	if sameAddress(listing, candidate) {
+		add(ReasonAddress, addressWeight)
+	}
+	if detector.sameLocation(listing, candidate) {
+		add(ReasonLocation, locationWeight)
+	}
+	if detector.similarTitle(listing.Title, candidate.Title) {
+		add(ReasonTitle, titleWeight)
+	}
+	if detector.samePrice(listing.Price, candidate.Price) {
+		add(ReasonPrice, priceWeight)
+	}
+	if sharesImage(listing.Images, candidate.Images) {
+		add(ReasonImages, imagesWeight)
+	}
+
+	result.Score = min(result.Score, MaxScore)
+	return result
+}
+
+// IsDuplicate reports whether the result is similar enough to flag the listings as duplicates
+func (detector *Detector) IsDuplicate(result Result) bool {
+	return detector.config.MinScore > 0 && result.Score >= detector.config.MinScore
+}
+
+// NormalizeAddress lowers an address to its words, with punctuation dropped and abbreviations expanded,
+// so "No. 12, Galle Rd." and "12 galle road" compare equal
+func NormalizeAddress(address string) string {
+	parts := words(address)
+	normalized := make([]string, 0, len(parts))
+	for _, word := range parts {
+		if addressNoise[word] {
+			continue
+		}
+		if expanded, ok := addressAbbreviations[word]; ok {
+			word = expanded
+		}
+		normalized = append(normalized, word)
+	}
+
+	return strings.Join(normalized, " ")
+}
+
+// sameAddress reports whether both listings have the same normalised address in the same city
+func sameAddress(listing, candidate Listing) bool {
+	address := NormalizeAddress(listing.Address)
+	return address != "" && address == NormalizeAddress(candidate.Address) &&
+		strings.EqualFold(strings.TrimSpace(listing.City), strings.TrimSpace(candidate.City))
+}
+
+// sameLocation reports whether both listings are pinned within the radius of each other.
+// A listing pinned at 0,0 has no location.
+func (detector *Detector) sameLocation(listing, candidate Listing) bool {
+	if (listing.Latitude == 0 && listing.Longitude == 0) || (candidate.Latitude == 0 && candidate.Longitude == 0) {
+		return false
+	}
+
+	distance := geo.DistanceKm(geo.Point{Lat: listing.Latitude, Lng: listing.Longitude}, geo.Point{Lat: candidate.Latitude, Lng: candidate.Longitude})
+	return distance*1000 <= detector.config.RadiusMeters
+}
+
+// similarTitle reports whether the titles share enough of their meaningful words, measured as the
+// Jaccard similarity of the two word sets
+func (detector *Detector) similarTitle(title, other string) bool {
+	a, b := titleWords(title), titleWords(other)
+	if len(a) == 0 || len(b) == 0 {
+		return false
+	}
+
+	shared := 0
+	for word := range a {
+		if b[word] {
+			shared++
+		}
+	}
+
+	return float64(shared)/float64(len(a)+len(b)-shared) >= detector.config.TitleSimilarity
+}
+
+// samePrice reports whether the prices are within the tolerance of the higher one
+func (detector *Detector) samePrice(price, other float64) bool {
+	if price <= 0 || other <= 0 {
+		return false
+	}
+	return math.Abs(price-other) <= math.Max(price, other)*detector.config.PriceTolerance
+}
+
+// sharesImage reports whether any image URL is used by both listings
+func sharesImage(images, others []string) bool {
+	urls := make(map[string]bool, len(images))
+	for _, url := range images {
+		urls[url] = true
+	}
+	for _, url := range others {
+		if urls[url] {
+			return true
+		}
+	}
+	return false
+}
+
+// titleWords returns the set of meaningful words of a title
+func titleWords(title string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range words(title) {
+		if !titleNoise[word] {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// words splits text into lower case words of letters and digits
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,121 @@
+package duplicate
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/duplicateconfig"
+)
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{address: "No. 12, Galle Rd.", want: "12 galle road"},
+		{address: "12 galle road", want: "12 galle road"},
+		{address: "45/2 Temple Ln", want: "45 2 temple lane"},
+		{address: "Flat 3, Baseline Mw, Colombo 09", want: "flat 3 baseline mawatha colombo 09"},
+		{address: "The Gdns, N", want: "gardens north"},
+		{address: "  ", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			if got := NormalizeAddress(tt.address); got != tt.want {
+				t.Errorf("NormalizeAddress(%q) = %q, want %q", tt.address, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	detector := NewDetector(duplicateconfig.Config{RadiusMeters: 50, TitleSimilarity: 0.6, PriceTolerance: 0.1, MinScore: 60})
+	listing := Listing{
+		Title:     "Two bedroom apartment in Colombo",
+		Address:   "No. 12, Galle Rd.",
+		City:      "Colombo",
+		Latitude:  6.9271,
+		Longitude: 79.8612,
+		Price:     25000000,
+		Images:    []string{"https://cdn.example.com/front.jpg"},
+	}
+	unrelated := func() Listing {
+		return Listing{
+			Title:     "Beach villa in Galle",
+			Address:   "5 Lighthouse Street",
+			City:      "Galle",
+			Latitude:  6.0329,
+			Longitude: 80.2168,
+			Price:     90000000,
+			Images:    []string{"https://cdn.example.com/villa.jpg"},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		edit        func(candidate *Listing)
+		wantReasons []string
+		wantScore   int
+	}{
+		{name: "nothing shared", edit: func(*Listing) {}},
+		{name: "address spelled differently", edit: func(candidate *Listing) {
+			candidate.Address, candidate.City = "12 galle road", " colombo"
+		}, wantReasons: []string{ReasonAddress}, wantScore: 40},
+		{name: "address in another city", edit: func(candidate *Listing) { candidate.Address = "12 Galle Road" }},
+		{name: "pinned within the radius", edit: func(candidate *Listing) {
+			candidate.Latitude, candidate.Longitude = 6.9273, 79.8612
+		}, wantReasons: []string{ReasonLocation}, wantScore: 30},
+		{name: "without a location", edit: func(candidate *Listing) {
+			candidate.Latitude, candidate.Longitude = 0, 0
+		}},
+		{name: "similar title", edit: func(candidate *Listing) {
+			candidate.Title = "Two bedroom apartment for rent in Colombo"
+		}, wantReasons: []string{ReasonTitle}, wantScore: 20},
+		{name: "price within the tolerance", edit: func(candidate *Listing) { candidate.Price = 24000000 },
+			wantReasons: []string{ReasonPrice}, wantScore: 10},
+		{name: "shared image", edit: func(candidate *Listing) {
+			candidate.Images = append(candidate.Images, "https://cdn.example.com/front.jpg")
+		}, wantReasons: []string{ReasonImages}, wantScore: 60},
+		{name: "everything shared", edit: func(candidate *Listing) {
+			*candidate = listing
+		}, wantReasons: []string{ReasonAddress, ReasonLocation, ReasonTitle, ReasonPrice, ReasonImages}, wantScore: MaxScore},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			candidate := unrelated()
+			tt.edit(&candidate)
+
+			result := detector.Compare(listing, candidate)
+			if !slices.Equal(result.Reasons, tt.wantReasons) {
+				t.Errorf("Compare() reasons = %v, want %v", result.Reasons, tt.wantReasons)
+			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Compare() score = %d, want %d", result.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestIsDuplicate(t *testing.T) {
+	tests := []struct {
+		name     string
+		minScore int
+		score    int
+		want     bool
+	}{
+		{name: "at the minimum", minScore: 60, score: 60, want: true},
+		{name: "below the minimum", minScore: 60, score: 59},
+		{name: "without a minimum", minScore: 0, score: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewDetector(duplicateconfig.Config{MinScore: tt.minScore})
+			if got := detector.IsDuplicate(Result{Score: tt.score}); got != tt.want {
+				t.Errorf("IsDuplicate(%d) = %v, want %v", tt.score, got, tt.want)
+			}
+		})
+	}
+}
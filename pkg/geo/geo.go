@@ -0,0 +1,158 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidPolygon is returned for polygons that cannot be parsed or have out of range vertices
+var ErrInvalidPolygon = errors.New("invalid polygon")
+
+const (
+	// EarthRadiusKm is the mean earth radius used for Haversine distances
+	EarthRadiusKm = 6371.0
+	// MinPolygonPoints and MaxPolygonPoints bound the vertices of a search polygon
+	MinPolygonPoints = 3
+	MaxPolygonPoints = 100
+
+	pointSeparator      = ";"
+	coordinateSeparator = ","
+	// kmPerDegreeLatitude is the length of one degree of latitude
+	kmPerDegreeLatitude = math.Pi * EarthRadiusKm / 180
+)
+
+// Point is a WGS84 coordinate
+type Point struct {
+	Lat float64
+	Lng float64
+}
+
+// Bounds is a latitude/longitude rectangle. MinLng is greater than MaxLng when the
+// rectangle crosses the antimeridian.
+type Bounds struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+// ValidLatitude reports whether lat is within -90 and 90
+func ValidLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+// ValidLongitude reports whether lng is within -180 and 180
+func ValidLongitude(lng float64) bool {
+	return lng >= -180 && lng <= 180
+}
+
+// ParsePolygon parses "lat,lng;lat,lng;..." into its vertices. A closing vertex equal
+// to the first one is dropped, so both open and closed rings are accepted.
+func ParsePolygon(value string) ([]Point, error) {
+	parts := strings.Split(strings.TrimSpace(value), pointSeparator)
+	points := make([]Point, 0, len(parts))
+	for _, part := range parts {
+		coordinates := strings.Split(part, coordinateSeparator)
+		if len(coordinates) != 2 {
+			return nil, ErrInvalidPolygon
+		}
+		lat, err := strconv.ParseFloat(strings.TrimSpace(coordinates[0]), 64)
+		if err != nil || !ValidLatitude(lat) {
+			return nil, ErrInvalidPolygon
+		}
+		lng, err := strconv.ParseFloat(strings.TrimSpace(coordinates[1]), 64)
+		if err != nil || !ValidLongitude(lng) {
+			return nil, ErrInvalidPolygon
+		}
+		points = append(points, Point{Lat: lat, Lng: lng})
+	}
+
+	if len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	if len(points) < MinPolygonPoints || len(points) > MaxPolygonPoints {
+		return nil, ErrInvalidPolygon
+	}
+
+	return points, nil
+}
+
+// PolygonWKT renders the vertices as a closed WKT polygon in lng/lat order
+func PolygonWKT(points []Point) string {
+	vertices := make([]string, 0, len(points)+1)
+	for _, p := range append(points, points[0]) {
+		vertices = append(vertices, fmt.Sprintf("%s %s", strconv.FormatFloat(p.Lng, 'f', -1, 64), strconv.FormatFloat(p.Lat, 'f', -1, 64)))
+	}
+	return fmt.Sprintf("POLYGON((%s))", strings.Join(vertices, ", "))
+}
+
+// PolygonBounds returns the rectangle enclosing the vertices
+func PolygonBounds(points []Point) Bounds {
+	bounds := Bounds{MinLat: points[0].Lat, MinLng: points[0].Lng, MaxLat: points[0].Lat, MaxLng: points[0].Lng}
+	for _, p := range points[1:] {
+		bounds.MinLat = math.Min(bounds.MinLat, p.Lat)
+		bounds.MaxLat = math.Max(bounds.MaxLat, p.Lat)
+		bounds.MinLng = math.Min(bounds.MinLng, p.Lng)
+		bounds.MaxLng = math.Max(bounds.MaxLng, p.Lng)
+	}
+	return bounds
+}
+
+// RadiusBounds returns a rectangle enclosing the circle of radiusKm around center,
+// used to narrow a Haversine search down to an indexable range first.
+func RadiusBounds(center Point, radiusKm float64) Bounds {
+	latDelta := radiusKm / kmPerDegreeLatitude
+	bounds := Bounds{
+		MinLat: math.Max(center.Lat-latDelta, -90),
+		MaxLat: math.Min(center.Lat+latDelta, 90),
+		MinLng: -180,
+		MaxLng: 180,
+	}
+
+	// Near the poles the circle covers every longitude
+	cosLat := math.Cos(center.Lat * math.Pi / 180)
+	if bounds.MinLat > -90 && bounds.MaxLat < 90 && cosLat > 0 {
+		lngDelta := latDelta / cosLat
+		if lngDelta < 180 {
+			bounds.MinLng = normalizeLongitude(center.Lng - lngDelta)
+			bounds.MaxLng = normalizeLongitude(center.Lng + lngDelta)
+		}
+	}
+
+	return bounds
+}
+
+// DistanceKm returns the great-circle distance between two points, using the Haversine formula
+func DistanceKm(a, b Point) float64 {
+	const radians = math.Pi / 180
+	dLat := (b.Lat - a.Lat) * radians
+	dLng := (b.Lng - a.Lng) * radians
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(a.Lat*radians)*math.Cos(b.Lat*radians)*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// Contains reports whether p lies inside the polygon, using ray casting
+func Contains(polygon []Point, p Point) bool {
+	inside := false
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		a, b := polygon[i], polygon[j]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lng < (b.Lng-a.Lng)*(p.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lng {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func normalizeLongitude(lng float64) float64 {
+	switch {
+	case lng < -180:
+		return lng + 360
+	case lng > 180:
+		return lng - 360
+	}
+	return lng
+}
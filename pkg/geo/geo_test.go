@@ -0,0 +1,160 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// kmPerDegree is the length of one degree along a great circle
+const kmPerDegree = math.Pi * EarthRadiusKm / 180
+
+func approxEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b Point
+		want float64
+	}{
+		{name: "same point", a: Point{Lat: 6.9271, Lng: 79.8612}, b: Point{Lat: 6.9271, Lng: 79.8612}, want: 0},
+		{name: "colombo to kandy", a: Point{Lat: 6.9271, Lng: 79.8612}, b: Point{Lat: 7.2906, Lng: 80.6337}, want: 94.34},
+		{name: "london to paris", a: Point{Lat: 51.5074, Lng: -0.1278}, b: Point{Lat: 48.8566, Lng: 2.3522}, want: 343.56},
+		{name: "one degree across the antimeridian", a: Point{Lat: 0, Lng: 179.5}, b: Point{Lat: 0, Lng: -179.5}, want: kmPerDegree},
+		{name: "antipodes", a: Point{Lat: 0, Lng: 0}, b: Point{Lat: 0, Lng: 180}, want: math.Pi * EarthRadiusKm},
+		{name: "pole to pole", a: Point{Lat: 90, Lng: 0}, b: Point{Lat: -90, Lng: 0}, want: math.Pi * EarthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DistanceKm(tt.a, tt.b); !approxEqual(got, tt.want, 0.01) {
+				t.Errorf("DistanceKm(%v, %v) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+			}
+			if got, back := DistanceKm(tt.a, tt.b), DistanceKm(tt.b, tt.a); !approxEqual(got, back, 1e-9) {
+				t.Errorf("DistanceKm is not symmetric: %.6f and %.6f", got, back)
+			}
+		})
+	}
+}
+
+func TestRadiusBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		center   Point
+		radiusKm float64
+		want     Bounds
+	}{
+		{name: "equator", center: Point{Lat: 0, Lng: 0}, radiusKm: kmPerDegree, want: Bounds{MinLat: -1, MinLng: -1, MaxLat: 1, MaxLng: 1}},
+		{name: "longitude widens away from the equator", center: Point{Lat: 60, Lng: 10}, radiusKm: kmPerDegree, want: Bounds{MinLat: 59, MinLng: 8, MaxLat: 61, MaxLng: 12}},
+		{name: "crosses the antimeridian", center: Point{Lat: 0, Lng: 179.5}, radiusKm: kmPerDegree, want: Bounds{MinLat: -1, MinLng: 178.5, MaxLat: 1, MaxLng: -179.5}},
+		{name: "covers the pole", center: Point{Lat: 89.5, Lng: 10}, radiusKm: kmPerDegree, want: Bounds{MinLat: 88.5, MinLng: -180, MaxLat: 90, MaxLng: 180}},
+		{name: "wider than half the globe", center: Point{Lat: 0, Lng: 0}, radiusKm: 100 * kmPerDegree, want: Bounds{MinLat: -90, MinLng: -180, MaxLat: 90, MaxLng: 180}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RadiusBounds(tt.center, tt.radiusKm)
+			if !approxEqual(got.MinLat, tt.want.MinLat, 1e-9) || !approxEqual(got.MaxLat, tt.want.MaxLat, 1e-9) ||
+				!approxEqual(got.MinLng, tt.want.MinLng, 1e-9) || !approxEqual(got.MaxLng, tt.want.MaxLng, 1e-9) {
+				t.Errorf("RadiusBounds(%v, %.2f) = %+v, want %+v", tt.center, tt.radiusKm, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePolygon(t *testing.T) {
+	triangle := []Point{{Lat: 6.9, Lng: 79.8}, {Lat: 7.0, Lng: 79.9}, {Lat: 6.8, Lng: 80.0}}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    []Point
+		wantErr bool
+	}{
+		{name: "open ring", value: "6.9,79.8;7.0,79.9;6.8,80.0", want: triangle},
+		{name: "closed ring", value: "6.9,79.8;7.0,79.9;6.8,80.0;6.9,79.8", want: triangle},
+		{name: "spaces", value: " 6.9, 79.8; 7.0 ,79.9;6.8,80.0 ", want: triangle},
+		{name: "empty", value: "", wantErr: true},
+		{name: "two points", value: "6.9,79.8;7.0,79.9", wantErr: true},
+		{name: "closed ring of two points", value: "6.9,79.8;7.0,79.9;6.9,79.8", wantErr: true},
+		{name: "missing longitude", value: "6.9;7.0,79.9;6.8,80.0", wantErr: true},
+		{name: "extra coordinate", value: "6.9,79.8,1;7.0,79.9;6.8,80.0", wantErr: true},
+		{name: "not a number", value: "north,79.8;7.0,79.9;6.8,80.0", wantErr: true},
+		{name: "latitude out of range", value: "91,79.8;7.0,79.9;6.8,80.0", wantErr: true},
+		{name: "longitude out of range", value: "6.9,181;7.0,79.9;6.8,80.0", wantErr: true},
+		{name: "trailing separator", value: "6.9,79.8;7.0,79.9;6.8,80.0;", wantErr: true},
+		{name: "too many points", value: strings.Repeat("6.9,79.8;", MaxPolygonPoints) + "7.0,79.9", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolygon(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPolygon) {
+					t.Errorf("ParsePolygon(%q) error = %v, want %v", tt.value, err, ErrInvalidPolygon)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParsePolygon(%q) error = %v", tt.value, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParsePolygon(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParsePolygon(%q)[%d] = %v, want %v", tt.value, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPolygonWKT(t *testing.T) {
+	points := []Point{{Lat: 6.9, Lng: 79.8}, {Lat: 7, Lng: 79.9}, {Lat: 6.8, Lng: 80}}
+
+	want := "POLYGON((79.8 6.9, 79.9 7, 80 6.8, 79.8 6.9))"
+	if got := PolygonWKT(points); got != want {
+		t.Errorf("PolygonWKT() = %q, want %q", got, want)
+	}
+	if len(points) != 3 {
+		t.Errorf("PolygonWKT() changed its input to %v", points)
+	}
+}
+
+func TestPolygonBounds(t *testing.T) {
+	points := []Point{{Lat: 6.9, Lng: 79.8}, {Lat: 7.1, Lng: 79.9}, {Lat: 6.8, Lng: 80.2}}
+
+	want := Bounds{MinLat: 6.8, MinLng: 79.8, MaxLat: 7.1, MaxLng: 80.2}
+	if got := PolygonBounds(points); got != want {
+		t.Errorf("PolygonBounds() = %+v, want %+v", got, want)
+	}
+}
+
+func TestContains(t *testing.T) {
+	// an L shape, so the notch at the top right lies outside
+	lShape := []Point{{Lat: 0, Lng: 0}, {Lat: 0, Lng: 2}, {Lat: 1, Lng: 2}, {Lat: 1, Lng: 1}, {Lat: 2, Lng: 1}, {Lat: 2, Lng: 0}}
+
+	tests := []struct {
+		name  string
+		point Point
+		want  bool
+	}{
+		{name: "inside the foot", point: Point{Lat: 0.5, Lng: 1.5}, want: true},
+		{name: "inside the stem", point: Point{Lat: 1.5, Lng: 0.5}, want: true},
+		{name: "in the notch", point: Point{Lat: 1.5, Lng: 1.5}, want: false},
+		{name: "left of the polygon", point: Point{Lat: 0.5, Lng: -0.5}, want: false},
+		{name: "above the polygon", point: Point{Lat: 2.5, Lng: 0.5}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Contains(lShape, tt.point); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.point, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/config/mailconfig"
+)
+
+// ErrUnknownMailDriver is returned when MAIL_DRIVER names no known driver
+var ErrUnknownMailDriver = errors.New("unknown mail driver")
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email messages
+type Mailer interface {
+	Send(message Message) error
+}
+
+// NewMailer creates the mailer selected by the MAIL_DRIVER config
+func NewMailer() (Mailer, error) {
+	mailConfig := config.GetConfig().MailConfig
+
+	switch mailConfig.Driver {
+	case mailconfig.DriverSMTP:
+		return NewSMTPMailer(mailConfig), nil
+	case mailconfig.DriverOutbox:
+		return NewOutboxMailer(mailConfig.OutboxDir, mailConfig.From), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownMailDriver, mailConfig.Driver)
+	}
+}
@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// headerLineBreaks removes line breaks, so a value cannot end its header and start another
+var headerLineBreaks = strings.NewReplacer("\r", "", "\n", "")
+
+// bytes renders the message in RFC 5322 format
+func (message Message) bytes(from string) []byte {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "From: %s\r\n", headerLineBreaks.Replace(from))
+	fmt.Fprintf(&builder, "To: %s\r\n", headerLineBreaks.Replace(message.To))
+	fmt.Fprintf(&builder, "Subject: %s\r\n", headerLineBreaks.Replace(message.Subject))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(message.Body)
+
+	return []byte(builder.String())
+}
+
+// EmailVerificationMessage builds the email carrying an email verification link
+func EmailVerificationMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Verify your Serendib Asia email address",
+		Body: fmt.Sprintf("Welcome to Serendib Asia.\r\n\r\n"+
+			"Please confirm your email address with the link below:\r\n%s\r\n\r\n"+
+			"If you did not create an account, you can ignore this email.\r\n", link),
+	}
+}
+
+// AccountUnlockMessage builds the email sent when an account is locked after failed logins
+func AccountUnlockMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Your Serendib Asia account has been locked",
+		Body: fmt.Sprintf("We locked your account after several failed sign in attempts.\r\n\r\n"+
+			"If this was you, use the link below to unlock it right away:\r\n%s\r\n\r\n"+
+			"Otherwise the account unlocks by itself shortly, and you may want to reset your password.\r\n", link),
+	}
+}
+
+// PasswordResetMessage builds the email carrying a password reset link
+func PasswordResetMessage(to, link string) Message {
+	return Message{
+		To:      to,
+		Subject: "Reset your Serendib Asia password",
+		Body: fmt.Sprintf("We received a request to reset your password.\r\n\r\n"+
+			"Use the link below to choose a new password:\r\n%s\r\n\r\n"+
+			"If you did not request this, you can ignore this email.\r\n", link),
+	}
+}
+
+// OrganizationInvitationMessage builds the email inviting someone to join an organization
+func OrganizationInvitationMessage(to, organizationName, role, link string) Message {
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("You have been invited to join %q on Serendib Asia", organizationName),
+		Body: fmt.Sprintf("You have been invited to join %s on Serendib Asia as %s.\r\n\r\n"+
+			"Sign in with this email address and accept the invitation with the link below:\r\n%s\r\n\r\n"+
+			"If you were not expecting this invitation, you can ignore this email.\r\n", organizationName, role, link),
+	}
+}
+
+// ListingRejectedMessage builds the email telling an owner why a listing was rejected by moderation
+func ListingRejectedMessage(to, title, reason, note, link string) Message {
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("Your listing %q was not approved", title),
+		Body: fmt.Sprintf("Your Serendib Asia listing %q was not approved by our moderators.\r\n\r\n"+
+			"Reason: %s\r\n%s\r\n\r\n"+
+			"You can correct the listing and submit it again from your listings page:\r\n%s\r\n", title, reason, note, link),
+	}
+}
+
+// ListingExpiryReminderMessage builds the email reminding an owner to renew a listing about to expire
+func ListingExpiryReminderMessage(to, title string, expiresAt time.Time, link string) Message {
+	return Message{
+		To:      to,
+		Subject: fmt.Sprintf("Your listing %q is about to expire", title),
+		Body: fmt.Sprintf("Your Serendib Asia listing %q expires on %s.\r\n\r\n"+
+			"Renew it from your listings page to keep it visible:\r\n%s\r\n\r\n"+
+			"Once expired, the listing is hidden until you renew it.\r\n", title, expiresAt.Format("2 January 2006"), link),
+	}
+}
@@ -0,0 +1,27 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessageBytesKeepsHeadersOnOneLine(t *testing.T) {
+	message := OrganizationInvitationMessage("agent@example.com\r\nBcc: victim@example.com",
+		"Acme\r\nBcc: everyone@example.com", "agent", "https://serendib.example.com/invitations?token=abc")
+
+	rendered := string(message.bytes("no-reply@example.com"))
+	headers, _, found := strings.Cut(rendered, "\r\n\r\n")
+	if !found {
+		t.Fatalf("bytes() has no blank line between headers and body:\n%s", rendered)
+	}
+
+	lines := strings.Split(headers, "\r\n")
+	if len(lines) != 5 {
+		t.Fatalf("bytes() rendered %d header lines, want 5:\n%s", len(lines), headers)
+	}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "Bcc:") {
+			t.Errorf("bytes() rendered an injected header %q", line)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// OutboxMailer writes every message to a file instead of sending it. It is meant
+// for local development, where the outbox directory can be inspected for links.
+type OutboxMailer struct {
+	_    struct{}
+	dir  string
+	from string
+}
+
+// NewOutboxMailer creates a new instance of OutboxMailer
+func NewOutboxMailer(dir, from string) *OutboxMailer {
+	return &OutboxMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+// Send writes the message to <dir>/<timestamp>-<recipient>.eml
+func (mailer *OutboxMailer) Send(message Message) error {
+	if err := os.MkdirAll(mailer.dir, 0o750); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), filepath.Base(message.To))
+
+	return os.WriteFile(filepath.Join(mailer.dir, name), message.bytes(mailer.from), 0o600)
+}
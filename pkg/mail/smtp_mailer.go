@@ -0,0 +1,39 @@
+package mail
+
+import (
+	"net"
+	"net/smtp"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/mailconfig"
+)
+
+// SMTPMailer delivers messages through an SMTP relay
+type SMTPMailer struct {
+	_        struct{}
+	address  string
+	host     string
+	from     string
+	username string
+	password string
+}
+
+// NewSMTPMailer creates a new instance of SMTPMailer
+func NewSMTPMailer(mailConfig mailconfig.Config) *SMTPMailer {
+	return &SMTPMailer{
+		address:  net.JoinHostPort(mailConfig.SMTPHost, mailConfig.SMTPPort),
+		host:     mailConfig.SMTPHost,
+		from:     mailConfig.From,
+		username: mailConfig.SMTPUsername,
+		password: mailConfig.SMTPPassword,
+	}
+}
+
+// Send delivers the message, authenticating only when a username is configured
+func (mailer *SMTPMailer) Send(message Message) error {
+	var auth smtp.Auth
+	if mailer.username != "" {
+		auth = smtp.PlainAuth("", mailer.username, mailer.password, mailer.host)
+	}
+
+	return smtp.SendMail(mailer.address, auth, mailer.from, []string{message.To}, message.bytes(mailer.from))
+}
@@ -0,0 +1,114 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+// ErrInvalidCursor is returned for page tokens that cannot be decoded or were issued for another ordering
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const (
+	// DirectionNext and DirectionPrev tell whether a cursor pages forward or backward from its row
+	DirectionNext = "next"
+	DirectionPrev = "prev"
+	// TimeLayout is how time sort values are written into cursors, understood by both PostgreSQL and MySQL
+	TimeLayout = "2006-01-02 15:04:05.999999"
+	// CursorParam is the query parameter carrying the page token
+	CursorParam = "cursor"
+)
+
+// Cursor is the decoded form of a page token: the position of the row a page continues from
+type Cursor struct {
+	Direction string `json:"d"`
+	Sort      string `json:"s"`
+	Value     any    `json:"v,omitempty"`
+	ID        uint   `json:"i"`
+}
+
+// Page describes where a page sits in a list and is returned alongside its items
+type Page struct {
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int64 `json:"total,omitempty"`
+}
+
+// Links holds the URLs of the pages around the current one
+type Links struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// Size returns the page size to use for a requested one, falling back to the default when out of range
+func Size(pageSize int) int {
+	if pageSize < 1 || pageSize > constant.MaxPageSize {
+		return constant.DefaultPageSize
+	}
+	return pageSize
+}
+
+// Encode turns a cursor into an opaque, URL safe page token
+func Encode(cursor Cursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a page token issued for the given ordering. An empty token is the first page and returns nil.
+func Decode(token, sort string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort || (cursor.Direction != DirectionNext && cursor.Direction != DirectionPrev) {
+		return nil, ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
+
+// Trim takes the up to pageSize+1 rows a keyset query returned from cursor and builds the page:
+// the extra row only tells whether more rows follow, and rows read backward are put back in order.
+// key returns the sort value and id of a row.
+func Trim[T any](rows []T, cursor *Cursor, pageSize int, sort string, key func(T) (any, uint)) ([]T, Page) {
+	page := Page{PageSize: pageSize}
+
+	backward := cursor != nil && cursor.Direction == DirectionPrev
+	hasMore := len(rows) > pageSize
+	if hasMore {
+		rows = rows[:pageSize]
+	}
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+	if len(rows) == 0 {
+		return rows, page
+	}
+
+	// Going forward there is a previous page unless this is the first one; going
+	// backward there is always a next page, the one the cursor came from
+	if backward || hasMore {
+		value, id := key(rows[len(rows)-1])
+		page.NextCursor = Encode(Cursor{Direction: DirectionNext, Sort: sort, Value: value, ID: id})
+	}
+	if (!backward && cursor != nil) || (backward && hasMore) {
+		value, id := key(rows[0])
+		page.PrevCursor = Encode(Cursor{Direction: DirectionPrev, Sort: sort, Value: value, ID: id})
+	}
+
+	return rows, page
+}
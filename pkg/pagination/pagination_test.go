@@ -0,0 +1,146 @@
+package pagination
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+)
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		pageSize int
+		want     int
+	}{
+		{pageSize: 0, want: constant.DefaultPageSize},
+		{pageSize: -1, want: constant.DefaultPageSize},
+		{pageSize: 1, want: 1},
+		{pageSize: constant.MaxPageSize, want: constant.MaxPageSize},
+		{pageSize: constant.MaxPageSize + 1, want: constant.DefaultPageSize},
+	}
+
+	for _, tt := range tests {
+		if got := Size(tt.pageSize); got != tt.want {
+			t.Errorf("Size(%d) = %d, want %d", tt.pageSize, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor Cursor
+	}{
+		{name: "id only", cursor: Cursor{Direction: DirectionNext, Sort: "uploaded:asc", ID: 42}},
+		{name: "time value", cursor: Cursor{Direction: DirectionPrev, Sort: "date:desc", Value: "2025-03-01 10:15:00.123456", ID: 7}},
+		{name: "number value", cursor: Cursor{Direction: DirectionNext, Sort: "price:asc", Value: 25000000.5, ID: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(Encode(tt.cursor), tt.cursor.Sort)
+			if err != nil {
+				t.Fatalf("Decode(Encode()) error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.cursor) {
+				t.Errorf("Decode(Encode()) = %+v, want %+v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeEmptyToken(t *testing.T) {
+	cursor, err := Decode("", "date:desc")
+	if cursor != nil || err != nil {
+		t.Errorf("Decode(\"\") = %v, %v, want the first page", cursor, err)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "not a token!"},
+		{name: "not json", token: "bm90IGpzb24"},
+		{name: "other ordering", token: Encode(Cursor{Direction: DirectionNext, Sort: "price:asc", ID: 1})},
+		{name: "unknown direction", token: Encode(Cursor{Direction: "sideways", Sort: "date:desc", ID: 1})},
+		{name: "no direction", token: Encode(Cursor{Sort: "date:desc", ID: 1})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.token, "date:desc"); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) error = %v, want %v", tt.token, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+type row struct {
+	value string
+	id    uint
+}
+
+func rowKey(r row) (any, uint) {
+	return r.value, r.id
+}
+
+func rows(ids ...uint) []row {
+	result := make([]row, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, row{value: string(rune('a' + id)), id: id})
+	}
+	return result
+}
+
+func cursorAt(direction string, id uint) string {
+	return Encode(Cursor{Direction: direction, Sort: "name:asc", Value: string(rune('a' + id)), ID: id})
+}
+
+func TestTrim(t *testing.T) {
+	tests := []struct {
+		name     string
+		rows     []row
+		cursor   *Cursor
+		wantIDs  []uint
+		wantNext string
+		wantPrev string
+	}{
+		{name: "only page", rows: rows(1, 2), wantIDs: []uint{1, 2}},
+		{name: "first page", rows: rows(1, 2, 3), wantIDs: []uint{1, 2}, wantNext: cursorAt(DirectionNext, 2)},
+		{name: "middle page forward", rows: rows(3, 4, 5), cursor: &Cursor{Direction: DirectionNext, ID: 2},
+			wantIDs: []uint{3, 4}, wantNext: cursorAt(DirectionNext, 4), wantPrev: cursorAt(DirectionPrev, 3)},
+		{name: "last page forward", rows: rows(5), cursor: &Cursor{Direction: DirectionNext, ID: 4},
+			wantIDs: []uint{5}, wantPrev: cursorAt(DirectionPrev, 5)},
+		{name: "middle page backward", rows: rows(4, 3, 2), cursor: &Cursor{Direction: DirectionPrev, ID: 5},
+			wantIDs: []uint{3, 4}, wantNext: cursorAt(DirectionNext, 4), wantPrev: cursorAt(DirectionPrev, 3)},
+		{name: "first page backward", rows: rows(2, 1), cursor: &Cursor{Direction: DirectionPrev, ID: 3},
+			wantIDs: []uint{1, 2}, wantNext: cursorAt(DirectionNext, 2)},
+		{name: "past the end", rows: rows(), cursor: &Cursor{Direction: DirectionNext, ID: 9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, page := Trim(tt.rows, tt.cursor, 2, "name:asc", rowKey)
+
+			ids := make([]uint, 0, len(got))
+			for _, r := range got {
+				ids = append(ids, r.id)
+			}
+			if len(ids) != len(tt.wantIDs) || (len(ids) > 0 && !reflect.DeepEqual(ids, tt.wantIDs)) {
+				t.Errorf("Trim() rows = %v, want %v", ids, tt.wantIDs)
+			}
+			if page.PageSize != 2 {
+				t.Errorf("Trim() page size = %d, want 2", page.PageSize)
+			}
+			if page.NextCursor != tt.wantNext {
+				t.Errorf("Trim() next cursor = %q, want %q", page.NextCursor, tt.wantNext)
+			}
+			if page.PrevCursor != tt.wantPrev {
+				t.Errorf("Trim() prev cursor = %q, want %q", page.PrevCursor, tt.wantPrev)
+			}
+		})
+	}
+}
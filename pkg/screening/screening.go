@@ -0,0 +1,268 @@
+package screening
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/screeningconfig"
+)
+
+// Rule names reported for the built-in checks
+const (
+	RulePhone         = "contact_phone"
+	RuleEmail         = "contact_email"
+	RuleURL           = "contact_url"
+	RuleMessagingApp  = "contact_messaging_app"
+	RuleBannedWord    = "banned_word"
+	RuleExcessiveCaps = "excessive_caps"
+	RulePriceMismatch = "price_mismatch"
+	// rulePatternPrefix prefixes the name of a configured pattern
+	rulePatternPrefix = "pattern:"
+)
+
+// Fields of a listing that are screened
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+)
+
+const (
+	// MaxScore caps the risk score of a listing
+	MaxScore = 100
+
+	// weights of the built-in checks
+	phoneWeight         = 50
+	emailWeight         = 50
+	urlWeight           = 40
+	messagingAppWeight  = 20
+	excessiveCapsWeight = 20
+	priceMismatchWeight = 30
+
+	// capsMinLetters is the number of letters from which a field is checked for capitals
+	capsMinLetters = 20
+	// capsMaxShare is the share of capital letters above which a field shouts
+	capsMaxShare = 0.6
+	// maxExcerpt caps the length of the matched text reported to moderators
+	maxExcerpt = 60
+)
+
+var (
+	// phone numbers, international with a leading + or local with a leading 0
+	phonePattern = regexp.MustCompile(`\+\d{1,3}(?:[\s.-]?\d){7,12}|\b0\d(?:[\s.-]?\d){8}\b`)
+	// email addresses, including the "name at domain dot com" spellings
+	emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}|\b[a-z0-9._%+-]+\s*(?:\(at\)|\[at\]|\sat\s)\s*[a-z0-9-]+\s*(?:\(dot\)|\[dot\]|\sdot\s)\s*[a-z]{2,}\b`)
+	// links and bare domains
+	urlPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s,;]*[^\s.,;:!?)]|\b[a-z0-9-]+\.(?:com|lk|net|org|info|io|me)\b`)
+	// messaging apps used to take the conversation off the site
+	messagingAppPattern = regexp.MustCompile(`(?i)\b(?:whats\s?app|viber|telegram|imo|signal)\b`)
+	// amounts with a currency, a multiplier or the local "/=" suffix
+	amountPattern = regexp.MustCompile(`(?i)(?:rs\.?|lkr|usd|\$)\s*(\d[\d,]*(?:\.\d+)?)\s*(million|mn|lakhs?|k)?\b|(\d[\d,]*(?:\.\d+)?)\s*(million|mn|lakhs?)\b|(\d[\d,]*)\s*/=`)
+)
+
+// amountMultipliers scales the amounts written with a multiplier
+var amountMultipliers = map[string]float64{
+	"million": 1_000_000,
+	"mn":      1_000_000,
+	"lakh":    100_000,
+	"lakhs":   100_000,
+	"k":       1_000,
+}
+
+// Listing is the text and price of a listing to screen
+type Listing struct {
+	Title       string
+	Description string
+	Price       float64
+}
+
+// Match is a rule that matched the listing
+type Match struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field"`
+	Excerpt string `json:"excerpt"`
+	Weight  int    `json:"weight"`
+}
+
+// Result is the outcome of screening a listing
+type Result struct {
+	Score   int
+	Matches []Match
+}
+
+// pattern is a configured regular expression rule
+type pattern struct {
+	name       string
+	expression *regexp.Regexp
+}
+
+// Screener screens listing text against the built-in and configured rules
+type Screener struct {
+	_              struct{}
+	bannedWords    *regexp.Regexp
+	wordWeight     int
+	patterns       []pattern
+	patternWeight  int
+	priceTolerance float64
+}
+
+// NewScreener creates a screener from the content screening config
+func NewScreener(config screeningconfig.Config) (*Screener, error) {
+	screener := &Screener{
+		wordWeight:     config.WordWeight,
+		patternWeight:  config.PatternWeight,
+		priceTolerance: config.PriceTolerance,
+	}
+
+	if len(config.BannedWords) > 0 {
+		words := make([]string, len(config.BannedWords))
+		for i, word := range config.BannedWords {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		screener.bannedWords = regexp.MustCompile(`(?i)\b(?:` + strings.Join(words, "|") + `)\b`)
+	}
+
+	names := make([]string, 0, len(config.Patterns))
+	for name := range config.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		expression, err := regexp.Compile(config.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("screening pattern %q: %w", name, err)
+		}
+		screener.patterns = append(screener.patterns, pattern{name: rulePatternPrefix + name, expression: expression})
+	}
+
+	return screener, nil
+}
+
+// Screen runs every rule against the title and description of the listing. Each rule adds
+// its weight to the score once, however often it matches; the score is capped at MaxScore.
+func (screener *Screener) Screen(listing Listing) Result {
+	var result Result
+	scored := make(map[string]bool)
+
+	add := func(rule, field, excerpt string, weight int) {
+		result.Matches = append(result.Matches, Match{Rule: rule, Field: field, Excerpt: truncate(excerpt), Weight: weight})
+		if !scored[rule] {
+			scored[rule] = true
+			result.Score += weight
+		}
+	}
+
+	fields := []struct{ name, text string }{
+		{FieldTitle, listing.Title},
+		{FieldDescription, listing.Description},
+	}
+	for _, field := range fields {
+		matchPattern(add, RulePhone, field.name, field.text, phonePattern, phoneWeight)
+		matchPattern(add, RuleEmail, field.name, field.text, emailPattern, emailWeight)
+		matchPattern(add, RuleURL, field.name, field.text, urlPattern, urlWeight)
+		matchPattern(add, RuleMessagingApp, field.name, field.text, messagingAppPattern, messagingAppWeight)
+		if screener.bannedWords != nil {
+			matchPattern(add, RuleBannedWord, field.name, field.text, screener.bannedWords, screener.wordWeight)
+		}
+		for _, pattern := range screener.patterns {
+			matchPattern(add, pattern.name, field.name, field.text, pattern.expression, screener.patternWeight)
+		}
+		if shouts(field.text) {
+			add(RuleExcessiveCaps, field.name, field.text, excessiveCapsWeight)
+		}
+	}
+
+	if amount, found := screener.mismatchedAmount(listing); found {
+		add(RulePriceMismatch, FieldDescription, amount, priceMismatchWeight)
+	}
+
+	result.Score = min(result.Score, MaxScore)
+	return result
+}
+
+// matchPattern reports every distinct match of the expression in the text
+func matchPattern(add func(rule, field, excerpt string, weight int), rule, field, text string, expression *regexp.Regexp, weight int) {
+	seen := make(map[string]bool)
+	for _, match := range expression.FindAllString(text, -1) {
+		if seen[match] {
+			continue
+		}
+		seen[match] = true
+		add(rule, field, match, weight)
+	}
+}
+
+// mismatchedAmount returns an amount from the description when the description names amounts
+// and none of them is within the tolerance of the listing price
+func (screener *Screener) mismatchedAmount(listing Listing) (string, bool) {
+	if listing.Price <= 0 {
+		return "", false
+	}
+
+	var mismatched string
+	for _, groups := range amountPattern.FindAllStringSubmatch(listing.Description, -1) {
+		amount, ok := parseAmount(groups)
+		if !ok {
+			continue
+		}
+		if math.Abs(amount-listing.Price) <= listing.Price*screener.priceTolerance {
+			return "", false
+		}
+		if mismatched == "" {
+			mismatched = groups[0]
+		}
+	}
+
+	return mismatched, mismatched != ""
+}
+
+// parseAmount reads the amount out of the groups of an amountPattern match
+func parseAmount(groups []string) (float64, bool) {
+	for i := 1; i < len(groups); i++ {
+		if groups[i] == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(groups[i], ",", ""), 64)
+		if err != nil {
+			return 0, false
+		}
+		if i+1 < len(groups) {
+			if multiplier, ok := amountMultipliers[strings.ToLower(groups[i+1])]; ok {
+				amount *= multiplier
+			}
+		}
+		return amount, true
+	}
+
+	return 0, false
+}
+
+// shouts reports whether most letters of a long enough text are capitals
+func shouts(text string) bool {
+	var letters, capitals int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(r) {
+			capitals++
+		}
+	}
+
+	return letters >= capsMinLetters && float64(capitals)/float64(letters) > capsMaxShare
+}
+
+// truncate shortens the text reported to moderators
+func truncate(text string) string {
+	runes := []rune(text)
+	if len(runes) <= maxExcerpt {
+		return text
+	}
+	return string(runes[:maxExcerpt]) + "…"
+}
@@ -0,0 +1,145 @@
+package screening
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/chazool/serendib_asia_service/pkg/config/screeningconfig"
+)
+
+func TestNewScreenerRejectsInvalidPattern(t *testing.T) {
+	_, err := NewScreener(screeningconfig.Config{Patterns: map[string]string{"broken": `(unclosed`}})
+	if err == nil {
+		t.Fatal("NewScreener() error = nil, want an error for an invalid pattern")
+	}
+}
+
+func TestScreen(t *testing.T) {
+	screener, err := NewScreener(screeningconfig.Config{
+		BannedWords:    []string{"guaranteed returns"},
+		WordWeight:     40,
+		Patterns:       map[string]string{"crypto": `(?i)\bbitcoin\b`},
+		PatternWeight:  40,
+		PriceTolerance: 0.25,
+	})
+	if err != nil {
+		t.Fatalf("NewScreener() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		listing   Listing
+		wantRules []string
+		wantScore int
+	}{
+		{
+			name:    "clean listing",
+			listing: Listing{Title: "Two bedroom apartment in Colombo", Description: "Close to the station, Rs. 24 million", Price: 25000000},
+		},
+		{
+			name:      "phone number",
+			listing:   Listing{Title: "Land in Kandy", Description: "Call 077 123 4567 for details"},
+			wantRules: []string{RulePhone},
+			wantScore: 50,
+		},
+		{
+			name:      "rule scored once however often it matches",
+			listing:   Listing{Title: "Call 0771234567", Description: "Or 0112345678 after six"},
+			wantRules: []string{RulePhone},
+			wantScore: 50,
+		},
+		{
+			name:      "spelled out email",
+			listing:   Listing{Title: "Annex for rent", Description: "Mail owner at example dot com"},
+			wantRules: []string{RuleEmail},
+			wantScore: 50,
+		},
+		{
+			name:      "messaging app",
+			listing:   Listing{Title: "Annex for rent", Description: "Contact on WhatsApp only"},
+			wantRules: []string{RuleMessagingApp},
+			wantScore: 20,
+		},
+		{
+			name:      "shouting title",
+			listing:   Listing{Title: "LUXURY VILLA WITH POOL FOR SALE NOW", Description: "Quiet area"},
+			wantRules: []string{RuleExcessiveCaps},
+			wantScore: 20,
+		},
+		{
+			name:      "price far from the listing price",
+			listing:   Listing{Title: "House in Galle", Description: "Asking Rs. 18 million", Price: 25000000},
+			wantRules: []string{RulePriceMismatch},
+			wantScore: 30,
+		},
+		{
+			name:      "banned word and configured pattern",
+			listing:   Listing{Title: "Guaranteed returns on this villa", Description: "Pay in Bitcoin"},
+			wantRules: []string{RuleBannedWord, "pattern:crypto"},
+			wantScore: 80,
+		},
+		{
+			name:      "score capped",
+			listing:   Listing{Title: "Villa", Description: "Call 0771234567, mail owner@example.com"},
+			wantRules: []string{RulePhone, RuleEmail, RuleURL},
+			wantScore: MaxScore,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := screener.Screen(tt.listing)
+
+			var rules []string
+			for _, match := range result.Matches {
+				if !slices.Contains(rules, match.Rule) {
+					rules = append(rules, match.Rule)
+				}
+			}
+			if !slices.Equal(rules, tt.wantRules) {
+				t.Errorf("Screen() rules = %v, want %v", rules, tt.wantRules)
+			}
+			if result.Score != tt.wantScore {
+				t.Errorf("Screen() score = %d, want %d", result.Score, tt.wantScore)
+			}
+		})
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{text: "Rs. 25,000,000", want: 25000000},
+		{text: "LKR 2.5 million", want: 2500000},
+		{text: "USD 1.5mn", want: 1500000},
+		{text: "Rs 75k", want: 75000},
+		{text: "$ 1,200", want: 1200},
+		{text: "45 lakhs", want: 4500000},
+		{text: "1 lakh", want: 100000},
+		{text: "250,000/=", want: 250000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			groups := amountPattern.FindStringSubmatch(tt.text)
+			if groups == nil {
+				t.Fatalf("amountPattern does not match %q", tt.text)
+			}
+			got, ok := parseAmount(groups)
+			if !ok || got != tt.want {
+				t.Errorf("parseAmount(%q) = %v, %v, want %v, true", tt.text, got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmountWithoutAmount(t *testing.T) {
+	if _, ok := parseAmount([]string{"Rs.", "", ""}); ok {
+		t.Error("parseAmount() ok = true for a match without an amount")
+	}
+	if _, ok := parseAmount([]string{"1.2.3", "1.2.3", ""}); ok {
+		t.Error("parseAmount() ok = true for an amount that is not a number")
+	}
+}
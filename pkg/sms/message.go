@@ -0,0 +1,15 @@
+package sms
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhoneVerificationMessage builds the SMS carrying a phone verification code
+func PhoneVerificationMessage(to, code string, ttl time.Duration) Message {
+	return Message{
+		To: to,
+		Body: fmt.Sprintf("%s is your Serendib Asia verification code. It expires in %d minutes. "+
+			"Do not share it with anyone.", code, int(ttl.Minutes())),
+	}
+}
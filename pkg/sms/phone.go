@@ -0,0 +1,58 @@
+package sms
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalidPhoneNumber is returned for numbers that cannot be normalised to E.164
+var ErrInvalidPhoneNumber = errors.New("invalid phone number")
+
+const (
+	internationalPrefix = "+"
+	// internationalDialPrefix is the 00 dialling prefix some users type instead of +
+	internationalDialPrefix = "00"
+	// trunkPrefix starts numbers written in national format, e.g. 077 123 4567
+	trunkPrefix = "0"
+)
+
+var (
+	e164Digits = regexp.MustCompile(`^[1-9][0-9]{7,14}$`)
+	// phoneSeparators are the formatting characters allowed between digits
+	phoneSeparators = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	// nationalNumberLengths holds the subscriber number length of the countries we
+	// validate strictly, keyed by country code
+	nationalNumberLengths = map[string]int{
+		"94": 9, // Sri Lanka
+	}
+)
+
+// NormalizePhoneNumber converts a phone number to E.164 format (+94771234567).
+// Numbers in national format, with a leading 0 or without any prefix, are
+// assumed to belong to defaultCountryCode.
+func NormalizePhoneNumber(number, defaultCountryCode string) (string, error) {
+	digits := phoneSeparators.Replace(strings.TrimSpace(number))
+
+	switch {
+	case strings.HasPrefix(digits, internationalPrefix):
+		digits = strings.TrimPrefix(digits, internationalPrefix)
+	case strings.HasPrefix(digits, internationalDialPrefix):
+		digits = strings.TrimPrefix(digits, internationalDialPrefix)
+	case strings.HasPrefix(digits, trunkPrefix):
+		digits = defaultCountryCode + strings.TrimPrefix(digits, trunkPrefix)
+	case len(digits) == nationalNumberLengths[defaultCountryCode]:
+		digits = defaultCountryCode + digits
+	}
+
+	if !e164Digits.MatchString(digits) {
+		return "", ErrInvalidPhoneNumber
+	}
+	for countryCode, length := range nationalNumberLengths {
+		if strings.HasPrefix(digits, countryCode) && len(digits) != len(countryCode)+length {
+			return "", ErrInvalidPhoneNumber
+		}
+	}
+
+	return internationalPrefix + digits, nil
+}
@@ -0,0 +1,51 @@
+package sms
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		name               string
+		number             string
+		defaultCountryCode string
+		want               string
+		wantErr            bool
+	}{
+		{name: "e164", number: "+94771234567", defaultCountryCode: "94", want: "+94771234567"},
+		{name: "international with spaces", number: " +94 77 123 4567 ", defaultCountryCode: "94", want: "+94771234567"},
+		{name: "00 dialling prefix", number: "0094771234567", defaultCountryCode: "94", want: "+94771234567"},
+		{name: "national with trunk prefix", number: "077-123-4567", defaultCountryCode: "94", want: "+94771234567"},
+		{name: "national with brackets and dots", number: "(077) 123.4567", defaultCountryCode: "94", want: "+94771234567"},
+		{name: "subscriber number only", number: "771234567", defaultCountryCode: "94", want: "+94771234567"},
+		{name: "other country", number: "+44 20 7946 0958", defaultCountryCode: "94", want: "+442079460958"},
+		{name: "national of other default country", number: "020 7946 0958", defaultCountryCode: "44", want: "+442079460958"},
+		{name: "empty", number: "", defaultCountryCode: "94", wantErr: true},
+		{name: "letters", number: "call me", defaultCountryCode: "94", wantErr: true},
+		{name: "too short", number: "12345", defaultCountryCode: "94", wantErr: true},
+		{name: "too long", number: "+1234567890123456", defaultCountryCode: "94", wantErr: true},
+		{name: "country code starting with zero", number: "+0771234567", defaultCountryCode: "94", wantErr: true},
+		{name: "sri lankan number one digit short", number: "+9477123456", defaultCountryCode: "94", wantErr: true},
+		{name: "sri lankan number one digit long", number: "+947712345678", defaultCountryCode: "94", wantErr: true},
+		{name: "national number one digit short", number: "077 123 456", defaultCountryCode: "94", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePhoneNumber(tt.number, tt.defaultCountryCode)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPhoneNumber) {
+					t.Errorf("NormalizePhoneNumber(%q) error = %v, want %v", tt.number, err, ErrInvalidPhoneNumber)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizePhoneNumber(%q) error = %v", tt.number, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePhoneNumber(%q) = %q, want %q", tt.number, got, tt.want)
+			}
+		})
+	}
+}
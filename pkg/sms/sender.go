@@ -0,0 +1,35 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/chazool/serendib_asia_service/pkg/config"
+	"github.com/chazool/serendib_asia_service/pkg/config/smsconfig"
+)
+
+// ErrUnknownSMSDriver is returned when SMS_DRIVER names no known driver
+var ErrUnknownSMSDriver = errors.New("unknown sms driver")
+
+// Message is a text message to an E.164 phone number
+type Message struct {
+	To   string
+	Body string
+}
+
+// Sender delivers SMS messages
+type Sender interface {
+	Send(message Message) error
+}
+
+// NewSender creates the sender selected by the SMS_DRIVER config
+func NewSender() (Sender, error) {
+	smsConfig := config.GetConfig().SMSConfig
+
+	switch smsConfig.Driver {
+	case smsconfig.DriverLog:
+		return NewLogSender(smsConfig.SenderID), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSMSDriver, smsConfig.Driver)
+	}
+}
@@ -54,6 +54,104 @@ const (
 	Code    = "code"
 )
 
+// Password constants
+const (
+	MinPasswordLength = 8
+)
+
+// Pagination constants
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 50
+)
+
+// Property sort fields
+const (
+	PropertySortPrice    = "price"
+	PropertySortDate     = "date"
+	PropertySortSize     = "size"
+	PropertySortDistance = "distance"
+)
+
+// Property pricing types
+const (
+	PricingTypeSell = "sell"
+	PricingTypeRent = "rent"
+	PricingTypeStay = "stay"
+)
+
+// Property listing statuses, only published listings are shown publicly
+const (
+	PropertyStatusDraft         = "draft"
+	PropertyStatusPendingReview = "pending_review"
+	PropertyStatusRejected      = "rejected"
+	PropertyStatusPublished     = "published"
+	PropertyStatusReserved      = "reserved"
+	PropertyStatusSold          = "sold"
+	PropertyStatusRented        = "rented"
+	PropertyStatusExpired       = "expired"
+	PropertyStatusArchived      = "archived"
+)
+
+// Notes recorded in the status history of a listing by the service itself
+const (
+	ListingExpiredNote = "Listing period ended"
+	ListingRenewedNote = "Listing renewed"
+	// ListingEditReviewNote is recorded when an edit sends a published listing back for review
+	ListingEditReviewNote = "Edited listing sent back for review"
+	// ListingScreeningReviewNote is recorded when the content screening sends a listing to review
+	ListingScreeningReviewNote = "Sent to review by content screening"
+	// ListingMergedNote is recorded when a duplicate listing is archived in favour of the listing kept
+	ListingMergedNote = "Merged into listing %d as a duplicate"
+	// ListingBaselineRevisionNote is recorded on the revision kept of a listing before its first update
+	ListingBaselineRevisionNote = "Listing before its first recorded update"
+	// ListingRestoredNote is recorded on the revision an earlier revision is restored as
+	ListingRestoredNote = "Restored from revision %d"
+	// Notes recorded on the revisions the image endpoints store
+	ListingImageAddedNote   = "Image added"
+	ListingImageDeletedNote = "Image deleted"
+	ListingImagePrimaryNote = "Primary image changed"
+	// ListingExpiryJobDisabled is logged when no expiry interval is configured
+	ListingExpiryJobDisabled = "Listing expiry job is disabled"
+	// ScreeningInitFailError is logged when the content screening rules do not compile
+	ScreeningInitFailError = "Failed to compile the content screening rules"
+)
+
+// Moderation decisions on listings under review
+const (
+	ModerationDecisionApproved = "approved"
+	ModerationDecisionRejected = "rejected"
+)
+
+// Moderation reason codes a listing is rejected with
+const (
+	ModerationReasonScam          = "scam"
+	ModerationReasonSpam          = "spam"
+	ModerationReasonDuplicate     = "duplicate"
+	ModerationReasonProhibited    = "prohibited_content"
+	ModerationReasonWrongCategory = "wrong_category"
+	ModerationReasonInaccurate    = "inaccurate_details"
+	ModerationReasonPoorImages    = "poor_images"
+	ModerationReasonOther         = "other"
+)
+
+// Statuses of a pair of listings flagged as duplicates
+const (
+	PropertyDuplicateStatusOpen      = "open"
+	PropertyDuplicateStatusMerged    = "merged"
+	PropertyDuplicateStatusDismissed = "dismissed"
+)
+
+// Property map constants
+const (
+	// MapListingsMinZoom is the zoom level from which the map returns listings instead of clusters
+	MapListingsMinZoom = 15
+	// MaxMapListings caps the listings returned for one viewport
+	MaxMapListings = 500
+	// MapCellsPerTile is how many cluster cells span one 256px map tile
+	MapCellsPerTile = 4
+)
+
 // Incident Type constants
 const (
 	IncidentTypeLikelyToEscalate    = "escalation_likely"
@@ -144,12 +242,14 @@ const (
 	PlatformHeader         = "Sec-Ch-Ua-Platform"
 	BrowserHeader          = "Sec-Ch-Ua"
 	Authorization          = "Authorization"
+	APIKeyHeader           = "X-API-Key"
 	UserID                 = "UserID"
 	Domain                 = "Domain"
 	InstanceID             = "InstanceId"
 	TenantID               = "TenantID"
 	XAccessTokenFromHeader = "X-Access-Token"
 	UserKey                = "User"
+	Permission             = "Permission"
 )
 
 // Method names
@@ -20,6 +20,7 @@ const (
 	ErrorOccurredWhenHashing            = "error occurred when hashing"
 	ErrorOccurredWhenHashCompare        = "error occurred when hash compare"
 	ErrOccurredWhenSendingEmail         = "error occurred when sending email"
+	ErrOccurredWhenSendingSMS           = "error occurred when sending sms"
 	ErrOccouredWhenParseDate            = "error occurred when parse %s"
 	ErrorOccurredWhenCompareGreaterThan = "%s should be greater than %s"
 	ErrOccurredWhenCompGreaterThanOrEql = "%s should be greater than or equal %s"
@@ -223,11 +224,92 @@ const (
 	DuplicateEmailErrorMessage = "Email already exists"
 	InvalidCredentialsMessage  = "Invalid email or password"
 	UserNotFoundMessage        = "User not found"
+	InvalidPhoneNumberMessage  = "Phone number is not valid"
+	// Property error messages
+	PropertyNotFoundMessage = "Property not found"
+	NotPropertyOwnerMessage = "Only the owner of the property or a manager of its organization can modify it"
+	ImageNotFoundMessage    = "Image not found"
+	ListingLimitReachedMsg  = "Listing limit of %d properties reached"
+	InvalidRangeMessage     = "min_%s cannot be greater than max_%s"
+	IncompletePointMessage  = "lat and lng must be given together"
+	LocationRequiredMessage = "lat and lng are required for %s"
+	IncompleteBoundsMessage = "min_lat, max_lat, min_lng and max_lng must be given together"
+	ViewportRequiredMessage = "min_lat, max_lat, min_lng and max_lng are required"
+	InvalidCursorMessage    = "The cursor is not valid for this list; start again without one"
+	InvalidPolygonMessage   = "polygon must be %d to %d lat,lng points separated by ;"
+	InvalidTransitionMsg    = "A %s listing cannot be moved to %s"
+	InvalidClosingStatusMsg = "A listing for %s cannot be marked %s"
+	StatusChangedMessage    = "The listing status was changed meanwhile, reload it and try again"
+	ListingPeriodEndedMsg   = "The listing period has ended, renew the listing to publish it again"
+	InvalidRenewalMsg       = "A %s listing cannot be renewed"
+	RenewalLimitReachedMsg  = "Renewal limit of %d renewals in %d days reached"
+	DuplicateNotFoundMsg    = "Duplicate flag not found"
+	DuplicateResolvedMsg    = "The duplicate flag was already %s"
+	InvalidMergeMsg         = "The listing to keep must be %d or %d"
+	RevisionNotFoundMsg     = "Revision %d of the property not found"
+	// Role error messages
+	RoleNotFoundMessage   = "Role not found"
+	LookupNotFoundMessage = "Lookup not found"
+	// Session error messages
+	SessionNotFoundMessage = "Session not found"
+	// API key error messages
+	APIKeyNotFoundMessage = "API key not found"
+	InvalidScopeMessage   = "Unknown api key scope %q"
+	InvalidExpiryMessage  = "Expiry must be in the future"
+	// Organization error messages
+	OrganizationNotFoundMessage  = "Organization not found"
+	NotOrganizationMemberMessage = "Only members of the organization can do this"
+	OrganizationRoleMessage      = "Your role in the organization does not allow this"
+	MemberNotFoundMessage        = "Member not found"
+	LastOwnerMessage             = "An organization must keep at least one owner"
+	AlreadyMemberMessage         = "User is already a member of the organization"
+	InvitationNotFoundMessage    = "Invitation not found"
+	InvalidInvitationMessage     = "Invalid or expired invitation"
+	InvitationEmailMessage       = "The invitation was sent to a different email address"
 
 	// User error codes
 	DuplicateEmailErrorCode = "EMAIL_EXISTS"
 	InvalidCredentialsCode  = "INVALID_CREDENTIALS"
 	UserNotFoundCode        = "USER_NOT_FOUND"
+	InvalidPhoneNumberCode  = "INVALID_PHONE_NUMBER"
+	// Property error codes
+	PropertyNotFoundCode  = "PROPERTY_NOT_FOUND"
+	NotPropertyOwnerCode  = "NOT_PROPERTY_OWNER"
+	ImageNotFoundCode     = "IMAGE_NOT_FOUND"
+	ListingLimitCode      = "LISTING_LIMIT_REACHED"
+	InvalidRangeCode      = "INVALID_RANGE"
+	InvalidLocationCode   = "INVALID_LOCATION"
+	InvalidBoundsCode     = "INVALID_BOUNDS"
+	InvalidPolygonCode    = "INVALID_POLYGON"
+	InvalidCursorCode     = "INVALID_CURSOR"
+	InvalidTransitionCode = "INVALID_STATUS_TRANSITION"
+	StatusChangedCode     = "STATUS_CHANGED"
+	ListingExpiredCode    = "LISTING_EXPIRED"
+	InvalidRenewalCode    = "INVALID_RENEWAL"
+	RenewalLimitCode      = "RENEWAL_LIMIT_REACHED"
+	DuplicateNotFoundCode = "DUPLICATE_NOT_FOUND"
+	DuplicateResolvedCode = "DUPLICATE_RESOLVED"
+	InvalidMergeCode      = "INVALID_MERGE"
+	RevisionNotFoundCode  = "REVISION_NOT_FOUND"
+	// Role error codes
+	RoleNotFoundCode   = "ROLE_NOT_FOUND"
+	LookupNotFoundCode = "LOOKUP_NOT_FOUND"
+	// Session error codes
+	SessionNotFoundCode = "SESSION_NOT_FOUND"
+	// API key error codes
+	APIKeyNotFoundCode = "API_KEY_NOT_FOUND"
+	InvalidScopeCode   = "INVALID_SCOPE"
+	InvalidExpiryCode  = "INVALID_EXPIRY"
+	// Organization error codes
+	OrganizationNotFoundCode  = "ORGANIZATION_NOT_FOUND"
+	NotOrganizationMemberCode = "NOT_ORGANIZATION_MEMBER"
+	OrganizationRoleCode      = "ORGANIZATION_ROLE_REQUIRED"
+	MemberNotFoundCode        = "MEMBER_NOT_FOUND"
+	LastOwnerCode             = "LAST_OWNER"
+	AlreadyMemberCode         = "ALREADY_MEMBER"
+	InvitationNotFoundCode    = "INVITATION_NOT_FOUND"
+	InvalidInvitationCode     = "INVALID_INVITATION"
+	InvitationEmailCode       = "INVITATION_EMAIL_MISMATCH"
 )
 
 // "Client validation failed"
@@ -269,10 +351,44 @@ const (
 	ErrOccurredWhenSigningJWTToken         = "error occurred when signing jwt token"
 )
 
-// Firebase auth error codes
+// Auth error codes
 const (
-	ErrEmptyAuthHeaderCode   = "AUTH_001"
-	ErrInvalidAuthHeaderCode = "AUTH_002"
-	ErrInvalidTokenCode      = "AUTH_003"
-	ErrInvalidTokenMsg       = "invalid token"
+	ErrEmptyAuthHeaderCode     = "AUTH_001"
+	ErrInvalidAuthHeaderCode   = "AUTH_002"
+	ErrInvalidTokenCode        = "AUTH_003"
+	ErrInvalidTokenMsg         = "invalid token"
+	ErrInvalidRefreshTokenCode = "AUTH_004"
+	ErrAccountSuspendedCode    = "AUTH_005"
+	ErrAccountSuspendedMsg     = "account is suspended"
+	ErrMissingPermissionMsg    = "permission required for this operation"
+	ErrInvalidResetTokenCode   = "AUTH_006"
+	ErrInvalidResetTokenMsg    = "invalid or expired password reset token"
+	ErrTokenBeforeResetMsg     = "token was issued before the last password reset"
+	ErrEmailNotVerifiedCode    = "AUTH_007"
+	ErrEmailNotVerifiedMsg     = "email address is not verified"
+	ErrInvalidVerifyTokenCode  = "AUTH_008"
+	ErrInvalidVerifyTokenMsg   = "invalid or expired email verification token"
+	ErrEmailVerifiedCode       = "AUTH_009"
+	ErrEmailVerifiedMsg        = "email address is already verified"
+	ErrLoginThrottledCode      = "AUTH_010"
+	ErrLoginThrottledMsg       = "too many failed login attempts, try again later"
+	ErrInvalidUnlockTokenCode  = "AUTH_011"
+	ErrInvalidUnlockTokenMsg   = "invalid or expired account unlock token"
+	ErrSessionRevokedCode      = "AUTH_012"
+	ErrSessionRevokedMsg       = "session has been revoked or has expired"
+	ErrInvalidExportFormatMsg  = "export format must be json or zip"
+	ErrInvalidPhoneCodeCode    = "AUTH_013"
+	ErrInvalidPhoneCodeMsg     = "invalid or expired phone verification code"
+	ErrPhoneCodeAttemptsCode   = "AUTH_014"
+	ErrPhoneCodeAttemptsMsg    = "too many wrong codes, request a new verification code"
+	ErrPhoneVerifiedCode       = "AUTH_015"
+	ErrPhoneVerifiedMsg        = "phone number is already verified"
+	ErrNoPhoneNumberCode       = "AUTH_016"
+	ErrNoPhoneNumberMsg        = "add a phone number to the profile first"
+	ErrPhoneCodeThrottledCode  = "AUTH_017"
+	ErrPhoneCodeThrottledMsg   = "a verification code was sent recently, try again later"
+	ErrInvalidAPIKeyCode       = "AUTH_018"
+	ErrInvalidAPIKeyMsg        = "invalid, expired or revoked api key"
+	ErrMissingScopeCode        = "AUTH_019"
+	ErrMissingScopeMsg         = "api key scope required for this operation"
 )
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// APIKeyMiddleware creates a middleware that authenticates requests carrying an
+// X-API-Key header, as long as the key grants the given scope. Requests without the
+// header are handed to next, normally the bearer token AuthMiddleware, so a route
+// only accepts API keys where this middleware is mounted.
+func APIKeyMiddleware(resolve auth.APIKeyResolver, scope string, next fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(constant.APIKeyHeader)
+		if key == constant.Empty {
+			return next(c)
+		}
+
+		requestID := web.GetRequestID(c)
+		commonLogFields := log.CommonLogField(requestID)
+
+		principal, errResult := resolve(requestID, key)
+		if errResult != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhen("resolving api key"), log.TraceCustomError(commonLogFields, *errResult)...)
+			return c.Status(errResult.StatusCode).JSON(errResult)
+		}
+
+		if !principal.HasScope(scope) {
+			log.Logger.Error(constant.ErrMissingScopeMsg, append(commonLogFields, zap.String(constant.Permission, scope))...)
+			errRes := custom.BuildForbiddenErrResult(constant.ErrMissingScopeCode, constant.ErrMissingScopeMsg, scope)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		c.Locals(auth.PrincipalContextKey, principal)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuthMiddleware creates a middleware that verifies the bearer token of the request,
+// resolves it to a user and stores the typed principal in the fiber context
+func AuthMiddleware(verifier auth.TokenVerifier, resolve auth.PrincipalResolver) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := web.GetRequestID(c)
+		commonLogFields := log.CommonLogField(requestID)
+
+		// Get Authorization header
+		authHeader := c.Get(constant.Authorization)
+		if authHeader == constant.Empty {
+			log.Logger.Error(constant.ErrEmptyAuthHeaderMsg, commonLogFields...)
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrEmptyAuthHeaderCode, constant.ErrEmptyAuthHeaderMsg, constant.Empty)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		// Extract token from Bearer header
+		token, found := strings.CutPrefix(authHeader, constant.BearerSpace)
+		if !found || token == constant.Empty {
+			log.Logger.Error(constant.ErrInvalidAuthHeaderMsg, commonLogFields...)
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrInvalidAuthHeaderCode, constant.ErrInvalidAuthHeaderMsg, constant.Empty)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		// Verify the token
+		identity, err := verifier.Verify(c.UserContext(), token)
+		if err != nil {
+			log.Logger.Error(constant.ErrInParsingTokenMsg, log.TraceError(commonLogFields, err)...)
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrInvalidTokenCode, constant.ErrInvalidTokenMsg, constant.Empty)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		// Map the identity to a user of this service
+		principal, errResult := resolve(requestID, identity)
+		if errResult != nil {
+			log.Logger.Error(log.TraceMsgErrorOccurredWhen("resolving principal"), log.TraceCustomError(commonLogFields, *errResult)...)
+			return c.Status(errResult.StatusCode).JSON(errResult)
+		}
+
+		c.Locals(auth.PrincipalContextKey, principal)
+
+		return c.Next()
+	}
+}
+
+// OptionalAuthMiddleware creates a middleware for routes that anyone may call but that answer
+// differently for signed in users. Requests with an Authorization header are handed to next,
+// normally AuthMiddleware, so an invalid token is still rejected; others continue anonymously.
+func OptionalAuthMiddleware(next fiber.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Get(constant.Authorization) == constant.Empty {
+			return c.Next()
+		}
+		return next(c)
+	}
+}
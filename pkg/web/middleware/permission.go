@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// RequirePermission creates a middleware that only lets principals holding the given
+// permission through. It must be mounted after AuthMiddleware.
+func RequirePermission(permission string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		commonLogFields := log.CommonLogField(web.GetRequestID(c))
+
+		principal, _ := c.Locals(auth.PrincipalContextKey).(*auth.Principal)
+		if principal == nil {
+			log.Logger.Error(constant.ErrEmptyAuthHeaderMsg, commonLogFields...)
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrEmptyAuthHeaderCode, constant.ErrEmptyAuthHeaderMsg, constant.Empty)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		if !principal.HasPermission(permission) {
+			log.Logger.Error(constant.ErrMissingPermissionMsg, append(commonLogFields, zap.String(constant.Permission, permission))...)
+			errRes := custom.BuildForbiddenErrResult(constant.ErrCodeForbidden, constant.ErrMissingPermissionMsg, permission)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		return c.Next()
+	}
+}
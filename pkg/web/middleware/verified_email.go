@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/chazool/serendib_asia_service/pkg/auth"
+	"github.com/chazool/serendib_asia_service/pkg/custom"
+	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
+	"github.com/chazool/serendib_asia_service/pkg/web"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireVerifiedEmail creates a middleware that only lets principals with a verified
+// email address through. When required is false every principal is let through.
+// It must be mounted after AuthMiddleware.
+func RequireVerifiedEmail(required bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !required {
+			return c.Next()
+		}
+
+		commonLogFields := log.CommonLogField(web.GetRequestID(c))
+
+		principal, _ := c.Locals(auth.PrincipalContextKey).(*auth.Principal)
+		if principal == nil {
+			log.Logger.Error(constant.ErrEmptyAuthHeaderMsg, commonLogFields...)
+			errRes := custom.BuildUnauthorizedErrResult(constant.ErrEmptyAuthHeaderCode, constant.ErrEmptyAuthHeaderMsg, constant.Empty)
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		if !principal.EmailVerified {
+			log.Logger.Error(constant.ErrEmailNotVerifiedMsg, commonLogFields...)
+			errRes := custom.BuildForbiddenErrResult(constant.ErrEmailNotVerifiedCode, constant.ErrEmailNotVerifiedMsg, "Email")
+			return c.Status(errRes.StatusCode).JSON(errRes)
+		}
+
+		return c.Next()
+	}
+}
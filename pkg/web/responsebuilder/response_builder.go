@@ -2,9 +2,11 @@ package responsebuilder
 
 import (
 	"net/http"
+	"net/url"
 
 	"github.com/chazool/serendib_asia_service/pkg/custom"
 	"github.com/chazool/serendib_asia_service/pkg/log"
+	"github.com/chazool/serendib_asia_service/pkg/pagination"
 	"github.com/chazool/serendib_asia_service/pkg/utils/constant"
 
 	"github.com/gofiber/fiber/v2"
@@ -19,11 +21,38 @@ type APIResponse struct {
 	RequestID     string
 	HTTPStatus    int
 	ErrorResponse custom.ErrorResult
+	// Page is set for paged lists and adds the pagination details and links to the envelope
+	Page *pagination.Page
 }
 
 // CommonSuccessResponse used to return common success response
 type CommonSuccessResponse struct {
-	Data any `json:"data"`
+	Data       any               `json:"data"`
+	Pagination *pagination.Page  `json:"pagination,omitempty"`
+	Links      *pagination.Links `json:"links,omitempty"`
+}
+
+// PageLinks builds the URLs of the pages around the current one from the request URL
+func PageLinks(ctx *fiber.Ctx, page *pagination.Page) *pagination.Links {
+	if page == nil || (page.NextCursor == "" && page.PrevCursor == "") {
+		return nil
+	}
+
+	link := func(cursor string) string {
+		if cursor == "" {
+			return ""
+		}
+		current, err := url.Parse(ctx.OriginalURL())
+		if err != nil {
+			return ""
+		}
+		query := current.Query()
+		query.Set(pagination.CursorParam, cursor)
+		current.RawQuery = query.Encode()
+		return ctx.BaseURL() + current.String()
+	}
+
+	return &pagination.Links{Next: link(page.NextCursor), Prev: link(page.PrevCursor)}
 }
 
 // BuildAPIResponse is used to build the API response
@@ -45,7 +74,9 @@ func (response *APIResponse) BuildAPIResponse() {
 	} else {
 		log.Logger.Debug(constant.TraceMsgAPISuccess, commonLogFields...)
 		successResponse := CommonSuccessResponse{
-			Data: response.Response,
+			Data:       response.Response,
+			Pagination: response.Page,
+			Links:      PageLinks(response.Ctx, response.Page),
 		}
 
 		err := response.Ctx.Status(response.HTTPStatus).JSON(successResponse)